@@ -0,0 +1,192 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/auth/v1/auth.proto
+
+package auth
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AuthRule declares who is allowed to call an RPC.
+// Read at startup from the registered descriptors by the authz middleware,
+// keyed by full operation ("/api.example.v1.Examplev1Service/Mock"). The
+// served /docs/openapi.yaml shows it on each operation as "x-auth-rule".
+//
+// Usage:
+//
+//	rpc Mock(MockRequest) returns (MockResponse) {
+//	  option (auth.v1.rule) = { roles: ["ADMIN"] };
+//	}
+//
+// With auth.authz.default_deny, methods without a rule are rejected; public
+// methods must say so: option (auth.v1.rule) = { public: true };
+//
+// Role lists are combined with AND: any of "roles", all of "all_of", none of
+// "none_of". Entries may end with "*" (e.g. "EXAMPLE_*"); caller roles are
+// expanded with auth.authz.role_hierarchy (ADMIN -> EDITOR -> VIEWER).
+//
+//	option (auth.v1.rule) = { all_of: ["EDITOR", "BILLING"], none_of: ["SUSPENDED"] };
+type AuthRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []string               `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`                 // caller needs at least one of these roles (empty = any valid token)
+	Public        bool                   `protobuf:"varint,2,opt,name=public,proto3" json:"public,omitempty"`              // no authentication at all (roles are ignored)
+	AllOf         []string               `protobuf:"bytes,3,rep,name=all_of,json=allOf,proto3" json:"all_of,omitempty"`    // caller needs every one of these roles
+	NoneOf        []string               `protobuf:"bytes,4,rep,name=none_of,json=noneOf,proto3" json:"none_of,omitempty"` // caller must have none of these roles
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthRule) Reset() {
+	*x = AuthRule{}
+	mi := &file_api_auth_v1_auth_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthRule) ProtoMessage() {}
+
+func (x *AuthRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_auth_v1_auth_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthRule.ProtoReflect.Descriptor instead.
+func (*AuthRule) Descriptor() ([]byte, []int) {
+	return file_api_auth_v1_auth_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthRule) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *AuthRule) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *AuthRule) GetAllOf() []string {
+	if x != nil {
+		return x.AllOf
+	}
+	return nil
+}
+
+func (x *AuthRule) GetNoneOf() []string {
+	if x != nil {
+		return x.NoneOf
+	}
+	return nil
+}
+
+var file_api_auth_v1_auth_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*AuthRule)(nil),
+		Field:         50501,
+		Name:          "auth.v1.rule",
+		Tag:           "bytes,50501,opt,name=rule",
+		Filename:      "api/auth/v1/auth.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional auth.v1.AuthRule rule = 50501;
+	E_Rule = &file_api_auth_v1_auth_proto_extTypes[0]
+)
+
+var File_api_auth_v1_auth_proto protoreflect.FileDescriptor
+
+const file_api_auth_v1_auth_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/auth/v1/auth.proto\x12\aauth.v1\x1a google/protobuf/descriptor.proto\"h\n" +
+	"\bAuthRule\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\x12\x16\n" +
+	"\x06public\x18\x02 \x01(\bR\x06public\x12\x15\n" +
+	"\x06all_of\x18\x03 \x03(\tR\x05allOf\x12\x17\n" +
+	"\anone_of\x18\x04 \x03(\tR\x06noneOf:G\n" +
+	"\x04rule\x12\x1e.google.protobuf.MethodOptions\x18Ŋ\x03 \x01(\v2\x11.auth.v1.AuthRuleR\x04ruleBC\n" +
+	"\x18dev.kratos.api.auth.authB\vAuthProtoV1P\x01Z\x18service/api/auth/v1;authb\x06proto3"
+
+var (
+	file_api_auth_v1_auth_proto_rawDescOnce sync.Once
+	file_api_auth_v1_auth_proto_rawDescData []byte
+)
+
+func file_api_auth_v1_auth_proto_rawDescGZIP() []byte {
+	file_api_auth_v1_auth_proto_rawDescOnce.Do(func() {
+		file_api_auth_v1_auth_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_auth_v1_auth_proto_rawDesc), len(file_api_auth_v1_auth_proto_rawDesc)))
+	})
+	return file_api_auth_v1_auth_proto_rawDescData
+}
+
+var file_api_auth_v1_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_auth_v1_auth_proto_goTypes = []any{
+	(*AuthRule)(nil),                   // 0: auth.v1.AuthRule
+	(*descriptorpb.MethodOptions)(nil), // 1: google.protobuf.MethodOptions
+}
+var file_api_auth_v1_auth_proto_depIdxs = []int32{
+	1, // 0: auth.v1.rule:extendee -> google.protobuf.MethodOptions
+	0, // 1: auth.v1.rule:type_name -> auth.v1.AuthRule
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	1, // [1:2] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_api_auth_v1_auth_proto_init() }
+func file_api_auth_v1_auth_proto_init() {
+	if File_api_auth_v1_auth_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_auth_v1_auth_proto_rawDesc), len(file_api_auth_v1_auth_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_auth_v1_auth_proto_goTypes,
+		DependencyIndexes: file_api_auth_v1_auth_proto_depIdxs,
+		MessageInfos:      file_api_auth_v1_auth_proto_msgTypes,
+		ExtensionInfos:    file_api_auth_v1_auth_proto_extTypes,
+	}.Build()
+	File_api_auth_v1_auth_proto = out.File
+	file_api_auth_v1_auth_proto_goTypes = nil
+	file_api_auth_v1_auth_proto_depIdxs = nil
+}
@@ -12,6 +12,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
+	_ "service/api/auth/v1"
 	sync "sync"
 	unsafe "unsafe"
 )
@@ -175,7 +176,7 @@ var File_api_example_v1_example_proto protoreflect.FileDescriptor
 
 const file_api_example_v1_example_proto_rawDesc = "" +
 	"\n" +
-	"\x1capi/example/v1/example.proto\x12\x0eapi.example.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\"\r\n" +
+	"\x1capi/example/v1/example.proto\x12\x0eapi.example.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\x1a\x16api/auth/v1/auth.proto\"\r\n" +
 	"\vMockRequest\"(\n" +
 	"\fMockResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"\xa3\x01\n" +
@@ -185,9 +186,9 @@ const file_api_example_v1_example_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2o\n" +
-	"\x10Examplev1Service\x12[\n" +
-	"\x04Mock\x12\x1b.api.example.v1.MockRequest\x1a\x1c.api.example.v1.MockResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/example/mockBO\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2u\n" +
+	"\x10Examplev1Service\x12a\n" +
+	"\x04Mock\x12\x1b.api.example.v1.MockRequest\x1a\x1c.api.example.v1.MockResponse\"\x1e\xaa\xd4\x18\x02\x10\x01\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/example/mockBO\n" +
 	"\x1edev.kratos.api.example.exampleB\x0eExampleProtoV1P\x01Z\x1bservice/api/example;exampleb\x06proto3"
 
 var (
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"service/internal/server/middleware/auth/auth/apikey"
+	"service/internal/server/middleware/auth/auth/jwt"
+)
+
+// Authenticators marks that every optional authenticator was built
+// (they register themselves in authn; PASETO registers on import).
+type Authenticators struct{}
+
+func ProvideAuthenticators(_ *jwt.Authenticator, _ *apikey.APIKeys) Authenticators {
+	return Authenticators{}
+}
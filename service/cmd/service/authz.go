@@ -0,0 +1,41 @@
+package main
+
+import (
+	"service/internal/conf/v1"
+	server_grpc "service/internal/server/grpc"
+	server_http "service/internal/server/http"
+	"service/internal/server/middleware/auth/authz"
+	"service/internal/server/middleware/auth/authz/endpoint"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// AuthzChecked marks that the role table was checked against the servers.
+type AuthzChecked struct{}
+
+// ValidateAuthz compares (auth.v1.rule) options with the operations served
+// over gRPC and HTTP; with auth.authz.validate a mismatch fails boot.
+func ValidateAuthz(c *conf.Auth, groups []endpoint.ServiceGroup, gs *grpc.Server, hs *http.Server, logger log.Logger) (AuthzChecked, error) {
+	var served []string
+	if gs != nil {
+		served = append(served, server_grpc.Operations(gs)...)
+	}
+	if hs != nil {
+		served = append(served, server_http.Operations(hs)...)
+	}
+
+	report, fail, err := authz.Validate(groups, c, served)
+	if err != nil {
+		return AuthzChecked{}, err
+	}
+	if report == nil {
+		return AuthzChecked{}, nil
+	}
+	if fail {
+		return AuthzChecked{}, report
+	}
+	log.NewHelper(logger).Warnf("[AUTHZ] %v", report)
+	return AuthzChecked{}, nil
+}
@@ -11,7 +11,14 @@ import (
 	"time"
 
 	"service/internal/conf/v1"
+	"service/internal/data/tenant"
 	"service/internal/out/broker"
+	"service/internal/server/middleware/auth/auth/authn"
+	"service/internal/server/middleware/auth/auth/paseto"
+	"service/internal/server/middleware/auth/authz"
+	"service/internal/server/middleware/auth/authz/endpoint"
+	"service/internal/server/middleware/traffic"
+	"service/internal/server/utils/ip"
 	mylog "service/pkg/logger"
 
 	krlogrus "github.com/go-kratos/kratos/contrib/log/logrus/v2"
@@ -55,7 +62,7 @@ func newLogger(mode string) klog.Logger {
 	return klog.With(base, "caller", klog.DefaultCaller)
 }
 
-func newApp(logger klog.Logger, app *conf.App, gs *grpc.Server, hs *http.Server, b *broker.Broker, data *conf.Data) *kratos.App {
+func newApp(logger klog.Logger, app *conf.App, gs *grpc.Server, hs *http.Server, b *broker.Broker, data *conf.Data, _ Authenticators, _ AuthzChecked) *kratos.App {
 	// safe start broker
 	if b != nil && data != nil {
 		go b.Start(data)
@@ -112,6 +119,13 @@ func main() {
 	}
 
 	logger := newLogger(bc.App.GetMode())
+	server_utils_ip.Init(bc.Server)
+	paseto.Init(bc.Auth)
+	defer paseto.Close() // stops the public keyring watcher
+	authn.Init(bc.Auth)
+	tenant.Init(bc.Auth)
+	authz.Init(bc.Auth)
+	traffic.UseIdentity(endpoint.Identity) // user-keyed rate limits from verified credentials
 
 	app, cleanup, err := wireApp(&bc, logger)
 	if err != nil {
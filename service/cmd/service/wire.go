@@ -11,6 +11,11 @@ import (
 
 	// "service/internal/out/webhooks"
 	"service/internal/server"
+	"service/internal/server/middleware/auth/auth/apikey"
+	"service/internal/server/middleware/auth/auth/jwt"
+	"service/internal/server/middleware/auth/auth/revocation"
+	"service/internal/server/middleware/traffic"
+	iq "service/internal/server/middleware/traffic/individual_quotas"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
@@ -26,6 +31,8 @@ func wireApp(bootstrap *conf.Bootstrap, logger log.Logger) (*kratos.App, func(),
 		ProvideAppFromBootstrap,
 		ProvideServerFromBootstrap,
 		ProvideDataFromBootstrap,
+		ProvideAuthFromBootstrap,
+		ProvideTrafficFromBootstrap,
 		// ProvideWebhooksFromBootstrap,
 
 		// infra
@@ -33,6 +40,13 @@ func wireApp(bootstrap *conf.Bootstrap, logger log.Logger) (*kratos.App, func(),
 		data.ProviderSet,
 		// webhooks.ProviderSet,
 		broker.ProviderSet,
+		revocation.ProviderSet, // token denylist
+		apikey.ProviderSet,     // API keys for machine clients
+		jwt.ProviderSet,        // OIDC/JWT authenticator
+		traffic.ProviderSet,    // shared rate limit buckets
+		iq.ProviderSet,         // individual quota push updates + /qt
+		ProvideAuthenticators,
+		ValidateAuthz,
 
 		feature.ProviderAuthSet, // auth groups
 
@@ -29,3 +29,17 @@ func ProvideWebhooksFromBootstrap(b *conf.Bootstrap) *conf.Webhooks {
 	}
 	return b.Webhooks
 }
+
+func ProvideAuthFromBootstrap(b *conf.Bootstrap) *conf.Auth {
+	if b == nil {
+		return nil
+	}
+	return b.Auth
+}
+
+func ProvideTrafficFromBootstrap(b *conf.Bootstrap) *conf.Traffic {
+	if b == nil {
+		return nil
+	}
+	return b.Traffic
+}
@@ -19,6 +19,11 @@ import (
 	"service/internal/out/broker"
 	"service/internal/server/grpc"
 	"service/internal/server/http"
+	"service/internal/server/middleware/auth/auth/apikey"
+	"service/internal/server/middleware/auth/auth/jwt"
+	"service/internal/server/middleware/auth/auth/revocation"
+	"service/internal/server/middleware/traffic"
+	"service/internal/server/middleware/traffic/individual_quotas"
 )
 
 import (
@@ -44,13 +49,81 @@ func wireApp(bootstrap *conf.Bootstrap, logger log.Logger) (*kratos.App, func(),
 	grpcRegister := example.NewExampleGRPCRegistrer(exampleService)
 	allRegistrers := BuildAllRegistrars(httpRegister, grpcRegister)
 	v := ProvideGRPCRegistrers(allRegistrers)
-	grpcServer := server_grpc.NewGRPCServer(server, app, v, logger)
-	v2 := ProvideHTTPRegistrers(allRegistrers)
-	v3 := feature.ProvideAuthGroups(exampleService)
-	httpServer := server_http.NewHTTPServer(server, app, v2, v3, logger)
-	brokerBroker := broker.NewBroker(logger)
-	kratosApp := newApp(logger, app, grpcServer, httpServer, brokerBroker, confData)
+	v2 := feature.ProvideAuthGroups(exampleService)
+	auth := ProvideAuthFromBootstrap(bootstrap)
+	confTraffic := ProvideTrafficFromBootstrap(bootstrap)
+	client, cleanup2, err := data.NewRedis(confData, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	shared := traffic.NewShared(confTraffic, app, client, logger)
+	hub, cleanup3, err := individual_quotas.NewHub(confTraffic, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	grpcServer, err := server_grpc.NewGRPCServer(server, app, v, v2, auth, confTraffic, shared, hub, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	v3 := ProvideHTTPRegistrers(allRegistrers)
+	revocationRevocation, cleanup4, err := revocation.NewRevocation(auth, dataData, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	apiKeys, cleanup5, err := apikey.NewAPIKeys(auth, dataData, logger)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	httpServer, err := server_http.NewHTTPServer(server, app, v3, v2, auth, confTraffic, shared, hub, revocationRevocation, apiKeys, logger)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	brokerBroker := broker.NewBroker(revocationRevocation, hub, logger)
+	authenticator, cleanup6, err := jwt.NewAuthenticator(auth, logger)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	authenticators := ProvideAuthenticators(authenticator, apiKeys)
+	authzChecked, err := ValidateAuthz(auth, v2, grpcServer, httpServer, logger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	kratosApp := newApp(logger, app, grpcServer, httpServer, brokerBroker, confData, authenticators, authzChecked)
 	return kratosApp, func() {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -32,6 +32,8 @@ type Bootstrap struct {
 	Data          *Data                  `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`         // data storage, brokers and etc.
 	App           *App                   `protobuf:"bytes,3,opt,name=app,proto3" json:"app,omitempty"`           // application metadata
 	Webhooks      *Webhooks              `protobuf:"bytes,4,opt,name=webhooks,proto3" json:"webhooks,omitempty"` // webhooks configuration
+	Auth          *Auth                  `protobuf:"bytes,5,opt,name=auth,proto3" json:"auth,omitempty"`         // authentication/authorization settings
+	Traffic       *Traffic               `protobuf:"bytes,6,opt,name=traffic,proto3" json:"traffic,omitempty"`   // rate limiting / load shedding (HTTP and gRPC)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -94,6 +96,20 @@ func (x *Bootstrap) GetWebhooks() *Webhooks {
 	return nil
 }
 
+func (x *Bootstrap) GetAuth() *Auth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *Bootstrap) GetTraffic() *Traffic {
+	if x != nil {
+		return x.Traffic
+	}
+	return nil
+}
+
 type App struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Mode          string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`       // mode of operation (dev/prod/etc.)
@@ -159,10 +175,12 @@ type Server struct {
 	// --------------------------------------------------------------------------
 	// 3.x) Instances of servers
 	// --------------------------------------------------------------------------
-	Http          *Server_HTTP `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
-	Grpc          *Server_GRPC `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Http            *Server_HTTP `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
+	Grpc            *Server_GRPC `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+	TrustedProxies  []string     `protobuf:"bytes,3,rep,name=trusted_proxies,json=trustedProxies,proto3" json:"trusted_proxies,omitempty"`    // CIDRs/IPs whose forwarded_header is trusted (empty = none)
+	ForwardedHeader string       `protobuf:"bytes,4,opt,name=forwarded_header,json=forwardedHeader,proto3" json:"forwarded_header,omitempty"` // header the trusted proxies set: "x-forwarded-for" (default) or "forwarded"; no other is read
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Server) Reset() {
@@ -209,6 +227,20 @@ func (x *Server) GetGrpc() *Server_GRPC {
 	return nil
 }
 
+func (x *Server) GetTrustedProxies() []string {
+	if x != nil {
+		return x.TrustedProxies
+	}
+	return nil
+}
+
+func (x *Server) GetForwardedHeader() string {
+	if x != nil {
+		return x.ForwardedHeader
+	}
+	return ""
+}
+
 type Data struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// --------------------------------------------------------------------------
@@ -216,6 +248,7 @@ type Data struct {
 	// --------------------------------------------------------------------------
 	Database      *Data_Database `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
 	Mqtt          *MQTT          `protobuf:"bytes,2,opt,name=mqtt,proto3" json:"mqtt,omitempty"`
+	Redis         *Data_Redis    `protobuf:"bytes,3,opt,name=redis,proto3" json:"redis,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -264,6 +297,13 @@ func (x *Data) GetMqtt() *MQTT {
 	return nil
 }
 
+func (x *Data) GetRedis() *Data_Redis {
+	if x != nil {
+		return x.Redis
+	}
+	return nil
+}
+
 type MQTT struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	Active               bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`                                                          // is MQTT active
@@ -504,6 +544,166 @@ func (x *Webhook) GetRoutes() *Webhook_Routes {
 	return nil
 }
 
+type Auth struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Token          *Auth_Token            `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Revocation     *Auth_Revocation       `protobuf:"bytes,2,opt,name=revocation,proto3" json:"revocation,omitempty"`
+	Tenant         *Auth_Tenant           `protobuf:"bytes,3,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	ApiKey         *Auth_ApiKey           `protobuf:"bytes,4,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	Authenticators []string               `protobuf:"bytes,5,rep,name=authenticators,proto3" json:"authenticators,omitempty"` // order of authenticators: "paseto", "jwt", "apikey" (empty = paseto, apikey)
+	Jwt            *Auth_Jwt              `protobuf:"bytes,6,opt,name=jwt,proto3" json:"jwt,omitempty"`
+	Authz          *Auth_Authz            `protobuf:"bytes,7,opt,name=authz,proto3" json:"authz,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Auth) Reset() {
+	*x = Auth{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth) ProtoMessage() {}
+
+func (x *Auth) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth.ProtoReflect.Descriptor instead.
+func (*Auth) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Auth) GetToken() *Auth_Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *Auth) GetRevocation() *Auth_Revocation {
+	if x != nil {
+		return x.Revocation
+	}
+	return nil
+}
+
+func (x *Auth) GetTenant() *Auth_Tenant {
+	if x != nil {
+		return x.Tenant
+	}
+	return nil
+}
+
+func (x *Auth) GetApiKey() *Auth_ApiKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *Auth) GetAuthenticators() []string {
+	if x != nil {
+		return x.Authenticators
+	}
+	return nil
+}
+
+func (x *Auth) GetJwt() *Auth_Jwt {
+	if x != nil {
+		return x.Jwt
+	}
+	return nil
+}
+
+func (x *Auth) GetAuthz() *Auth_Authz {
+	if x != nil {
+		return x.Authz
+	}
+	return nil
+}
+
+type Traffic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Http          *Traffic_Limits        `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
+	Grpc          *Traffic_Limits        `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+	Shared        *Traffic_Shared        `protobuf:"bytes,3,opt,name=shared,proto3" json:"shared,omitempty"`
+	Quotas        *Traffic_Quotas        `protobuf:"bytes,4,opt,name=quotas,proto3" json:"quotas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Traffic) Reset() {
+	*x = Traffic{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic) ProtoMessage() {}
+
+func (x *Traffic) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic.ProtoReflect.Descriptor instead.
+func (*Traffic) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Traffic) GetHttp() *Traffic_Limits {
+	if x != nil {
+		return x.Http
+	}
+	return nil
+}
+
+func (x *Traffic) GetGrpc() *Traffic_Limits {
+	if x != nil {
+		return x.Grpc
+	}
+	return nil
+}
+
+func (x *Traffic) GetShared() *Traffic_Shared {
+	if x != nil {
+		return x.Shared
+	}
+	return nil
+}
+
+func (x *Traffic) GetQuotas() *Traffic_Quotas {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
 // --------------------------------------------------------------------------
 // 3.1) HTTP — HTTP server
 // --------------------------------------------------------------------------
@@ -518,7 +718,7 @@ type Server_HTTP struct {
 
 func (x *Server_HTTP) Reset() {
 	*x = Server_HTTP{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -530,7 +730,7 @@ func (x *Server_HTTP) String() string {
 func (*Server_HTTP) ProtoMessage() {}
 
 func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[8]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -581,7 +781,7 @@ type Server_GRPC struct {
 
 func (x *Server_GRPC) Reset() {
 	*x = Server_GRPC{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -593,7 +793,7 @@ func (x *Server_GRPC) String() string {
 func (*Server_GRPC) ProtoMessage() {}
 
 func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[9]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -644,7 +844,7 @@ type Data_Database struct {
 
 func (x *Data_Database) Reset() {
 	*x = Data_Database{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -656,7 +856,7 @@ func (x *Data_Database) String() string {
 func (*Data_Database) ProtoMessage() {}
 
 func (x *Data_Database) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[10]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -693,6 +893,93 @@ func (x *Data_Database) GetSeed() bool {
 	return false
 }
 
+// --------------------------------------------------------------------------
+// 4.2) Redis — shared state between replicas (password: REDIS_PASSWORD env)
+// --------------------------------------------------------------------------
+type Data_Redis struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`                                // is Redis active
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`                                     // address: "127.0.0.1:6379"
+	Db            int32                  `protobuf:"varint,3,opt,name=db,proto3" json:"db,omitempty"`                                        // database number
+	DialTimeout   *durationpb.Duration   `protobuf:"bytes,4,opt,name=dial_timeout,json=dialTimeout,proto3" json:"dial_timeout,omitempty"`    // connect timeout
+	ReadTimeout   *durationpb.Duration   `protobuf:"bytes,5,opt,name=read_timeout,json=readTimeout,proto3" json:"read_timeout,omitempty"`    // read timeout
+	WriteTimeout  *durationpb.Duration   `protobuf:"bytes,6,opt,name=write_timeout,json=writeTimeout,proto3" json:"write_timeout,omitempty"` // write timeout
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Redis) Reset() {
+	*x = Data_Redis{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Redis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Redis) ProtoMessage() {}
+
+func (x *Data_Redis) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Redis.ProtoReflect.Descriptor instead.
+func (*Data_Redis) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *Data_Redis) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Data_Redis) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Data_Redis) GetDb() int32 {
+	if x != nil {
+		return x.Db
+	}
+	return 0
+}
+
+func (x *Data_Redis) GetDialTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.DialTimeout
+	}
+	return nil
+}
+
+func (x *Data_Redis) GetReadTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ReadTimeout
+	}
+	return nil
+}
+
+func (x *Data_Redis) GetWriteTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.WriteTimeout
+	}
+	return nil
+}
+
 // --------------------------------------------------------------------------
 // 6.1) List of routes (example)
 // --------------------------------------------------------------------------
@@ -706,7 +993,7 @@ type Webhook_Routes struct {
 
 func (x *Webhook_Routes) Reset() {
 	*x = Webhook_Routes{}
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -718,7 +1005,7 @@ func (x *Webhook_Routes) String() string {
 func (*Webhook_Routes) ProtoMessage() {}
 
 func (x *Webhook_Routes) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_conf_v1_conf_proto_msgTypes[11]
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -748,59 +1035,1371 @@ func (x *Webhook_Routes) GetRoute2() string {
 	return ""
 }
 
-var File_internal_conf_v1_conf_proto protoreflect.FileDescriptor
+// --------------------------------------------------------------------------
+// 7.1) Token — validation policy applied to every verified token
+// --------------------------------------------------------------------------
+type Auth_Token struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Leeway         *durationpb.Duration   `protobuf:"bytes,1,opt,name=leeway,proto3" json:"leeway,omitempty"`                                       // clock skew tolerance for exp/nbf/iat
+	Issuer         string                 `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`                                       // expected "iss" claim (empty = not checked)
+	Audience       []string               `protobuf:"bytes,3,rep,name=audience,proto3" json:"audience,omitempty"`                                   // accepted "aud" values (empty = not checked)
+	RequiredClaims []string               `protobuf:"bytes,4,rep,name=required_claims,json=requiredClaims,proto3" json:"required_claims,omitempty"` // claims that must be present, e.g. ["username", "roles"]
+	Type           string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`                                           // expected "type" claim (empty = "access")
+	MaxAge         *durationpb.Duration   `protobuf:"bytes,6,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`                         // maximum age since "iat" (0 = not checked)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Auth_Token) Reset() {
+	*x = Auth_Token{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-const file_internal_conf_v1_conf_proto_rawDesc = "" +
-	"\n" +
-	"\x1binternal/conf/v1/conf.proto\x12\x10internal.conf.v1\x1a\x1egoogle/protobuf/duration.proto\"\xca\x01\n" +
-	"\tBootstrap\x120\n" +
-	"\x06server\x18\x01 \x01(\v2\x18.internal.conf.v1.ServerR\x06server\x12*\n" +
-	"\x04data\x18\x02 \x01(\v2\x16.internal.conf.v1.DataR\x04data\x12'\n" +
-	"\x03app\x18\x03 \x01(\v2\x15.internal.conf.v1.AppR\x03app\x126\n" +
-	"\bwebhooks\x18\x04 \x01(\v2\x1a.internal.conf.v1.WebhooksR\bwebhooks\"G\n" +
-	"\x03App\x12\x12\n" +
-	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\"\xc4\x02\n" +
-	"\x06Server\x121\n" +
-	"\x04http\x18\x01 \x01(\v2\x1d.internal.conf.v1.Server.HTTPR\x04http\x121\n" +
-	"\x04grpc\x18\x02 \x01(\v2\x1d.internal.conf.v1.Server.GRPCR\x04grpc\x1ai\n" +
-	"\x04HTTP\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1ai\n" +
-	"\x04GRPC\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xc7\x01\n" +
-	"\x04Data\x12;\n" +
-	"\bdatabase\x18\x01 \x01(\v2\x1f.internal.conf.v1.Data.DatabaseR\bdatabase\x12*\n" +
-	"\x04mqtt\x18\x02 \x01(\v2\x16.internal.conf.v1.MQTTR\x04mqtt\x1aV\n" +
-	"\bDatabase\x12\x16\n" +
-	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1e\n" +
-	"\n" +
-	"migrations\x18\x02 \x01(\bR\n" +
-	"migrations\x12\x12\n" +
-	"\x04seed\x18\x03 \x01(\bR\x04seed\"\xf1\x01\n" +
-	"\x04MQTT\x12\x16\n" +
-	"\x06active\x18\x01 \x01(\bR\x06active\x12\x16\n" +
-	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1b\n" +
-	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12O\n" +
-	"\x16max_reconnect_interval\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x14maxReconnectInterval\x12\x16\n" +
-	"\x06topics\x18\x05 \x03(\tR\x06topics\x123\n" +
-	"\apublish\x18\x06 \x01(\v2\x19.internal.conf.v1.PublishR\apublish\"9\n" +
-	"\aPublish\x12\x16\n" +
-	"\x06topic1\x18\x01 \x01(\tR\x06topic1\x12\x16\n" +
-	"\x06topic2\x18\x02 \x01(\tR\x06topic2\"?\n" +
-	"\bWebhooks\x123\n" +
-	"\awebhook\x18\x01 \x01(\v2\x19.internal.conf.v1.WebhookR\awebhook\"\xc4\x01\n" +
-	"\aWebhook\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\x123\n" +
-	"\atimeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x128\n" +
-	"\x06routes\x18\x03 \x01(\v2 .internal.conf.v1.Webhook.RoutesR\x06routes\x1a8\n" +
-	"\x06Routes\x12\x16\n" +
-	"\x06route1\x18\x01 \x01(\tR\x06route1\x12\x16\n" +
-	"\x06route2\x18\x02 \x01(\tR\x06route2B\x1fZ\x1dservice/internal/conf/v1;confb\x06proto3"
+func (x *Auth_Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_Token) ProtoMessage() {}
+
+func (x *Auth_Token) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_Token.ProtoReflect.Descriptor instead.
+func (*Auth_Token) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *Auth_Token) GetLeeway() *durationpb.Duration {
+	if x != nil {
+		return x.Leeway
+	}
+	return nil
+}
+
+func (x *Auth_Token) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *Auth_Token) GetAudience() []string {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+func (x *Auth_Token) GetRequiredClaims() []string {
+	if x != nil {
+		return x.RequiredClaims
+	}
+	return nil
+}
+
+func (x *Auth_Token) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Auth_Token) GetMaxAge() *durationpb.Duration {
+	if x != nil {
+		return x.MaxAge
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 7.2) Revocation — denylist of revoked tokens
+// --------------------------------------------------------------------------
+type Auth_Revocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`                                // is revocation check active
+	MqttTopic     string                 `protobuf:"bytes,2,opt,name=mqtt_topic,json=mqttTopic,proto3" json:"mqtt_topic,omitempty"`          // topic to publish/receive revocations (empty = local only)
+	DefaultTtl    *durationpb.Duration   `protobuf:"bytes,3,opt,name=default_ttl,json=defaultTtl,proto3" json:"default_ttl,omitempty"`       // username cut-off lifetime when "exp" is unknown (single tokens use token.max_age)
+	CleanupEvery  *durationpb.Duration   `protobuf:"bytes,4,opt,name=cleanup_every,json=cleanupEvery,proto3" json:"cleanup_every,omitempty"` // expired entries cleanup interval
+	SyncEvery     *durationpb.Duration   `protobuf:"bytes,5,opt,name=sync_every,json=syncEvery,proto3" json:"sync_every,omitempty"`          // reload from database interval (0 = only at start)
+	AdminRoles    []string               `protobuf:"bytes,6,rep,name=admin_roles,json=adminRoles,proto3" json:"admin_roles,omitempty"`       // roles allowed to call the revoke endpoint (empty = endpoint disabled)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Auth_Revocation) Reset() {
+	*x = Auth_Revocation{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth_Revocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_Revocation) ProtoMessage() {}
+
+func (x *Auth_Revocation) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_Revocation.ProtoReflect.Descriptor instead.
+func (*Auth_Revocation) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 1}
+}
+
+func (x *Auth_Revocation) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Auth_Revocation) GetMqttTopic() string {
+	if x != nil {
+		return x.MqttTopic
+	}
+	return ""
+}
+
+func (x *Auth_Revocation) GetDefaultTtl() *durationpb.Duration {
+	if x != nil {
+		return x.DefaultTtl
+	}
+	return nil
+}
+
+func (x *Auth_Revocation) GetCleanupEvery() *durationpb.Duration {
+	if x != nil {
+		return x.CleanupEvery
+	}
+	return nil
+}
+
+func (x *Auth_Revocation) GetSyncEvery() *durationpb.Duration {
+	if x != nil {
+		return x.SyncEvery
+	}
+	return nil
+}
+
+func (x *Auth_Revocation) GetAdminRoles() []string {
+	if x != nil {
+		return x.AdminRoles
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 7.3) Tenant — rows scoped by the "company_id" claim
+// --------------------------------------------------------------------------
+type Auth_Tenant struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Active          bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`                                         // is tenant isolation active
+	PrivilegedRoles []string               `protobuf:"bytes,2,rep,name=privileged_roles,json=privilegedRoles,proto3" json:"privileged_roles,omitempty"` // roles allowed to access every company
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Auth_Tenant) Reset() {
+	*x = Auth_Tenant{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth_Tenant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_Tenant) ProtoMessage() {}
+
+func (x *Auth_Tenant) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_Tenant.ProtoReflect.Descriptor instead.
+func (*Auth_Tenant) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 2}
+}
+
+func (x *Auth_Tenant) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Auth_Tenant) GetPrivilegedRoles() []string {
+	if x != nil {
+		return x.PrivilegedRoles
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 7.4) ApiKey — "X-Secret-Access" keys for machine clients (requires database)
+// --------------------------------------------------------------------------
+type Auth_ApiKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`                                     // is API key authentication active
+	AdminRoles    []string               `protobuf:"bytes,2,rep,name=admin_roles,json=adminRoles,proto3" json:"admin_roles,omitempty"`            // roles allowed to create/list/revoke keys (empty = endpoint disabled)
+	CacheTtl      *durationpb.Duration   `protobuf:"bytes,3,opt,name=cache_ttl,json=cacheTtl,proto3" json:"cache_ttl,omitempty"`                  // how long a verified key is cached (0 = 30s)
+	LastUsedEvery *durationpb.Duration   `protobuf:"bytes,4,opt,name=last_used_every,json=lastUsedEvery,proto3" json:"last_used_every,omitempty"` // minimum interval between "last_used_at" writes (0 = 1m)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Auth_ApiKey) Reset() {
+	*x = Auth_ApiKey{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth_ApiKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_ApiKey) ProtoMessage() {}
+
+func (x *Auth_ApiKey) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_ApiKey.ProtoReflect.Descriptor instead.
+func (*Auth_ApiKey) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 3}
+}
+
+func (x *Auth_ApiKey) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Auth_ApiKey) GetAdminRoles() []string {
+	if x != nil {
+		return x.AdminRoles
+	}
+	return nil
+}
+
+func (x *Auth_ApiKey) GetCacheTtl() *durationpb.Duration {
+	if x != nil {
+		return x.CacheTtl
+	}
+	return nil
+}
+
+func (x *Auth_ApiKey) GetLastUsedEvery() *durationpb.Duration {
+	if x != nil {
+		return x.LastUsedEvery
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 7.5) Jwt — JWTs issued by an OIDC provider, verified against its JWKS
+// --------------------------------------------------------------------------
+type Auth_Jwt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jwks          string                 `protobuf:"bytes,1,opt,name=jwks,proto3" json:"jwks,omitempty"`                                     // JWKS URL (http/https) or file path
+	RefreshEvery  *durationpb.Duration   `protobuf:"bytes,2,opt,name=refresh_every,json=refreshEvery,proto3" json:"refresh_every,omitempty"` // JWKS reload interval (0 = 1h)
+	Issuer        string                 `protobuf:"bytes,3,opt,name=issuer,proto3" json:"issuer,omitempty"`                                 // expected "iss" claim (empty = not checked)
+	Audience      []string               `protobuf:"bytes,4,rep,name=audience,proto3" json:"audience,omitempty"`                             // accepted "aud" values (empty = not checked)
+	Leeway        *durationpb.Duration   `protobuf:"bytes,5,opt,name=leeway,proto3" json:"leeway,omitempty"`                                 // clock skew tolerance for exp/nbf/iat
+	Algorithms    []string               `protobuf:"bytes,6,rep,name=algorithms,proto3" json:"algorithms,omitempty"`                         // accepted "alg" values (empty = RS256, ES256, EdDSA...)
+	SubjectClaim  string                 `protobuf:"bytes,7,opt,name=subject_claim,json=subjectClaim,proto3" json:"subject_claim,omitempty"` // principal subject (empty = "sub")
+	RolesClaim    string                 `protobuf:"bytes,8,opt,name=roles_claim,json=rolesClaim,proto3" json:"roles_claim,omitempty"`       // dotted path to roles, e.g. "realm_access.roles" (empty = "roles")
+	CompanyClaim  string                 `protobuf:"bytes,9,opt,name=company_claim,json=companyClaim,proto3" json:"company_claim,omitempty"` // tenant claim (empty = "company_id")
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Auth_Jwt) Reset() {
+	*x = Auth_Jwt{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth_Jwt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_Jwt) ProtoMessage() {}
+
+func (x *Auth_Jwt) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_Jwt.ProtoReflect.Descriptor instead.
+func (*Auth_Jwt) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 4}
+}
+
+func (x *Auth_Jwt) GetJwks() string {
+	if x != nil {
+		return x.Jwks
+	}
+	return ""
+}
+
+func (x *Auth_Jwt) GetRefreshEvery() *durationpb.Duration {
+	if x != nil {
+		return x.RefreshEvery
+	}
+	return nil
+}
+
+func (x *Auth_Jwt) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *Auth_Jwt) GetAudience() []string {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+func (x *Auth_Jwt) GetLeeway() *durationpb.Duration {
+	if x != nil {
+		return x.Leeway
+	}
+	return nil
+}
+
+func (x *Auth_Jwt) GetAlgorithms() []string {
+	if x != nil {
+		return x.Algorithms
+	}
+	return nil
+}
+
+func (x *Auth_Jwt) GetSubjectClaim() string {
+	if x != nil {
+		return x.SubjectClaim
+	}
+	return ""
+}
+
+func (x *Auth_Jwt) GetRolesClaim() string {
+	if x != nil {
+		return x.RolesClaim
+	}
+	return ""
+}
+
+func (x *Auth_Jwt) GetCompanyClaim() string {
+	if x != nil {
+		return x.CompanyClaim
+	}
+	return ""
+}
+
+// --------------------------------------------------------------------------
+// 7.6) Authz — role table ((auth.v1.rule) options) enforcement
+// --------------------------------------------------------------------------
+type Auth_Authz struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DefaultDeny      bool                   `protobuf:"varint,1,opt,name=default_deny,json=defaultDeny,proto3" json:"default_deny,omitempty"`                                                                                // operations without (auth.v1.rule) are rejected (public ones need public: true)
+	Validate         bool                   `protobuf:"varint,2,opt,name=validate,proto3" json:"validate,omitempty"`                                                                                                         // fail boot if an operation has no rule or a rule points to no operation
+	PublicOperations []string               `protobuf:"bytes,3,rep,name=public_operations,json=publicOperations,proto3" json:"public_operations,omitempty"`                                                                  // extra public operations: "/pkg.Service/Method" or "/pkg.Service/*"
+	RoleHierarchy    map[string]string      `protobuf:"bytes,4,rep,name=role_hierarchy,json=roleHierarchy,proto3" json:"role_hierarchy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // role -> implied roles (comma separated), e.g. ADMIN: "EDITOR"
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Auth_Authz) Reset() {
+	*x = Auth_Authz{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth_Authz) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth_Authz) ProtoMessage() {}
+
+func (x *Auth_Authz) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth_Authz.ProtoReflect.Descriptor instead.
+func (*Auth_Authz) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{8, 5}
+}
+
+func (x *Auth_Authz) GetDefaultDeny() bool {
+	if x != nil {
+		return x.DefaultDeny
+	}
+	return false
+}
+
+func (x *Auth_Authz) GetValidate() bool {
+	if x != nil {
+		return x.Validate
+	}
+	return false
+}
+
+func (x *Auth_Authz) GetPublicOperations() []string {
+	if x != nil {
+		return x.PublicOperations
+	}
+	return nil
+}
+
+func (x *Auth_Authz) GetRoleHierarchy() map[string]string {
+	if x != nil {
+		return x.RoleHierarchy
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 8.1) Limits — limiter of one server (unset values = built-in defaults)
+// --------------------------------------------------------------------------
+type Traffic_Limits struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	InflightMax       *int32                 `protobuf:"varint,1,opt,name=inflight_max,json=inflightMax,proto3,oneof" json:"inflight_max,omitempty"` // maximum concurrent requests (unset = 400, 0 = no cap)
+	RateRps           *float64               `protobuf:"fixed64,2,opt,name=rate_rps,json=rateRps,proto3,oneof" json:"rate_rps,omitempty"`            // token bucket refill (requests per second) (unset = 150, 0 = no token bucket)
+	RateBurst         int32                  `protobuf:"varint,3,opt,name=rate_burst,json=rateBurst,proto3" json:"rate_burst,omitempty"`             // token bucket capacity
+	KeyBy             string                 `protobuf:"bytes,4,opt,name=key_by,json=keyBy,proto3" json:"key_by,omitempty"`                          // bucket key: "global", "ip" or "user"
+	Cpu               *Traffic_Cpu           `protobuf:"bytes,5,opt,name=cpu,proto3" json:"cpu,omitempty"`                                           // adaptive protection by CPU (BBR)
+	Routes            []*Traffic_Route       `protobuf:"bytes,6,rep,name=routes,proto3" json:"routes,omitempty"`                                     // per-route overrides (first match wins)
+	MaxKeys           int32                  `protobuf:"varint,7,opt,name=max_keys,json=maxKeys,proto3" json:"max_keys,omitempty"`                   // token buckets kept in memory, LRU evicted (0 = 100000)
+	KeyIdleTtl        *durationpb.Duration   `protobuf:"bytes,8,opt,name=key_idle_ttl,json=keyIdleTtl,proto3" json:"key_idle_ttl,omitempty"`         // bucket dropped after this time without calls (0 = 10m)
+	Shadow            []string               `protobuf:"bytes,9,rep,name=shadow,proto3" json:"shadow,omitempty"`                                     // dry-run limiters (log + count, never reject): "inflight", "rate", "bbr", "iq"
+	QueueMax          *int32                 `protobuf:"varint,10,opt,name=queue_max,json=queueMax,proto3,oneof" json:"queue_max,omitempty"`         // calls waiting for an in-flight slot (0 = 429 at once)
+	QueueTimeout      *durationpb.Duration   `protobuf:"bytes,11,opt,name=queue_timeout,json=queueTimeout,proto3" json:"queue_timeout,omitempty"`    // maximum wait in the queue; the request deadline also applies (0 = 1s)
+	QueueOrder        string                 `protobuf:"bytes,12,opt,name=queue_order,json=queueOrder,proto3" json:"queue_order,omitempty"`          // "fifo" (oldest first) or "lifo" (newest first, oldest dropped when full)
+	Concurrency       string                 `protobuf:"bytes,13,opt,name=concurrency,proto3" json:"concurrency,omitempty"`                          // in-flight cap: "fixed" (inflight_max) or "adaptive" (by latency, up to inflight_max)
+	Adaptive          *Traffic_Adaptive      `protobuf:"bytes,14,opt,name=adaptive,proto3" json:"adaptive,omitempty"`
+	CriticalityHeader string                 `protobuf:"bytes,15,opt,name=criticality_header,json=criticalityHeader,proto3" json:"criticality_header,omitempty"`     // shedding class ("critical", "default", "sheddable") set by trusted proxies (empty = off, route class only); the proxy must strip it from client requests
+	CriticalHeadroom  *int32                 `protobuf:"varint,16,opt,name=critical_headroom,json=criticalHeadroom,proto3,oneof" json:"critical_headroom,omitempty"` // in-flight slots above inflight_max only critical calls may take (unset = 10% of inflight_max, 0 = none)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Traffic_Limits) Reset() {
+	*x = Traffic_Limits{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Limits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Limits) ProtoMessage() {}
+
+func (x *Traffic_Limits) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Limits.ProtoReflect.Descriptor instead.
+func (*Traffic_Limits) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *Traffic_Limits) GetInflightMax() int32 {
+	if x != nil && x.InflightMax != nil {
+		return *x.InflightMax
+	}
+	return 0
+}
+
+func (x *Traffic_Limits) GetRateRps() float64 {
+	if x != nil && x.RateRps != nil {
+		return *x.RateRps
+	}
+	return 0
+}
+
+func (x *Traffic_Limits) GetRateBurst() int32 {
+	if x != nil {
+		return x.RateBurst
+	}
+	return 0
+}
+
+func (x *Traffic_Limits) GetKeyBy() string {
+	if x != nil {
+		return x.KeyBy
+	}
+	return ""
+}
+
+func (x *Traffic_Limits) GetCpu() *Traffic_Cpu {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetRoutes() []*Traffic_Route {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetMaxKeys() int32 {
+	if x != nil {
+		return x.MaxKeys
+	}
+	return 0
+}
+
+func (x *Traffic_Limits) GetKeyIdleTtl() *durationpb.Duration {
+	if x != nil {
+		return x.KeyIdleTtl
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetShadow() []string {
+	if x != nil {
+		return x.Shadow
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetQueueMax() int32 {
+	if x != nil && x.QueueMax != nil {
+		return *x.QueueMax
+	}
+	return 0
+}
+
+func (x *Traffic_Limits) GetQueueTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTimeout
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetQueueOrder() string {
+	if x != nil {
+		return x.QueueOrder
+	}
+	return ""
+}
+
+func (x *Traffic_Limits) GetConcurrency() string {
+	if x != nil {
+		return x.Concurrency
+	}
+	return ""
+}
+
+func (x *Traffic_Limits) GetAdaptive() *Traffic_Adaptive {
+	if x != nil {
+		return x.Adaptive
+	}
+	return nil
+}
+
+func (x *Traffic_Limits) GetCriticalityHeader() string {
+	if x != nil {
+		return x.CriticalityHeader
+	}
+	return ""
+}
+
+func (x *Traffic_Limits) GetCriticalHeadroom() int32 {
+	if x != nil && x.CriticalHeadroom != nil {
+		return *x.CriticalHeadroom
+	}
+	return 0
+}
+
+// --------------------------------------------------------------------------
+// 8.2) Cpu — BBR adaptive limiter
+// --------------------------------------------------------------------------
+type Traffic_Cpu struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Disabled          bool                   `protobuf:"varint,1,opt,name=disabled,proto3" json:"disabled,omitempty"`                                            // turn BBR off (on by default)
+	Window            *durationpb.Duration   `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`                                                 // observation window
+	Buckets           int32                  `protobuf:"varint,3,opt,name=buckets,proto3" json:"buckets,omitempty"`                                              // buckets within the window
+	Threshold         int64                  `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`                                          // CPU load in thousandths (800 = 80%)
+	Quota             float64                `protobuf:"fixed64,5,opt,name=quota,proto3" json:"quota,omitempty"`                                                 // effective CPUs (0 = GOMAXPROCS)
+	CriticalThreshold int64                  `protobuf:"varint,6,opt,name=critical_threshold,json=criticalThreshold,proto3" json:"critical_threshold,omitempty"` // critical calls are shed only above this CPU load (0 = 950); sheddable ones above threshold
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Traffic_Cpu) Reset() {
+	*x = Traffic_Cpu{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Cpu) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Cpu) ProtoMessage() {}
+
+func (x *Traffic_Cpu) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Cpu.ProtoReflect.Descriptor instead.
+func (*Traffic_Cpu) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 1}
+}
+
+func (x *Traffic_Cpu) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *Traffic_Cpu) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *Traffic_Cpu) GetBuckets() int32 {
+	if x != nil {
+		return x.Buckets
+	}
+	return 0
+}
+
+func (x *Traffic_Cpu) GetThreshold() int64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *Traffic_Cpu) GetQuota() float64 {
+	if x != nil {
+		return x.Quota
+	}
+	return 0
+}
+
+func (x *Traffic_Cpu) GetCriticalThreshold() int64 {
+	if x != nil {
+		return x.CriticalThreshold
+	}
+	return 0
+}
+
+// --------------------------------------------------------------------------
+// 8.3) Route — overrides for matching operations (zero values = inherited)
+// --------------------------------------------------------------------------
+type Traffic_Route struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     string                 `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`                     // "/pkg.Service/Method" or "/pkg.Service/*"
+	PathPrefix    string                 `protobuf:"bytes,2,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"` // HTTP path prefix, e.g. "/v1/files/upload"
+	RateRps       float64                `protobuf:"fixed64,3,opt,name=rate_rps,json=rateRps,proto3" json:"rate_rps,omitempty"`        // own token bucket for the route
+	RateBurst     int32                  `protobuf:"varint,4,opt,name=rate_burst,json=rateBurst,proto3" json:"rate_burst,omitempty"`
+	KeyBy         string                 `protobuf:"bytes,5,opt,name=key_by,json=keyBy,proto3" json:"key_by,omitempty"`                    // "global", "ip" or "user"
+	InflightMax   int32                  `protobuf:"varint,6,opt,name=inflight_max,json=inflightMax,proto3" json:"inflight_max,omitempty"` // concurrent requests of the route (on top of the server cap)
+	Shadow        bool                   `protobuf:"varint,7,opt,name=shadow,proto3" json:"shadow,omitempty"`                              // dry-run the route limits (log + count, never reject)
+	QueueMax      int32                  `protobuf:"varint,8,opt,name=queue_max,json=queueMax,proto3" json:"queue_max,omitempty"`          // wait queue of the route cap (zero values = the server ones)
+	QueueTimeout  *durationpb.Duration   `protobuf:"bytes,9,opt,name=queue_timeout,json=queueTimeout,proto3" json:"queue_timeout,omitempty"`
+	QueueOrder    string                 `protobuf:"bytes,10,opt,name=queue_order,json=queueOrder,proto3" json:"queue_order,omitempty"`
+	Criticality   string                 `protobuf:"bytes,11,opt,name=criticality,proto3" json:"criticality,omitempty"` // "critical" (shed last), "default" or "sheddable" (shed first, never queued)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Traffic_Route) Reset() {
+	*x = Traffic_Route{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Route) ProtoMessage() {}
+
+func (x *Traffic_Route) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Route.ProtoReflect.Descriptor instead.
+func (*Traffic_Route) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 2}
+}
+
+func (x *Traffic_Route) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Traffic_Route) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *Traffic_Route) GetRateRps() float64 {
+	if x != nil {
+		return x.RateRps
+	}
+	return 0
+}
+
+func (x *Traffic_Route) GetRateBurst() int32 {
+	if x != nil {
+		return x.RateBurst
+	}
+	return 0
+}
+
+func (x *Traffic_Route) GetKeyBy() string {
+	if x != nil {
+		return x.KeyBy
+	}
+	return ""
+}
+
+func (x *Traffic_Route) GetInflightMax() int32 {
+	if x != nil {
+		return x.InflightMax
+	}
+	return 0
+}
+
+func (x *Traffic_Route) GetShadow() bool {
+	if x != nil {
+		return x.Shadow
+	}
+	return false
+}
+
+func (x *Traffic_Route) GetQueueMax() int32 {
+	if x != nil {
+		return x.QueueMax
+	}
+	return 0
+}
+
+func (x *Traffic_Route) GetQueueTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTimeout
+	}
+	return nil
+}
+
+func (x *Traffic_Route) GetQueueOrder() string {
+	if x != nil {
+		return x.QueueOrder
+	}
+	return ""
+}
+
+func (x *Traffic_Route) GetCriticality() string {
+	if x != nil {
+		return x.Criticality
+	}
+	return ""
+}
+
+// --------------------------------------------------------------------------
+// 8.4) Shared — buckets shared by all replicas in Redis (data.redis)
+// --------------------------------------------------------------------------
+type Traffic_Shared struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`  // token buckets and individual quotas in Redis (GCRA)
+	Prefix        string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`   // key prefix (empty = "traffic:<app name>:")
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"` // per call; slower calls fall back to local buckets (0 = 50ms)
+	Backoff       *durationpb.Duration   `protobuf:"bytes,4,opt,name=backoff,proto3" json:"backoff,omitempty"` // local buckets only for this long after a failure (0 = 5s)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Traffic_Shared) Reset() {
+	*x = Traffic_Shared{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Shared) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Shared) ProtoMessage() {}
+
+func (x *Traffic_Shared) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Shared.ProtoReflect.Descriptor instead.
+func (*Traffic_Shared) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 3}
+}
+
+func (x *Traffic_Shared) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Traffic_Shared) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *Traffic_Shared) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Traffic_Shared) GetBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.Backoff
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 8.5) Quotas — individual quotas per route, from the quota service
+// --------------------------------------------------------------------------
+type Traffic_Quotas struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceUrl    string                 `protobuf:"bytes,1,opt,name=service_url,json=serviceUrl,proto3" json:"service_url,omitempty"`      // quota service (GET <url>/qt?project=); empty = quotas off
+	Refresh       *durationpb.Duration   `protobuf:"bytes,2,opt,name=refresh,proto3" json:"refresh,omitempty"`                              // period between fetches (0 = 24h)
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`                              // per fetch (0 = 10s)
+	BurstFactor   float64                `protobuf:"fixed64,4,opt,name=burst_factor,json=burstFactor,proto3" json:"burst_factor,omitempty"` // bucket capacity = quota * burst_factor (0 = 2)
+	PrefixMatch   bool                   `protobuf:"varint,5,opt,name=prefix_match,json=prefixMatch,proto3" json:"prefix_match,omitempty"`  // also match request paths by their longest segment prefix
+	Snapshot      string                 `protobuf:"bytes,6,opt,name=snapshot,proto3" json:"snapshot,omitempty"`                            // file with the last known good table, loaded at start (empty = none)
+	RetryMin      *durationpb.Duration   `protobuf:"bytes,7,opt,name=retry_min,json=retryMin,proto3" json:"retry_min,omitempty"`            // first retry after a failed fetch, doubled up to retry_max (0 = 5s)
+	RetryMax      *durationpb.Duration   `protobuf:"bytes,8,opt,name=retry_max,json=retryMax,proto3" json:"retry_max,omitempty"`            // (0 = 5m)
+	MaxStale      *durationpb.Duration   `protobuf:"bytes,9,opt,name=max_stale,json=maxStale,proto3" json:"max_stale,omitempty"`            // drop a table not refreshed for this long (0 = keep it)
+	MaxKeys       int32                  `protobuf:"varint,10,opt,name=max_keys,json=maxKeys,proto3" json:"max_keys,omitempty"`             // per-client buckets kept in memory, LRU evicted (0 = 100000)
+	KeyIdleTtl    *durationpb.Duration   `protobuf:"bytes,11,opt,name=key_idle_ttl,json=keyIdleTtl,proto3" json:"key_idle_ttl,omitempty"`   // per-client bucket dropped after this time without calls (0 = 1h)
+	MqttTopic     string                 `protobuf:"bytes,12,opt,name=mqtt_topic,json=mqttTopic,proto3" json:"mqtt_topic,omitempty"`        // quota change events over data.mqtt (empty = none)
+	EventsUrl     string                 `protobuf:"bytes,13,opt,name=events_url,json=eventsUrl,proto3" json:"events_url,omitempty"`        // quota change events as server-sent events (empty = none)
+	AdminRoles    []string               `protobuf:"bytes,14,rep,name=admin_roles,json=adminRoles,proto3" json:"admin_roles,omitempty"`     // roles allowed to call /qt (empty = endpoint disabled)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Traffic_Quotas) Reset() {
+	*x = Traffic_Quotas{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Quotas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Quotas) ProtoMessage() {}
+
+func (x *Traffic_Quotas) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Quotas.ProtoReflect.Descriptor instead.
+func (*Traffic_Quotas) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 4}
+}
+
+func (x *Traffic_Quotas) GetServiceUrl() string {
+	if x != nil {
+		return x.ServiceUrl
+	}
+	return ""
+}
+
+func (x *Traffic_Quotas) GetRefresh() *durationpb.Duration {
+	if x != nil {
+		return x.Refresh
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetBurstFactor() float64 {
+	if x != nil {
+		return x.BurstFactor
+	}
+	return 0
+}
+
+func (x *Traffic_Quotas) GetPrefixMatch() bool {
+	if x != nil {
+		return x.PrefixMatch
+	}
+	return false
+}
+
+func (x *Traffic_Quotas) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *Traffic_Quotas) GetRetryMin() *durationpb.Duration {
+	if x != nil {
+		return x.RetryMin
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetRetryMax() *durationpb.Duration {
+	if x != nil {
+		return x.RetryMax
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetMaxStale() *durationpb.Duration {
+	if x != nil {
+		return x.MaxStale
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetMaxKeys() int32 {
+	if x != nil {
+		return x.MaxKeys
+	}
+	return 0
+}
+
+func (x *Traffic_Quotas) GetKeyIdleTtl() *durationpb.Duration {
+	if x != nil {
+		return x.KeyIdleTtl
+	}
+	return nil
+}
+
+func (x *Traffic_Quotas) GetMqttTopic() string {
+	if x != nil {
+		return x.MqttTopic
+	}
+	return ""
+}
+
+func (x *Traffic_Quotas) GetEventsUrl() string {
+	if x != nil {
+		return x.EventsUrl
+	}
+	return ""
+}
+
+func (x *Traffic_Quotas) GetAdminRoles() []string {
+	if x != nil {
+		return x.AdminRoles
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// 8.6) Adaptive — in-flight cap driven by latency (concurrency: adaptive)
+// --------------------------------------------------------------------------
+type Traffic_Adaptive struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLimit      int32                  `protobuf:"varint,1,opt,name=min_limit,json=minLimit,proto3" json:"min_limit,omitempty"`             // lowest cap (0 = 10)
+	InitialLimit  int32                  `protobuf:"varint,2,opt,name=initial_limit,json=initialLimit,proto3" json:"initial_limit,omitempty"` // cap at start (0 = 50)
+	Tolerance     float64                `protobuf:"fixed64,3,opt,name=tolerance,proto3" json:"tolerance,omitempty"`                          // latency growth tolerated before shrinking (0 = 1.5)
+	Window        *durationpb.Duration   `protobuf:"bytes,4,opt,name=window,proto3" json:"window,omitempty"`                                  // sampling window (0 = 1s)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Traffic_Adaptive) Reset() {
+	*x = Traffic_Adaptive{}
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Traffic_Adaptive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Traffic_Adaptive) ProtoMessage() {}
+
+func (x *Traffic_Adaptive) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_conf_v1_conf_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Traffic_Adaptive.ProtoReflect.Descriptor instead.
+func (*Traffic_Adaptive) Descriptor() ([]byte, []int) {
+	return file_internal_conf_v1_conf_proto_rawDescGZIP(), []int{9, 5}
+}
+
+func (x *Traffic_Adaptive) GetMinLimit() int32 {
+	if x != nil {
+		return x.MinLimit
+	}
+	return 0
+}
+
+func (x *Traffic_Adaptive) GetInitialLimit() int32 {
+	if x != nil {
+		return x.InitialLimit
+	}
+	return 0
+}
+
+func (x *Traffic_Adaptive) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *Traffic_Adaptive) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+var File_internal_conf_v1_conf_proto protoreflect.FileDescriptor
+
+const file_internal_conf_v1_conf_proto_rawDesc = "" +
+	"\n" +
+	"\x1binternal/conf/v1/conf.proto\x12\x10internal.conf.v1\x1a\x1egoogle/protobuf/duration.proto\"\xab\x02\n" +
+	"\tBootstrap\x120\n" +
+	"\x06server\x18\x01 \x01(\v2\x18.internal.conf.v1.ServerR\x06server\x12*\n" +
+	"\x04data\x18\x02 \x01(\v2\x16.internal.conf.v1.DataR\x04data\x12'\n" +
+	"\x03app\x18\x03 \x01(\v2\x15.internal.conf.v1.AppR\x03app\x126\n" +
+	"\bwebhooks\x18\x04 \x01(\v2\x1a.internal.conf.v1.WebhooksR\bwebhooks\x12*\n" +
+	"\x04auth\x18\x05 \x01(\v2\x16.internal.conf.v1.AuthR\x04auth\x123\n" +
+	"\atraffic\x18\x06 \x01(\v2\x19.internal.conf.v1.TrafficR\atraffic\"G\n" +
+	"\x03App\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\x98\x03\n" +
+	"\x06Server\x121\n" +
+	"\x04http\x18\x01 \x01(\v2\x1d.internal.conf.v1.Server.HTTPR\x04http\x121\n" +
+	"\x04grpc\x18\x02 \x01(\v2\x1d.internal.conf.v1.Server.GRPCR\x04grpc\x12'\n" +
+	"\x0ftrusted_proxies\x18\x03 \x03(\tR\x0etrustedProxies\x12)\n" +
+	"\x10forwarded_header\x18\x04 \x01(\tR\x0fforwardedHeader\x1ai\n" +
+	"\x04HTTP\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1ai\n" +
+	"\x04GRPC\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xfd\x03\n" +
+	"\x04Data\x12;\n" +
+	"\bdatabase\x18\x01 \x01(\v2\x1f.internal.conf.v1.Data.DatabaseR\bdatabase\x12*\n" +
+	"\x04mqtt\x18\x02 \x01(\v2\x16.internal.conf.v1.MQTTR\x04mqtt\x122\n" +
+	"\x05redis\x18\x03 \x01(\v2\x1c.internal.conf.v1.Data.RedisR\x05redis\x1aV\n" +
+	"\bDatabase\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1e\n" +
+	"\n" +
+	"migrations\x18\x02 \x01(\bR\n" +
+	"migrations\x12\x12\n" +
+	"\x04seed\x18\x03 \x01(\bR\x04seed\x1a\xff\x01\n" +
+	"\x05Redis\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x0e\n" +
+	"\x02db\x18\x03 \x01(\x05R\x02db\x12<\n" +
+	"\fdial_timeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vdialTimeout\x12<\n" +
+	"\fread_timeout\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vreadTimeout\x12>\n" +
+	"\rwrite_timeout\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\fwriteTimeout\"\xf1\x01\n" +
+	"\x04MQTT\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12O\n" +
+	"\x16max_reconnect_interval\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x14maxReconnectInterval\x12\x16\n" +
+	"\x06topics\x18\x05 \x03(\tR\x06topics\x123\n" +
+	"\apublish\x18\x06 \x01(\v2\x19.internal.conf.v1.PublishR\apublish\"9\n" +
+	"\aPublish\x12\x16\n" +
+	"\x06topic1\x18\x01 \x01(\tR\x06topic1\x12\x16\n" +
+	"\x06topic2\x18\x02 \x01(\tR\x06topic2\"?\n" +
+	"\bWebhooks\x123\n" +
+	"\awebhook\x18\x01 \x01(\v2\x19.internal.conf.v1.WebhookR\awebhook\"\xc4\x01\n" +
+	"\aWebhook\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x123\n" +
+	"\atimeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x128\n" +
+	"\x06routes\x18\x03 \x01(\v2 .internal.conf.v1.Webhook.RoutesR\x06routes\x1a8\n" +
+	"\x06Routes\x12\x16\n" +
+	"\x06route1\x18\x01 \x01(\tR\x06route1\x12\x16\n" +
+	"\x06route2\x18\x02 \x01(\tR\x06route2\"\xdf\r\n" +
+	"\x04Auth\x122\n" +
+	"\x05token\x18\x01 \x01(\v2\x1c.internal.conf.v1.Auth.TokenR\x05token\x12A\n" +
+	"\n" +
+	"revocation\x18\x02 \x01(\v2!.internal.conf.v1.Auth.RevocationR\n" +
+	"revocation\x125\n" +
+	"\x06tenant\x18\x03 \x01(\v2\x1d.internal.conf.v1.Auth.TenantR\x06tenant\x126\n" +
+	"\aapi_key\x18\x04 \x01(\v2\x1d.internal.conf.v1.Auth.ApiKeyR\x06apiKey\x12&\n" +
+	"\x0eauthenticators\x18\x05 \x03(\tR\x0eauthenticators\x12,\n" +
+	"\x03jwt\x18\x06 \x01(\v2\x1a.internal.conf.v1.Auth.JwtR\x03jwt\x122\n" +
+	"\x05authz\x18\a \x01(\v2\x1c.internal.conf.v1.Auth.AuthzR\x05authz\x1a\xdf\x01\n" +
+	"\x05Token\x121\n" +
+	"\x06leeway\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06leeway\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x1a\n" +
+	"\baudience\x18\x03 \x03(\tR\baudience\x12'\n" +
+	"\x0frequired_claims\x18\x04 \x03(\tR\x0erequiredClaims\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x122\n" +
+	"\amax_age\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x06maxAge\x1a\x9a\x02\n" +
+	"\n" +
+	"Revocation\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1d\n" +
+	"\n" +
+	"mqtt_topic\x18\x02 \x01(\tR\tmqttTopic\x12:\n" +
+	"\vdefault_ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"defaultTtl\x12>\n" +
+	"\rcleanup_every\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\fcleanupEvery\x128\n" +
+	"\n" +
+	"sync_every\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\tsyncEvery\x12\x1f\n" +
+	"\vadmin_roles\x18\x06 \x03(\tR\n" +
+	"adminRoles\x1aK\n" +
+	"\x06Tenant\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12)\n" +
+	"\x10privileged_roles\x18\x02 \x03(\tR\x0fprivilegedRoles\x1a\xbc\x01\n" +
+	"\x06ApiKey\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1f\n" +
+	"\vadmin_roles\x18\x02 \x03(\tR\n" +
+	"adminRoles\x126\n" +
+	"\tcache_ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bcacheTtl\x12A\n" +
+	"\x0flast_used_every\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\rlastUsedEvery\x1a\xcb\x02\n" +
+	"\x03Jwt\x12\x12\n" +
+	"\x04jwks\x18\x01 \x01(\tR\x04jwks\x12>\n" +
+	"\rrefresh_every\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\frefreshEvery\x12\x16\n" +
+	"\x06issuer\x18\x03 \x01(\tR\x06issuer\x12\x1a\n" +
+	"\baudience\x18\x04 \x03(\tR\baudience\x121\n" +
+	"\x06leeway\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x06leeway\x12\x1e\n" +
+	"\n" +
+	"algorithms\x18\x06 \x03(\tR\n" +
+	"algorithms\x12#\n" +
+	"\rsubject_claim\x18\a \x01(\tR\fsubjectClaim\x12\x1f\n" +
+	"\vroles_claim\x18\b \x01(\tR\n" +
+	"rolesClaim\x12#\n" +
+	"\rcompany_claim\x18\t \x01(\tR\fcompanyClaim\x1a\x8d\x02\n" +
+	"\x05Authz\x12!\n" +
+	"\fdefault_deny\x18\x01 \x01(\bR\vdefaultDeny\x12\x1a\n" +
+	"\bvalidate\x18\x02 \x01(\bR\bvalidate\x12+\n" +
+	"\x11public_operations\x18\x03 \x03(\tR\x10publicOperations\x12V\n" +
+	"\x0erole_hierarchy\x18\x04 \x03(\v2/.internal.conf.v1.Auth.Authz.RoleHierarchyEntryR\rroleHierarchy\x1a@\n" +
+	"\x12RoleHierarchyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb9\x13\n" +
+	"\aTraffic\x124\n" +
+	"\x04http\x18\x01 \x01(\v2 .internal.conf.v1.Traffic.LimitsR\x04http\x124\n" +
+	"\x04grpc\x18\x02 \x01(\v2 .internal.conf.v1.Traffic.LimitsR\x04grpc\x128\n" +
+	"\x06shared\x18\x03 \x01(\v2 .internal.conf.v1.Traffic.SharedR\x06shared\x128\n" +
+	"\x06quotas\x18\x04 \x01(\v2 .internal.conf.v1.Traffic.QuotasR\x06quotas\x1a\xe8\x05\n" +
+	"\x06Limits\x12&\n" +
+	"\finflight_max\x18\x01 \x01(\x05H\x00R\vinflightMax\x88\x01\x01\x12\x1e\n" +
+	"\brate_rps\x18\x02 \x01(\x01H\x01R\arateRps\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"rate_burst\x18\x03 \x01(\x05R\trateBurst\x12\x15\n" +
+	"\x06key_by\x18\x04 \x01(\tR\x05keyBy\x12/\n" +
+	"\x03cpu\x18\x05 \x01(\v2\x1d.internal.conf.v1.Traffic.CpuR\x03cpu\x127\n" +
+	"\x06routes\x18\x06 \x03(\v2\x1f.internal.conf.v1.Traffic.RouteR\x06routes\x12\x19\n" +
+	"\bmax_keys\x18\a \x01(\x05R\amaxKeys\x12;\n" +
+	"\fkey_idle_ttl\x18\b \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"keyIdleTtl\x12\x16\n" +
+	"\x06shadow\x18\t \x03(\tR\x06shadow\x12 \n" +
+	"\tqueue_max\x18\n" +
+	" \x01(\x05H\x02R\bqueueMax\x88\x01\x01\x12>\n" +
+	"\rqueue_timeout\x18\v \x01(\v2\x19.google.protobuf.DurationR\fqueueTimeout\x12\x1f\n" +
+	"\vqueue_order\x18\f \x01(\tR\n" +
+	"queueOrder\x12 \n" +
+	"\vconcurrency\x18\r \x01(\tR\vconcurrency\x12>\n" +
+	"\badaptive\x18\x0e \x01(\v2\".internal.conf.v1.Traffic.AdaptiveR\badaptive\x12-\n" +
+	"\x12criticality_header\x18\x0f \x01(\tR\x11criticalityHeader\x120\n" +
+	"\x11critical_headroom\x18\x10 \x01(\x05H\x03R\x10criticalHeadroom\x88\x01\x01B\x0f\n" +
+	"\r_inflight_maxB\v\n" +
+	"\t_rate_rpsB\f\n" +
+	"\n" +
+	"_queue_maxB\x14\n" +
+	"\x12_critical_headroom\x1a\xd1\x01\n" +
+	"\x03Cpu\x12\x1a\n" +
+	"\bdisabled\x18\x01 \x01(\bR\bdisabled\x121\n" +
+	"\x06window\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06window\x12\x18\n" +
+	"\abuckets\x18\x03 \x01(\x05R\abuckets\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\x03R\tthreshold\x12\x14\n" +
+	"\x05quota\x18\x05 \x01(\x01R\x05quota\x12-\n" +
+	"\x12critical_threshold\x18\x06 \x01(\x03R\x11criticalThreshold\x1a\xf2\x02\n" +
+	"\x05Route\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x1f\n" +
+	"\vpath_prefix\x18\x02 \x01(\tR\n" +
+	"pathPrefix\x12\x19\n" +
+	"\brate_rps\x18\x03 \x01(\x01R\arateRps\x12\x1d\n" +
+	"\n" +
+	"rate_burst\x18\x04 \x01(\x05R\trateBurst\x12\x15\n" +
+	"\x06key_by\x18\x05 \x01(\tR\x05keyBy\x12!\n" +
+	"\finflight_max\x18\x06 \x01(\x05R\vinflightMax\x12\x16\n" +
+	"\x06shadow\x18\a \x01(\bR\x06shadow\x12\x1b\n" +
+	"\tqueue_max\x18\b \x01(\x05R\bqueueMax\x12>\n" +
+	"\rqueue_timeout\x18\t \x01(\v2\x19.google.protobuf.DurationR\fqueueTimeout\x12\x1f\n" +
+	"\vqueue_order\x18\n" +
+	" \x01(\tR\n" +
+	"queueOrder\x12 \n" +
+	"\vcriticality\x18\v \x01(\tR\vcriticality\x1a\xa2\x01\n" +
+	"\x06Shared\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x123\n" +
+	"\abackoff\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\abackoff\x1a\xd4\x04\n" +
+	"\x06Quotas\x12\x1f\n" +
+	"\vservice_url\x18\x01 \x01(\tR\n" +
+	"serviceUrl\x123\n" +
+	"\arefresh\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\arefresh\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12!\n" +
+	"\fburst_factor\x18\x04 \x01(\x01R\vburstFactor\x12!\n" +
+	"\fprefix_match\x18\x05 \x01(\bR\vprefixMatch\x12\x1a\n" +
+	"\bsnapshot\x18\x06 \x01(\tR\bsnapshot\x126\n" +
+	"\tretry_min\x18\a \x01(\v2\x19.google.protobuf.DurationR\bretryMin\x126\n" +
+	"\tretry_max\x18\b \x01(\v2\x19.google.protobuf.DurationR\bretryMax\x126\n" +
+	"\tmax_stale\x18\t \x01(\v2\x19.google.protobuf.DurationR\bmaxStale\x12\x19\n" +
+	"\bmax_keys\x18\n" +
+	" \x01(\x05R\amaxKeys\x12;\n" +
+	"\fkey_idle_ttl\x18\v \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"keyIdleTtl\x12\x1d\n" +
+	"\n" +
+	"mqtt_topic\x18\f \x01(\tR\tmqttTopic\x12\x1d\n" +
+	"\n" +
+	"events_url\x18\r \x01(\tR\teventsUrl\x12\x1f\n" +
+	"\vadmin_roles\x18\x0e \x03(\tR\n" +
+	"adminRoles\x1a\x9d\x01\n" +
+	"\bAdaptive\x12\x1b\n" +
+	"\tmin_limit\x18\x01 \x01(\x05R\bminLimit\x12#\n" +
+	"\rinitial_limit\x18\x02 \x01(\x05R\finitialLimit\x12\x1c\n" +
+	"\ttolerance\x18\x03 \x01(\x01R\ttolerance\x121\n" +
+	"\x06window\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x06windowB\x1fZ\x1dservice/internal/conf/v1;confb\x06proto3"
 
 var (
 	file_internal_conf_v1_conf_proto_rawDescOnce sync.Once
@@ -814,7 +2413,7 @@ func file_internal_conf_v1_conf_proto_rawDescGZIP() []byte {
 	return file_internal_conf_v1_conf_proto_rawDescData
 }
 
-var file_internal_conf_v1_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_internal_conf_v1_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_internal_conf_v1_conf_proto_goTypes = []any{
 	(*Bootstrap)(nil),           // 0: internal.conf.v1.Bootstrap
 	(*App)(nil),                 // 1: internal.conf.v1.App
@@ -824,33 +2423,91 @@ var file_internal_conf_v1_conf_proto_goTypes = []any{
 	(*Publish)(nil),             // 5: internal.conf.v1.Publish
 	(*Webhooks)(nil),            // 6: internal.conf.v1.Webhooks
 	(*Webhook)(nil),             // 7: internal.conf.v1.Webhook
-	(*Server_HTTP)(nil),         // 8: internal.conf.v1.Server.HTTP
-	(*Server_GRPC)(nil),         // 9: internal.conf.v1.Server.GRPC
-	(*Data_Database)(nil),       // 10: internal.conf.v1.Data.Database
-	(*Webhook_Routes)(nil),      // 11: internal.conf.v1.Webhook.Routes
-	(*durationpb.Duration)(nil), // 12: google.protobuf.Duration
+	(*Auth)(nil),                // 8: internal.conf.v1.Auth
+	(*Traffic)(nil),             // 9: internal.conf.v1.Traffic
+	(*Server_HTTP)(nil),         // 10: internal.conf.v1.Server.HTTP
+	(*Server_GRPC)(nil),         // 11: internal.conf.v1.Server.GRPC
+	(*Data_Database)(nil),       // 12: internal.conf.v1.Data.Database
+	(*Data_Redis)(nil),          // 13: internal.conf.v1.Data.Redis
+	(*Webhook_Routes)(nil),      // 14: internal.conf.v1.Webhook.Routes
+	(*Auth_Token)(nil),          // 15: internal.conf.v1.Auth.Token
+	(*Auth_Revocation)(nil),     // 16: internal.conf.v1.Auth.Revocation
+	(*Auth_Tenant)(nil),         // 17: internal.conf.v1.Auth.Tenant
+	(*Auth_ApiKey)(nil),         // 18: internal.conf.v1.Auth.ApiKey
+	(*Auth_Jwt)(nil),            // 19: internal.conf.v1.Auth.Jwt
+	(*Auth_Authz)(nil),          // 20: internal.conf.v1.Auth.Authz
+	nil,                         // 21: internal.conf.v1.Auth.Authz.RoleHierarchyEntry
+	(*Traffic_Limits)(nil),      // 22: internal.conf.v1.Traffic.Limits
+	(*Traffic_Cpu)(nil),         // 23: internal.conf.v1.Traffic.Cpu
+	(*Traffic_Route)(nil),       // 24: internal.conf.v1.Traffic.Route
+	(*Traffic_Shared)(nil),      // 25: internal.conf.v1.Traffic.Shared
+	(*Traffic_Quotas)(nil),      // 26: internal.conf.v1.Traffic.Quotas
+	(*Traffic_Adaptive)(nil),    // 27: internal.conf.v1.Traffic.Adaptive
+	(*durationpb.Duration)(nil), // 28: google.protobuf.Duration
 }
 var file_internal_conf_v1_conf_proto_depIdxs = []int32{
 	2,  // 0: internal.conf.v1.Bootstrap.server:type_name -> internal.conf.v1.Server
 	3,  // 1: internal.conf.v1.Bootstrap.data:type_name -> internal.conf.v1.Data
 	1,  // 2: internal.conf.v1.Bootstrap.app:type_name -> internal.conf.v1.App
 	6,  // 3: internal.conf.v1.Bootstrap.webhooks:type_name -> internal.conf.v1.Webhooks
-	8,  // 4: internal.conf.v1.Server.http:type_name -> internal.conf.v1.Server.HTTP
-	9,  // 5: internal.conf.v1.Server.grpc:type_name -> internal.conf.v1.Server.GRPC
-	10, // 6: internal.conf.v1.Data.database:type_name -> internal.conf.v1.Data.Database
-	4,  // 7: internal.conf.v1.Data.mqtt:type_name -> internal.conf.v1.MQTT
-	12, // 8: internal.conf.v1.MQTT.max_reconnect_interval:type_name -> google.protobuf.Duration
-	5,  // 9: internal.conf.v1.MQTT.publish:type_name -> internal.conf.v1.Publish
-	7,  // 10: internal.conf.v1.Webhooks.webhook:type_name -> internal.conf.v1.Webhook
-	12, // 11: internal.conf.v1.Webhook.timeout:type_name -> google.protobuf.Duration
-	11, // 12: internal.conf.v1.Webhook.routes:type_name -> internal.conf.v1.Webhook.Routes
-	12, // 13: internal.conf.v1.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	12, // 14: internal.conf.v1.Server.GRPC.timeout:type_name -> google.protobuf.Duration
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	8,  // 4: internal.conf.v1.Bootstrap.auth:type_name -> internal.conf.v1.Auth
+	9,  // 5: internal.conf.v1.Bootstrap.traffic:type_name -> internal.conf.v1.Traffic
+	10, // 6: internal.conf.v1.Server.http:type_name -> internal.conf.v1.Server.HTTP
+	11, // 7: internal.conf.v1.Server.grpc:type_name -> internal.conf.v1.Server.GRPC
+	12, // 8: internal.conf.v1.Data.database:type_name -> internal.conf.v1.Data.Database
+	4,  // 9: internal.conf.v1.Data.mqtt:type_name -> internal.conf.v1.MQTT
+	13, // 10: internal.conf.v1.Data.redis:type_name -> internal.conf.v1.Data.Redis
+	28, // 11: internal.conf.v1.MQTT.max_reconnect_interval:type_name -> google.protobuf.Duration
+	5,  // 12: internal.conf.v1.MQTT.publish:type_name -> internal.conf.v1.Publish
+	7,  // 13: internal.conf.v1.Webhooks.webhook:type_name -> internal.conf.v1.Webhook
+	28, // 14: internal.conf.v1.Webhook.timeout:type_name -> google.protobuf.Duration
+	14, // 15: internal.conf.v1.Webhook.routes:type_name -> internal.conf.v1.Webhook.Routes
+	15, // 16: internal.conf.v1.Auth.token:type_name -> internal.conf.v1.Auth.Token
+	16, // 17: internal.conf.v1.Auth.revocation:type_name -> internal.conf.v1.Auth.Revocation
+	17, // 18: internal.conf.v1.Auth.tenant:type_name -> internal.conf.v1.Auth.Tenant
+	18, // 19: internal.conf.v1.Auth.api_key:type_name -> internal.conf.v1.Auth.ApiKey
+	19, // 20: internal.conf.v1.Auth.jwt:type_name -> internal.conf.v1.Auth.Jwt
+	20, // 21: internal.conf.v1.Auth.authz:type_name -> internal.conf.v1.Auth.Authz
+	22, // 22: internal.conf.v1.Traffic.http:type_name -> internal.conf.v1.Traffic.Limits
+	22, // 23: internal.conf.v1.Traffic.grpc:type_name -> internal.conf.v1.Traffic.Limits
+	25, // 24: internal.conf.v1.Traffic.shared:type_name -> internal.conf.v1.Traffic.Shared
+	26, // 25: internal.conf.v1.Traffic.quotas:type_name -> internal.conf.v1.Traffic.Quotas
+	28, // 26: internal.conf.v1.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	28, // 27: internal.conf.v1.Server.GRPC.timeout:type_name -> google.protobuf.Duration
+	28, // 28: internal.conf.v1.Data.Redis.dial_timeout:type_name -> google.protobuf.Duration
+	28, // 29: internal.conf.v1.Data.Redis.read_timeout:type_name -> google.protobuf.Duration
+	28, // 30: internal.conf.v1.Data.Redis.write_timeout:type_name -> google.protobuf.Duration
+	28, // 31: internal.conf.v1.Auth.Token.leeway:type_name -> google.protobuf.Duration
+	28, // 32: internal.conf.v1.Auth.Token.max_age:type_name -> google.protobuf.Duration
+	28, // 33: internal.conf.v1.Auth.Revocation.default_ttl:type_name -> google.protobuf.Duration
+	28, // 34: internal.conf.v1.Auth.Revocation.cleanup_every:type_name -> google.protobuf.Duration
+	28, // 35: internal.conf.v1.Auth.Revocation.sync_every:type_name -> google.protobuf.Duration
+	28, // 36: internal.conf.v1.Auth.ApiKey.cache_ttl:type_name -> google.protobuf.Duration
+	28, // 37: internal.conf.v1.Auth.ApiKey.last_used_every:type_name -> google.protobuf.Duration
+	28, // 38: internal.conf.v1.Auth.Jwt.refresh_every:type_name -> google.protobuf.Duration
+	28, // 39: internal.conf.v1.Auth.Jwt.leeway:type_name -> google.protobuf.Duration
+	21, // 40: internal.conf.v1.Auth.Authz.role_hierarchy:type_name -> internal.conf.v1.Auth.Authz.RoleHierarchyEntry
+	23, // 41: internal.conf.v1.Traffic.Limits.cpu:type_name -> internal.conf.v1.Traffic.Cpu
+	24, // 42: internal.conf.v1.Traffic.Limits.routes:type_name -> internal.conf.v1.Traffic.Route
+	28, // 43: internal.conf.v1.Traffic.Limits.key_idle_ttl:type_name -> google.protobuf.Duration
+	28, // 44: internal.conf.v1.Traffic.Limits.queue_timeout:type_name -> google.protobuf.Duration
+	27, // 45: internal.conf.v1.Traffic.Limits.adaptive:type_name -> internal.conf.v1.Traffic.Adaptive
+	28, // 46: internal.conf.v1.Traffic.Cpu.window:type_name -> google.protobuf.Duration
+	28, // 47: internal.conf.v1.Traffic.Route.queue_timeout:type_name -> google.protobuf.Duration
+	28, // 48: internal.conf.v1.Traffic.Shared.timeout:type_name -> google.protobuf.Duration
+	28, // 49: internal.conf.v1.Traffic.Shared.backoff:type_name -> google.protobuf.Duration
+	28, // 50: internal.conf.v1.Traffic.Quotas.refresh:type_name -> google.protobuf.Duration
+	28, // 51: internal.conf.v1.Traffic.Quotas.timeout:type_name -> google.protobuf.Duration
+	28, // 52: internal.conf.v1.Traffic.Quotas.retry_min:type_name -> google.protobuf.Duration
+	28, // 53: internal.conf.v1.Traffic.Quotas.retry_max:type_name -> google.protobuf.Duration
+	28, // 54: internal.conf.v1.Traffic.Quotas.max_stale:type_name -> google.protobuf.Duration
+	28, // 55: internal.conf.v1.Traffic.Quotas.key_idle_ttl:type_name -> google.protobuf.Duration
+	28, // 56: internal.conf.v1.Traffic.Adaptive.window:type_name -> google.protobuf.Duration
+	57, // [57:57] is the sub-list for method output_type
+	57, // [57:57] is the sub-list for method input_type
+	57, // [57:57] is the sub-list for extension type_name
+	57, // [57:57] is the sub-list for extension extendee
+	0,  // [0:57] is the sub-list for field type_name
 }
 
 func init() { file_internal_conf_v1_conf_proto_init() }
@@ -858,13 +2515,14 @@ func file_internal_conf_v1_conf_proto_init() {
 	if File_internal_conf_v1_conf_proto != nil {
 		return
 	}
+	file_internal_conf_v1_conf_proto_msgTypes[22].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_conf_v1_conf_proto_rawDesc), len(file_internal_conf_v1_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
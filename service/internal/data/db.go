@@ -6,6 +6,7 @@ import (
 	"service/internal/data/adapters" // common registry
 	_ "service/internal/data/adapters/mysql"
 	_ "service/internal/data/adapters/postgres"
+	"service/internal/data/tenant"
 	"service/pkg/utils"
 	"time"
 
@@ -22,7 +23,7 @@ func NewData(config *conf.Data, logger log.Logger) (*Data, func(), error) {
 
 	if !config.Database.Active {
 		h.Infof("[DATABASE] [SKIPPED] Database is disabled")
-		return nil, nil, nil
+		return nil, func() {}, nil
 	}
 	// 1) Select adapter by DB_DRIVER / ENV (LoadConfig sets it)
 	drv := utils.EnvFirst("DB_DRIVER")
@@ -65,9 +66,15 @@ func NewData(config *conf.Data, logger log.Logger) (*Data, func(), error) {
 		return nil, nil, err
 	}
 
-	// 5) Migrations/seeds
+	// Tenant scope for models implementing tenant.Owned
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, nil, err
+	}
+
+	// 5) Migrations/seeds (every company: tenant.System)
+	sys := db.WithContext(tenant.System(context.Background()))
 	if config.Database.Migrations {
-		if err := adapter.RunMigrations(db, logger); err != nil {
+		if err := adapter.RunMigrations(sys, logger); err != nil {
 			return nil, nil, err
 		}
 	} else {
@@ -75,7 +82,7 @@ func NewData(config *conf.Data, logger log.Logger) (*Data, func(), error) {
 	}
 
 	if config.Database.Seed {
-		if err := adapter.RunSeeds(db, logger); err != nil {
+		if err := adapter.RunSeeds(sys, logger); err != nil {
 			return nil, nil, err
 		}
 	} else {
@@ -12,12 +12,13 @@ type contextTxKey struct{}
 // txKey is the single instance of the transaction key
 var txKey = contextTxKey{}
 
-// DB returns the transaction from the context if it exists, otherwise returns the normal connection to the DB
+// DB returns the transaction from the context if it exists, otherwise returns the normal connection to the DB.
+// The context is bound to the session, so tenant-owned models are scoped to the request's company.
 func (d *Data) DB(ctx context.Context) *gorm.DB {
 	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
-		return tx
+		return tx.WithContext(ctx)
 	}
-	return d.db
+	return d.db.WithContext(ctx)
 }
 
 // WithTx wraps the function in a transaction if it doesn't exist in the context
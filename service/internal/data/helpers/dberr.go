@@ -5,6 +5,8 @@ import (
 	"errors"
 	"net/http"
 
+	"service/internal/data/tenant"
+
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
@@ -24,6 +26,11 @@ func HTTPStatusFromDBErr(err error) int {
 		return http.StatusRequestTimeout // 408
 	}
 
+	// Tenant isolation
+	if errors.Is(err, tenant.ErrCrossTenant) || errors.Is(err, tenant.ErrNoTenant) || errors.Is(err, tenant.ErrUnscoped) {
+		return http.StatusForbidden // 403
+	}
+
 	// GORM: record not found
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return http.StatusNotFound // 404
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// APIKeys represents the API keys of machine clients (only the hash is stored)
+type APIKeys struct {
+	Base
+	Prefix     string     `gorm:"column:prefix;type:varchar(16);not null;unique"` // public part of the key, used for lookup
+	Hash       string     `gorm:"column:hash;type:varchar(64);not null"`          // sha256 (hex) of the full key
+	Name       string     `gorm:"column:name;type:varchar(255);not null"`         // e.g. "nightly-cron", "partner-x"
+	Roles      string     `gorm:"column:roles;type:varchar(1024)"`                // CSV, same format as token "roles"
+	CompanyID  uint       `gorm:"column:company_id;not null;default:0"`           // 0 = no company
+	CreatedBy  string     `gorm:"column:created_by;type:varchar(255)"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;type:DATETIME"` // nil = never expires
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:DATETIME"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:DATETIME"`
+	Others
+}
+
+// TableName returns the name of the table for the APIKeys model
+func (APIKeys) TableName() string {
+	return "api_keys"
+}
@@ -10,3 +10,14 @@ type Base struct {
 type Others struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:DATETIME;not null;autoCreateTime;default:CURRENT_TIMESTAMP"`
 }
+
+// Tenant marks a model as owned by a company: rows are scoped by the
+// "company_id" claim of the request (see data/tenant).
+type Tenant struct {
+	CompanyID uint `gorm:"column:company_id;not null;index"`
+}
+
+// TenantColumn implements tenant.Owned
+func (Tenant) TenantColumn() string {
+	return "company_id"
+}
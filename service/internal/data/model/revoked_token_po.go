@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RevokedTokens represents the denylist of revoked access tokens
+type RevokedTokens struct {
+	Base
+	JTI       string    `gorm:"column:jti;type:varchar(128);index"`      // token id (if the token has one)
+	Username  string    `gorm:"column:username;type:varchar(255);index"` // user of the token(s)
+	Iat       int64     `gorm:"column:iat;not null;default:0"`           // issued-at of the token (0 = every token of the user)
+	Reason    string    `gorm:"column:reason;type:varchar(255)"`
+	RevokedAt time.Time `gorm:"column:revoked_at;type:DATETIME;not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:DATETIME;not null;index"` // entry can be removed after this
+	Others
+}
+
+// TableName returns the name of the table for the RevokedTokens model
+func (RevokedTokens) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"context"
+	"service/internal/conf/v1"
+	"service/pkg/utils"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis connects to data.redis; nil when disabled.
+// An unreachable server does not fail boot: users fall back to local state
+// and the client keeps reconnecting.
+func NewRedis(config *conf.Data, logger log.Logger) (*redis.Client, func(), error) {
+	h := log.NewHelper(logger)
+
+	rc := config.GetRedis()
+	if !rc.GetActive() {
+		h.Infof("[REDIS] [SKIPPED] Redis is disabled")
+		return nil, func() {}, nil
+	}
+
+	opts := &redis.Options{
+		Addr:     rc.GetAddr(),
+		DB:       int(rc.GetDb()),
+		Password: utils.EnvFirst("REDIS_PASSWORD"),
+	}
+	if d := rc.GetDialTimeout(); d != nil {
+		opts.DialTimeout = d.AsDuration()
+	}
+	if d := rc.GetReadTimeout(); d != nil {
+		opts.ReadTimeout = d.AsDuration()
+	}
+	if d := rc.GetWriteTimeout(); d != nil {
+		opts.WriteTimeout = d.AsDuration()
+	}
+	rdb := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		h.Warnf("[REDIS] %s unreachable (will retry): %v", rc.GetAddr(), err)
+	} else {
+		h.Infof("Connected to redis: %s", rc.GetAddr())
+	}
+
+	cleanup := func() { _ = rdb.Close() }
+	return rdb, cleanup, nil
+}
@@ -0,0 +1,190 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Plugin registers the tenant scope on every GORM operation.
+type Plugin struct{}
+
+func (Plugin) Name() string { return "tenant" }
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", scopeWhere); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:row", scopeWhere); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", scopeUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", scopeWhere); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant:create", scopeCreate)
+}
+
+// scoped returns the tenant column and tenant when the statement must be
+// scoped. Owned models without a Tenant in the context are rejected.
+func scoped(db *gorm.DB) (*schema.Field, Tenant, bool) {
+	stmt := db.Statement
+	if !Active() || stmt.Schema == nil {
+		return nil, Tenant{}, false
+	}
+	owned, ok := reflect.New(stmt.Schema.ModelType).Interface().(Owned)
+	if !ok {
+		return nil, Tenant{}, false
+	}
+	t, ok := FromContext(stmt.Context)
+	if !ok {
+		db.AddError(ErrUnscoped)
+		return nil, Tenant{}, false
+	}
+	if t.Privileged {
+		return nil, Tenant{}, false
+	}
+	field := stmt.Schema.LookUpField(owned.TenantColumn())
+	if field == nil {
+		db.AddError(ErrCrossTenant)
+		return nil, Tenant{}, false
+	}
+	if t.CompanyID == 0 {
+		db.AddError(ErrNoTenant)
+		return nil, Tenant{}, false
+	}
+	return field, t, true
+}
+
+// scopeWhere limits reads/deletes to the tenant's rows.
+func scopeWhere(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field, t, ok := scoped(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: t.CompanyID},
+	}})
+}
+
+// scopeUpdate limits updates to the tenant's rows and rejects moving them to another company.
+func scopeUpdate(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field, t, ok := scoped(db)
+	if !ok {
+		return
+	}
+
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		if v, exists := dest[field.DBName]; exists && !sameCompany(v, t.CompanyID) {
+			db.AddError(ErrCrossTenant)
+			return
+		}
+		if v, exists := dest[field.Name]; exists && !sameCompany(v, t.CompanyID) {
+			db.AddError(ErrCrossTenant)
+			return
+		}
+	default:
+		if !checkRows(db, field, t, true) || !checkDest(db, field, t) {
+			return
+		}
+	}
+
+	scopeWhere(db)
+}
+
+// scopeCreate fills the tenant column and rejects rows of another company.
+func scopeCreate(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field, t, ok := scoped(db)
+	if !ok {
+		return
+	}
+	checkRows(db, field, t, true)
+}
+
+// checkRows walks struct destination rows; zero tenant values are filled when fill is set.
+func checkRows(db *gorm.DB, field *schema.Field, t Tenant, fill bool) bool {
+	stmt := db.Statement
+	rv := stmt.ReflectValue
+	check := func(row reflect.Value) bool {
+		v, zero := field.ValueOf(stmt.Context, row)
+		if zero {
+			if fill {
+				if err := field.Set(stmt.Context, row, t.CompanyID); err != nil {
+					db.AddError(err)
+					return false
+				}
+			}
+			return true
+		}
+		if !sameCompany(v, t.CompanyID) {
+			db.AddError(ErrCrossTenant)
+			return false
+		}
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			row := reflect.Indirect(rv.Index(i))
+			if row.Kind() == reflect.Struct && !check(row) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		return check(rv)
+	}
+	return true
+}
+
+// checkDest rejects a struct update destination carrying another company,
+// e.g. Model(&T{}).Updates(&T{CompanyID: x}) (zero values are not written).
+func checkDest(db *gorm.DB, field *schema.Field, t Tenant) bool {
+	stmt := db.Statement
+	dv := reflect.Indirect(reflect.ValueOf(stmt.Dest))
+	if dv.Kind() != reflect.Struct {
+		return true
+	}
+	var v interface{}
+	var zero bool
+	if dv.Type() == stmt.Schema.ModelType {
+		v, zero = field.ValueOf(stmt.Context, dv)
+	} else {
+		fv := dv.FieldByName(field.Name)
+		if !fv.IsValid() {
+			return true
+		}
+		v, zero = fv.Interface(), fv.IsZero()
+	}
+	if !zero && !sameCompany(v, t.CompanyID) {
+		db.AddError(ErrCrossTenant)
+		return false
+	}
+	return true
+}
+
+func sameCompany(v interface{}, id uint) bool {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() >= 0 && uint64(rv.Int()) == uint64(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() == uint64(id)
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service/internal/conf/v1"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type invoice struct {
+	ID        uint
+	CompanyID uint
+	Total     int
+}
+
+func (invoice) TenantColumn() string { return "company_id" }
+
+type country struct {
+	ID   uint
+	Name string
+}
+
+func dryRun(t *testing.T, active bool) *gorm.DB {
+	t.Helper()
+	Init(&conf.Auth{Tenant: &conf.Auth_Tenant{Active: active}})
+	t.Cleanup(func() { Init(nil) })
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestScopeQuery(t *testing.T) {
+	db := dryRun(t, true)
+	company := NewContext(context.Background(), Tenant{CompanyID: 7})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		model   interface{}
+		err     error
+		company bool // WHERE company_id = 7 added
+	}{
+		{"tenant", company, &[]invoice{}, nil, true},
+		{"no tenant", context.Background(), &[]invoice{}, ErrUnscoped, false},
+		{"tenant without company", NewContext(context.Background(), Tenant{}), &[]invoice{}, ErrNoTenant, false},
+		{"privileged", NewContext(context.Background(), Tenant{Privileged: true}), &[]invoice{}, nil, false},
+		{"system", System(context.Background()), &[]invoice{}, nil, false},
+		{"not owned without tenant", context.Background(), &[]country{}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := db.WithContext(tt.ctx).Find(tt.model).Statement
+			if !errors.Is(stmt.Error, tt.err) {
+				t.Fatalf("error = %v, want %v", stmt.Error, tt.err)
+			}
+			_, scoped := stmt.Clauses["WHERE"]
+			if scoped != tt.company {
+				t.Errorf("scoped = %v, want %v (%s %v)", scoped, tt.company, stmt.SQL.String(), stmt.Vars)
+			}
+			if tt.company && (len(stmt.Vars) != 1 || stmt.Vars[0] != uint(7)) {
+				t.Errorf("vars = %v, want [7]", stmt.Vars)
+			}
+		})
+	}
+}
+
+func TestScopeInactive(t *testing.T) {
+	db := dryRun(t, false)
+	if err := db.WithContext(context.Background()).Find(&[]invoice{}).Error; err != nil {
+		t.Fatalf("inactive isolation must not scope: %v", err)
+	}
+}
+
+func TestScopeCreate(t *testing.T) {
+	db := dryRun(t, true)
+	ctx := NewContext(context.Background(), Tenant{CompanyID: 7})
+
+	row := invoice{Total: 10}
+	if err := db.WithContext(ctx).Create(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	if row.CompanyID != 7 {
+		t.Errorf("company_id = %d, want filled with 7", row.CompanyID)
+	}
+
+	other := invoice{CompanyID: 8}
+	if err := db.WithContext(ctx).Create(&other).Error; !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("create for another company: error = %v, want %v", err, ErrCrossTenant)
+	}
+	if err := db.WithContext(context.Background()).Create(&invoice{CompanyID: 8}).Error; !errors.Is(err, ErrUnscoped) {
+		t.Errorf("create without tenant: error = %v, want %v", err, ErrUnscoped)
+	}
+}
+
+func TestScopeUpdate(t *testing.T) {
+	db := dryRun(t, true)
+	ctx := NewContext(context.Background(), Tenant{CompanyID: 7})
+
+	err := db.WithContext(ctx).Model(&invoice{}).Where("id = ?", 1).
+		Updates(map[string]interface{}{"company_id": 8}).Error
+	if !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("moving a row to another company: error = %v, want %v", err, ErrCrossTenant)
+	}
+
+	type invoicePatch struct {
+		CompanyID uint
+		Total     int
+	}
+	tests := []struct {
+		name string
+		dest interface{}
+		want error
+	}{
+		{"struct dest of another company", &invoice{CompanyID: 8, Total: 10}, ErrCrossTenant},
+		{"partial struct dest of another company", &invoicePatch{CompanyID: 8}, ErrCrossTenant},
+		{"struct dest of the same company", &invoice{CompanyID: 7, Total: 10}, nil},
+		{"struct dest without company", &invoice{Total: 10}, nil},
+		{"partial struct dest without company", invoicePatch{Total: 10}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.WithContext(ctx).Model(&invoice{}).Where("id = ?", 1).Updates(tt.dest).Error
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Updates(%T) error = %v, want %v", tt.dest, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,28 +1,20 @@
 package example
 
 import (
+	api_example "service/api/example/v1"
 	example_service "service/internal/feature/example/v1/service"
 	"service/internal/server/middleware/auth/authz/endpoint"
 )
 
-const (
-	RoleExample = "TEST1"
-)
-
-// Endpoints with required roles (versioned)
-func GetExamplev1Endpoints(svc *example_service.ExampleService) endpoint.ServiceGroup {
-	return endpoint.ServiceGroup{
-		Name: "examplev1",
-		Methods: []endpoint.ServiceMethod{
-			// Examples (uncomment and replace with real service methods):
-			// endpoint.NewServiceMethod(svc, svc.ListExamples),
-			// endpoint.NewServiceMethod(svc, svc.UpsertExcel, RoleExample),
-			// other methods...
-		},
-	}
+// Endpoints with required roles (versioned).
+// Roles are declared per RPC in api/example/v1/example.proto:
+//
+//	option (auth.v1.rule) = { roles: ["TEST1"] };
+func GetExamplev1Endpoints(_ *example_service.ExampleService) endpoint.ServiceGroup {
+	return endpoint.NewServiceGroup("examplev1", api_example.Examplev1Service_ServiceDesc.ServiceName)
 }
 
 // Backward-compatible alias (without version): calls versioned function
 func GetServiceEndpoints(svc *example_service.ExampleService) endpoint.ServiceGroup {
 	return GetExamplev1Endpoints(svc)
-}
\ No newline at end of file
+}
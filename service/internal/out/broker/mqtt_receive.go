@@ -8,6 +8,18 @@ import (
 
 // processMessage processes the message received from the MQTT broker
 func (b *Broker) processMessage(client mqtt.Client, message mqtt.Message) {
+	// token revocations from other replicas
+	if t := b.revocation.Topic(); t != "" && message.Topic() == t {
+		b.revocation.HandleMessage(message.Payload())
+		return
+	}
+
+	// individual quota changes (full table or refresh hint)
+	if t := b.quotas.Topic(); t != "" && message.Topic() == t {
+		b.quotas.HandleMessage(message.Payload())
+		return
+	}
+
 	// MOCK
 	mymqtt.MockMQTT_ProcessMessage(message.Topic(), string(message.Payload()))
 	// TODO: Implement the logic to process the message
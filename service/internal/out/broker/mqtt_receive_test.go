@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/auth/authn"
+	"service/internal/server/middleware/auth/auth/revocation"
+	iq "service/internal/server/middleware/traffic/individual_quotas"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+func (m message) Duplicate() bool   { return false }
+func (m message) Qos() byte         { return 1 }
+func (m message) Retained() bool    { return false }
+func (m message) Topic() string     { return m.topic }
+func (m message) MessageID() uint16 { return 1 }
+func (m message) Payload() []byte   { return m.payload }
+func (m message) Ack()              {}
+
+func TestProcessMessage(t *testing.T) {
+	qc := &conf.Traffic_Quotas{ServiceUrl: "http://127.0.0.1:1", MqttTopic: "quotas/changes"}
+	hub, cleanupHub, err := iq.NewHub(&conf.Traffic{Quotas: qc}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cleanupHub)
+	quotas := iq.New("p", iq.HTTP, qc, log.DefaultLogger)
+	hub.Attach(quotas)
+
+	rev, cleanupRev, err := revocation.NewRevocation(&conf.Auth{Revocation: &conf.Auth_Revocation{
+		Active:    true,
+		MqttTopic: "auth/revocations",
+	}}, nil, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cleanupRev)
+
+	b := NewBroker(rev, hub, log.DefaultLogger)
+
+	table := `{"project":"p","items":[{"project":"p","route":"/v1/a","quota":1}]}`
+	b.processMessage(nil, message{topic: "quotas/changes", payload: []byte(table)})
+	if quotas.QuotasLen() != 1 {
+		t.Fatalf("quota routes = %d, want 1 (pushed table applied)", quotas.QuotasLen())
+	}
+
+	now := time.Now().Unix()
+	entry, _ := json.Marshal(revocation.Entry{JTI: "j1", RevokedAt: now, ExpiresAt: now + 60})
+	b.processMessage(nil, message{topic: "auth/revocations", payload: entry})
+	if !rev.Revoked(&authn.Principal{ID: "j1"}) {
+		t.Fatal("revocation from another replica not applied")
+	}
+
+	// A revocation is not a quota table and vice versa.
+	b.processMessage(nil, message{topic: "quotas/changes", payload: entry})
+	b.processMessage(nil, message{topic: "auth/revocations", payload: []byte(table)})
+	if quotas.QuotasLen() != 1 || rev.Len() != 1 {
+		t.Fatalf("routes = %d, revocations = %d after cross-topic payloads; want 1, 1", quotas.QuotasLen(), rev.Len())
+	}
+}
@@ -2,6 +2,8 @@ package broker
 
 import (
 	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/auth/revocation"
+	iq "service/internal/server/middleware/traffic/individual_quotas"
 
 	mymqtt "service/pkg/mqtt"
 	"service/pkg/utils"
@@ -10,13 +12,17 @@ import (
 )
 
 type Broker struct {
-	log *log.Helper
+	revocation *revocation.Revocation // nil if token revocation is disabled
+	quotas     *iq.Hub                // individual quota change events
+	log        *log.Helper
 }
 
 // NewBroker creates a new Broker instance with the given Usecase and logger
-func NewBroker(logger log.Logger) *Broker {
+func NewBroker(rev *revocation.Revocation, quotas *iq.Hub, logger log.Logger) *Broker {
 	return &Broker{
-		log: log.NewHelper(logger),
+		revocation: rev,
+		quotas:     quotas,
+		log:        log.NewHelper(logger),
 	}
 }
 
@@ -35,6 +41,11 @@ func (b *Broker) Start(data *conf.Data) {
 	clientid := data.Mqtt.ClientId
 	maxReconnectInterval := data.Mqtt.MaxReconnectInterval
 	topics := data.Mqtt.Topics
+	for _, t := range []string{b.revocation.Topic(), b.quotas.Topic()} {
+		if t != "" {
+			topics = append(append([]string{}, topics...), t)
+		}
+	}
 
 	username := utils.EnvFirst("MQTT_USERNAME")
 	password := utils.EnvFirst("MQTT_PASSWORD")
@@ -4,8 +4,11 @@ package server_grpc
 import (
 	"context"
 	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/authz"
+	"service/internal/server/middleware/auth/authz/endpoint"
 	"service/internal/server/middleware/traffic"
 	iq "service/internal/server/middleware/traffic/individual_quotas"
+	"service/internal/server/utils/ip"
 	"service/internal/server/utils/requestlog"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -16,25 +19,44 @@ import (
 // GRPCRegistrar is a function that registers routes on the server.
 type GRPCRegister func(*grpc.Server)
 
-func NewGRPCServer(c *conf.Server, app *conf.App, regs []GRPCRegister /* authGroups []endpoint.ServiceGroup, */, log log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, app *conf.App, regs []GRPCRegister, authGroups []endpoint.ServiceGroup, auth *conf.Auth, tc *conf.Traffic, shared *traffic.Shared, quotas *iq.Hub, log log.Logger) (*grpc.Server, error) {
+	// role checks from (auth.v1.rule) options of the auth groups
+	authMiddleware, err := authz.ProviderSet(authGroups, auth)
+	if err != nil {
+		return nil, err
+	}
+	authStream, err := authz.StreamProviderSet(authGroups, auth)
+	if err != nil {
+		return nil, err
+	}
 
 	// individual quotas middleware
-	iqMgr := iq.New(app.GetName(), iq.GRPC, log)
+	iqMgr := iq.New(app.GetName(), iq.GRPC, tc.GetQuotas(), log)
+	iqMgr.UseShared(shared)
+	quotas.Attach(iqMgr) // push updates + /qt
 	iqMgr.Start(context.Background())
 
-	// global middleware for gRPC
-	rateLimitMiddleware := traffic.New(traffic.GRPCConfig(log))
-	// rateLimitMiddleware := traffic.New(traffic.GRPCConfigTest(log))
+	// global middleware for gRPC (traffic.grpc, defaults for missing values)
+	trafficCfg := traffic.FromConf(tc.GetGrpc(), traffic.GRPCConfig(log), log)
+	trafficCfg.Shared = shared // nil = per-replica buckets
+	iqMgr.UseShadow(trafficCfg.Shadowed(traffic.ReasonIQ))
+	rateLimitMiddleware := traffic.New(trafficCfg)
 
 	opts := []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
+			server_utils_ip.Server(),   // client IP resolved once (server.trusted_proxies)
 			rateLimitMiddleware.GRPC(), // add traffic middleware for rate limiting
 			iqMgr.GRPC(),               // add traffic middleware for rate limiting
+			authMiddleware,             // add auth middleware for roles
 		),
 		// Add logging for unary and stream requests
 		grpc.UnaryInterceptor(requestlog.UnaryLogInterceptor()),
-		grpc.StreamInterceptor(requestlog.StreamLogInterceptor()),
+		grpc.StreamInterceptor(
+			server_utils_ip.StreamInterceptor(),
+			requestlog.StreamLogInterceptor(),
+			authStream, // auth for streaming calls
+		),
 	}
 	if c.Grpc.Network != "" {
 		opts = append(opts, grpc.Network(c.Grpc.Network))
@@ -53,5 +75,16 @@ func NewGRPCServer(c *conf.Server, app *conf.App, regs []GRPCRegister /* authGro
 		r(srv)
 	}
 
-	return srv
+	return srv, nil
+}
+
+// Operations returns every served operation ("/pkg.Service/Method").
+func Operations(srv *grpc.Server) []string {
+	var ops []string
+	for name, info := range srv.GetServiceInfo() {
+		for _, m := range info.Methods {
+			ops = append(ops, "/"+name+"/"+m.Name)
+		}
+	}
+	return ops
 }
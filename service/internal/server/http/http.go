@@ -8,10 +8,13 @@ import (
 	"service/internal/server/http/middleware/multipart"
 	"service/internal/server/http/openapi/swagger"
 	"service/internal/server/http/sys"
+	"service/internal/server/middleware/auth/auth/apikey"
+	"service/internal/server/middleware/auth/auth/revocation"
 	"service/internal/server/middleware/auth/authz"
 	"service/internal/server/middleware/auth/authz/endpoint"
 	"service/internal/server/middleware/traffic"
 	iq "service/internal/server/middleware/traffic/individual_quotas"
+	"service/internal/server/utils/ip"
 	"service/internal/server/utils/requestlog"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -22,25 +25,37 @@ import (
 // HTTPRegistrar is a function that registers routes on the server.
 type HTTPRegister func(*http.Server)
 
-func NewHTTPServer(c *conf.Server, app *conf.App, regs []HTTPRegister, authGroups []endpoint.ServiceGroup, log log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, app *conf.App, regs []HTTPRegister, authGroups []endpoint.ServiceGroup, auth *conf.Auth, tc *conf.Traffic, shared *traffic.Shared, quotas *iq.Hub, rev *revocation.Revocation, keys *apikey.APIKeys, log log.Logger) (*http.Server, error) {
+	// role checks from (auth.v1.rule) options of the auth groups
+	authMiddleware, err := authz.ProviderSet(authGroups, auth)
+	if err != nil {
+		return nil, err
+	}
 
 	// individual quotas middleware
-	iqMgr := iq.New(app.GetName(), iq.HTTP, log)
+	iqMgr := iq.New(app.GetName(), iq.HTTP, tc.GetQuotas(), log)
+	iqMgr.UseShared(shared)
+	quotas.Attach(iqMgr) // push updates + /qt
 	iqMgr.Start(context.Background())
 
-	// global middleware for HTTP
-	rateLimitMiddleware := traffic.New(traffic.HTTPConfig(log))
-	// rateLimitMiddleware := traffic.New(traffic.HTTPConfigTest(log))
+	// global middleware for HTTP (traffic.http, defaults for missing values)
+	trafficCfg := traffic.FromConf(tc.GetHttp(), traffic.HTTPConfig(log), log)
+	trafficCfg.Shared = shared // nil = per-replica buckets
+	iqMgr.UseShadow(trafficCfg.Shadowed(traffic.ReasonIQ))
+	rateLimitMiddleware := traffic.New(trafficCfg)
 
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
-			rateLimitMiddleware.HTTP(),    // add traffic middleware for rate limiting
-			iqMgr.HTTP(),                  // add traffic middleware for rate limiting
-			authz.ProviderSet(authGroups), // add auth middleware for roles
-			multipart.Middleware(32<<20),  // 32MB max memory for file uploads
+			rateLimitMiddleware.HTTP(),   // add traffic middleware for rate limiting
+			iqMgr.HTTP(),                 // add traffic middleware for rate limiting
+			authMiddleware,               // add auth middleware for roles
+			multipart.Middleware(32<<20), // 32MB max memory for file uploads
+		),
+		http.Filter(
+			server_utils_ip.HTTPFilter(), // client IP resolved once (server.trusted_proxies)
+			requestlog.HTTPLogMiddleware(),
 		),
-		http.Filter(requestlog.HTTPLogMiddleware()),
 	}
 	if c.Http.Network != "" {
 		opts = append(opts, http.Network(c.Http.Network))
@@ -80,7 +95,23 @@ func NewHTTPServer(c *conf.Server, app *conf.App, regs []HTTPRegister, authGroup
 	})
 
 	sys.LoadSystemEndpoints(srv)
-	sys.LoadQuotasRefreshEndpoint(srv, iqMgr)
+	sys.LoadQuotasEndpoints(srv, quotas)
+	sys.LoadRevocationEndpoints(srv, rev)
+	sys.LoadAPIKeyEndpoints(srv, keys)
 
-	return srv
+	return srv, nil
+}
+
+// Operations returns the operations ("/pkg.Service/Method") of the proto routes
+// served; plain handlers (docs, /health, /auth/...) have no operation.
+func Operations(srv *http.Server) []string {
+	bindings := endpoint.HTTPBindings()
+	var ops []string
+	_ = srv.WalkRoute(func(r http.RouteInfo) error {
+		if op, ok := bindings[endpoint.HTTPRouteKey(r.Method, r.Path)]; ok {
+			ops = append(ops, op)
+		}
+		return nil
+	})
+	return ops
 }
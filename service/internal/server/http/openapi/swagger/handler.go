@@ -5,6 +5,7 @@ import (
 	stdhttp "net/http"
 
 	openapifs "service/docs"
+	"service/internal/server/middleware/auth/authz/endpoint"
 
 	kratoshttp "github.com/go-kratos/kratos/v2/transport/http"
 )
@@ -94,17 +95,20 @@ func AttachEmbeddedSwaggerUIWithConfig(s *kratoshttp.Server, cfg Config) {
 		stdhttp.Redirect(w, r, p(r, "/docs/login"), stdhttp.StatusSeeOther)
 	})
 
-	// openapi.yaml
+	// openapi.yaml (with the x-auth-rule of each operation)
+	spec, specErr := fs.ReadFile(cfg.DocsFS, "openapi.yaml")
+	if specErr == nil {
+		spec = withRules(spec, endpoint.OpenAPIRules())
+	}
 	reg(s, cfg.Base, "/docs/openapi.yaml", authRequired(&cfg, func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
-		data, err := fs.ReadFile(cfg.DocsFS, "openapi.yaml")
-		if err != nil {
+		if specErr != nil {
 			httpNotFound(w)
 			return
 		}
 		setOnlyContentType(w, "application/yaml")
 		setNoCache(w)
 		w.WriteHeader(stdhttp.StatusOK)
-		_, _ = w.Write(data)
+		_, _ = w.Write(spec)
 	}))
 
 	// static <base>/docs/openapi/*
@@ -0,0 +1,94 @@
+package swagger
+
+import (
+	"bytes"
+
+	"service/internal/server/middleware/auth/authz/endpoint"
+	"service/pkg/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleExtension is the operation extension carrying its (auth.v1.rule).
+const ruleExtension = "x-auth-rule"
+
+// withRules adds x-auth-rule to every operation of an OpenAPI document whose
+// operationId has a rule. protoc-gen-openapi does not know the option, so the
+// rule is added when serving, from the same descriptors the middleware reads.
+// On a document it cannot parse it returns data unchanged.
+func withRules(data []byte, rules map[string]endpoint.Rule) []byte {
+	if len(rules) == 0 {
+		return data
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		logger.Warn("openapi.yaml: cannot add auth rules")
+		return data
+	}
+	paths := mapValue(doc.Content[0], "paths")
+	if paths == nil {
+		return data
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		ops := paths.Content[i]
+		for j := 1; j < len(ops.Content); j += 2 {
+			op := ops.Content[j]
+			id := mapValue(op, "operationId")
+			if id == nil || mapValue(op, ruleExtension) != nil {
+				continue
+			}
+			if rule, ok := rules[id.Value]; ok {
+				op.Content = append(op.Content, scalar(ruleExtension), ruleNode(rule))
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(&doc); err != nil {
+		logger.Warn("openapi.yaml: cannot add auth rules")
+		return data
+	}
+	_ = enc.Close()
+	return buf.Bytes()
+}
+
+// ruleNode renders a rule like the option: public, roles, all_of, none_of.
+func ruleNode(r endpoint.Rule) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	if r.Public {
+		n.Content = append(n.Content, scalar("public"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		return n
+	}
+	for _, f := range []struct {
+		key   string
+		roles []string
+	}{{"roles", r.Require.AnyOf}, {"all_of", r.Require.AllOf}, {"none_of", r.Require.NoneOf}} {
+		if len(f.roles) == 0 {
+			continue
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, role := range f.roles {
+			list.Content = append(list.Content, scalar(role))
+		}
+		n.Content = append(n.Content, scalar(f.key), list)
+	}
+	return n
+}
+
+func mapValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
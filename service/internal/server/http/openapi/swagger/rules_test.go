@@ -0,0 +1,70 @@
+package swagger
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	_ "service/api/example/v1"
+	openapifs "service/docs"
+	"service/internal/server/middleware/auth/authz/endpoint"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWithRules(t *testing.T) {
+	data, err := fs.ReadFile(openapifs.FS, "openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := endpoint.OpenAPIRules()
+	if r, ok := rules["Examplev1Service_Mock"]; !ok || !r.Public {
+		t.Fatalf("rule of Examplev1Service_Mock = %+v, %v; want the public rule from example.proto", r, ok)
+	}
+
+	tests := []struct {
+		name string
+		rule endpoint.Rule
+		want map[string]any
+	}{
+		{"public", endpoint.Rule{Public: true}, map[string]any{"public": true}},
+		{"roles", endpoint.Rule{Require: endpoint.Requirement{AnyOf: []string{"ADMIN"}, NoneOf: []string{"SUSPENDED"}}},
+			map[string]any{"roles": []any{"ADMIN"}, "none_of": []any{"SUSPENDED"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := withRules(data, map[string]endpoint.Rule{"Examplev1Service_Mock": tt.rule})
+			var doc struct {
+				Paths map[string]map[string]map[string]any `yaml:"paths"`
+			}
+			if err := yaml.Unmarshal(out, &doc); err != nil {
+				t.Fatal(err)
+			}
+			got := doc.Paths["/v1/example/mock"]["get"][ruleExtension]
+			if !equalYAML(t, got, tt.want) {
+				t.Errorf("%s = %v, want %v", ruleExtension, got, tt.want)
+			}
+			if !strings.HasPrefix(string(out), "# Generated with protoc-gen-openapi") {
+				t.Errorf("header comment lost:\n%s", out[:80])
+			}
+		})
+	}
+
+	if out := withRules(data, map[string]endpoint.Rule{"Other_Op": {Public: true}}); strings.Contains(string(out), ruleExtension) {
+		t.Errorf("operation without a rule got %s", ruleExtension)
+	}
+}
+
+func equalYAML(t *testing.T, a, b any) bool {
+	t.Helper()
+	x, err := yaml.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := yaml.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(x) == string(y)
+}
@@ -0,0 +1,101 @@
+package sys
+
+import (
+	"encoding/json"
+	"errors"
+	stdhttp "net/http"
+	"strconv"
+	"time"
+
+	"service/internal/server/middleware/auth/auth/apikey"
+	"service/internal/server/middleware/auth/authz/endpoint"
+	"service/pkg/logger"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"gorm.io/gorm"
+)
+
+type createKeyRequest struct {
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles"`
+	CompanyID uint     `json:"company_id"`
+	ExpiresAt int64    `json:"expires_at"` // unix seconds; 0 => never expires
+}
+
+// LoadAPIKeyEndpoints registers /auth/keys (requires admin roles; not
+// registered without auth.api_key.admin_roles):
+//   - GET             list keys
+//   - POST            create a key (the secret is returned only once)
+//   - DELETE ?id=123  revoke a key
+func LoadAPIKeyEndpoints(srv *khttp.Server, keys *apikey.APIKeys) {
+	if keys == nil {
+		return
+	}
+	if !endpoint.HasRoles(keys.AdminRoles()) {
+		logger.Warn("[APIKEY] /auth/keys disabled: no admin_roles configured")
+		return
+	}
+
+	srv.HandleFunc("/auth/keys", endpoint.RequireRoles(keys.AdminRoles(), func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		switch r.Method {
+		case stdhttp.MethodGet:
+			list, err := keys.List(r.Context())
+			if err != nil {
+				writeJSON(w, stdhttp.StatusInternalServerError, map[string]any{"ok": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, stdhttp.StatusOK, map[string]any{"ok": true, "items": list, "total": len(list)})
+
+		case stdhttp.MethodPost:
+			var req createKeyRequest
+			if err := json.NewDecoder(stdhttp.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+				writeJSON(w, stdhttp.StatusBadRequest, map[string]any{"ok": false, "error": "invalid json body"})
+				return
+			}
+			p := apikey.CreateParams{
+				Name:      req.Name,
+				Roles:     req.Roles,
+				CompanyID: req.CompanyID,
+			}
+			if req.ExpiresAt > 0 {
+				t := time.Unix(req.ExpiresAt, 0)
+				p.ExpiresAt = &t
+			}
+			if c := endpoint.PrincipalFromContext(r.Context()); c != nil {
+				p.CreatedBy = c.Subject
+			}
+
+			raw, key, err := keys.Create(r.Context(), p)
+			if errors.Is(err, apikey.ErrEmptyName) {
+				writeJSON(w, stdhttp.StatusBadRequest, map[string]any{"ok": false, "error": err.Error()})
+				return
+			}
+			if err != nil {
+				writeJSON(w, stdhttp.StatusInternalServerError, map[string]any{"ok": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, stdhttp.StatusCreated, map[string]any{"ok": true, "key": raw, "item": key})
+
+		case stdhttp.MethodDelete:
+			id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 32)
+			if err != nil || id == 0 {
+				writeJSON(w, stdhttp.StatusBadRequest, map[string]any{"ok": false, "error": "id is required"})
+				return
+			}
+			key, err := keys.Revoke(r.Context(), uint(id))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				writeJSON(w, stdhttp.StatusNotFound, map[string]any{"ok": false, "error": "api key not found"})
+				return
+			}
+			if err != nil {
+				writeJSON(w, stdhttp.StatusInternalServerError, map[string]any{"ok": false, "error": err.Error()})
+				return
+			}
+			writeJSON(w, stdhttp.StatusOK, map[string]any{"ok": true, "item": key})
+
+		default:
+			w.WriteHeader(stdhttp.StatusMethodNotAllowed)
+			_, _ = w.Write([]byte("method not allowed"))
+		}
+	}))
+}
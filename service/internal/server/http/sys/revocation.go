@@ -0,0 +1,78 @@
+package sys
+
+import (
+	"encoding/json"
+	"errors"
+	stdhttp "net/http"
+	"time"
+
+	"service/internal/server/middleware/auth/auth/revocation"
+	"service/internal/server/middleware/auth/authz/endpoint"
+	"service/pkg/logger"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type revokeRequest struct {
+	JTI      string `json:"jti"`
+	Username string `json:"username"`
+	Iat      int64  `json:"iat"`
+	Exp      int64  `json:"exp"` // unix seconds; 0 => token max_age (default_ttl for username cut-offs)
+	Reason   string `json:"reason"`
+}
+
+// LoadRevocationEndpoints registers POST /auth/revoke (requires admin roles;
+// not registered without auth.revocation.admin_roles).
+func LoadRevocationEndpoints(srv *khttp.Server, rev *revocation.Revocation) {
+	if rev == nil {
+		return
+	}
+	if !endpoint.HasRoles(rev.AdminRoles()) {
+		logger.Warn("[REVOCATION] /auth/revoke disabled: no admin_roles configured")
+		return
+	}
+
+	srv.HandleFunc("/auth/revoke", endpoint.RequireRoles(rev.AdminRoles(), func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.Method != stdhttp.MethodPost {
+			w.WriteHeader(stdhttp.StatusMethodNotAllowed)
+			_, _ = w.Write([]byte("method not allowed"))
+			return
+		}
+
+		var req revokeRequest
+		if err := json.NewDecoder(stdhttp.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+			writeJSON(w, stdhttp.StatusBadRequest, map[string]any{"ok": false, "error": "invalid json body"})
+			return
+		}
+
+		e, err := rev.Revoke(r.Context(), revocation.Entry{
+			JTI:       req.JTI,
+			Username:  req.Username,
+			Iat:       req.Iat,
+			Reason:    req.Reason,
+			ExpiresAt: req.Exp,
+		})
+		if errors.Is(err, revocation.ErrEmptyEntry) || errors.Is(err, revocation.ErrMissingExpiry) ||
+			errors.Is(err, revocation.ErrInvalidExpiry) || errors.Is(err, revocation.ErrInvalidRevoked) {
+			writeJSON(w, stdhttp.StatusBadRequest, map[string]any{"ok": false, "error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, stdhttp.StatusInternalServerError, map[string]any{"ok": false, "error": err.Error()})
+			return
+		}
+
+		writeJSON(w, stdhttp.StatusOK, map[string]any{
+			"ok":         true,
+			"revoked":    e,
+			"expires_at": time.Unix(e.ExpiresAt, 0).UTC().Format(time.RFC3339),
+			"entries":    rev.Len(),
+		})
+	}))
+}
+
+func writeJSON(w stdhttp.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
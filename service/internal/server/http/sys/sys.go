@@ -6,7 +6,9 @@ import (
 	stdhttp "net/http"
 	"time"
 
+	"service/internal/server/middleware/auth/authz/endpoint"
 	iqpkg "service/internal/server/middleware/traffic/individual_quotas"
+	"service/pkg/logger"
 
 	khttp "github.com/go-kratos/kratos/v2/transport/http"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,29 +32,48 @@ func LoadSystemEndpoints(srv *khttp.Server) {
 
 }
 
-func LoadQuotasRefreshEndpoint(srv *khttp.Server, iq *iqpkg.IQ) {
-	if iq == nil {
+// LoadQuotasEndpoints registers the individual quotas admin API (requires
+// admin roles; not registered without traffic.quotas.admin_roles): GET /qt
+// returns the active tables and limiter state, POST /qt refreshes them from
+// the quota service.
+func LoadQuotasEndpoints(srv *khttp.Server, hub *iqpkg.Hub) {
+	if hub == nil {
+		return
+	}
+	if !endpoint.HasRoles(hub.AdminRoles()) {
+		logger.Warn("[IQ] /qt disabled: no admin_roles configured")
 		return
 	}
 
-	srv.HandleFunc("/qt", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			_, _ = w.Write([]byte("method not allowed"))
-			return
-		}
+	srv.HandleFunc("/qt", endpoint.RequireRoles(hub.AdminRoles(), func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]any{"transports": hub.State()})
 
-		start := time.Now()
-		iq.RefreshOnce(r.Context())
-		elapsed := time.Since(start)
+		case http.MethodPost:
+			start := time.Now()
+			results := hub.Refresh(r.Context())
+			elapsed := time.Since(start)
 
-		resp := map[string]any{
-			"ok":            true,
-			"refreshed_at":  time.Now().UTC().Format(time.RFC3339),
-			"routes_loaded": iq.QuotasLen(),
-			"took_ms":       elapsed.Milliseconds(),
+			ok := true
+			for _, res := range results {
+				ok = ok && res.OK
+			}
+			status := http.StatusOK
+			if !ok {
+				// la tabla anterior sigue activa (last known good)
+				status = http.StatusBadGateway
+			}
+			writeJSON(w, status, map[string]any{
+				"ok":           ok,
+				"refreshed_at": time.Now().UTC().Format(time.RFC3339),
+				"took_ms":      elapsed.Milliseconds(),
+				"transports":   results,
+			})
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_, _ = w.Write([]byte("method not allowed"))
 		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	})
+	}))
 }
@@ -0,0 +1,178 @@
+package sys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/auth/authn"
+	iqpkg "service/internal/server/middleware/traffic/individual_quotas"
+
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// bearerRoles authenticates "Bearer <name>" as a principal with the roles of name.
+type bearerRoles map[string][]string
+
+func (bearerRoles) Name() string { return "test" }
+
+func (b bearerRoles) Authenticate(_ context.Context, cred authn.Credentials) (*authn.Principal, error) {
+	if cred.Bearer == "" {
+		return nil, authn.ErrNotApplicable
+	}
+	roles, ok := b[cred.Bearer]
+	if !ok {
+		return nil, errors.New("unknown token")
+	}
+	return &authn.Principal{Method: "test", Subject: cred.Bearer, Roles: roles}, nil
+}
+
+func useTestAuth(t *testing.T) {
+	t.Helper()
+	authn.Init(&conf.Auth{Authenticators: []string{"test"}})
+	authn.Register("test", bearerRoles{"admin": {"ADMIN"}, "viewer": {"VIEWER"}})
+	t.Cleanup(func() {
+		authn.Register("test", nil)
+		authn.Init(nil)
+	})
+}
+
+// quotaService serves GET /qt with items, or fails while failing is set.
+func quotaService(t *testing.T, failing *atomic.Bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"project":"p","route":"/v1/a","quota":5},{"project":"p","route":"/v1/b","quota":1,"key_by":"ip"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newQuotasServer(t *testing.T, serviceURL string, adminRoles []string) *httptest.Server {
+	t.Helper()
+	qc := &conf.Traffic_Quotas{ServiceUrl: serviceURL, AdminRoles: adminRoles}
+	hub, cleanup, err := iqpkg.NewHub(&conf.Traffic{Quotas: qc}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cleanup)
+	hub.Attach(iqpkg.New("p", iqpkg.HTTP, qc, log.DefaultLogger))
+
+	srv := khttp.NewServer()
+	LoadQuotasEndpoints(srv, hub)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func call(t *testing.T, method, url, token string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]any
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+	}
+	return resp.StatusCode, body
+}
+
+func TestQuotasEndpointsAuth(t *testing.T) {
+	useTestAuth(t)
+	var failing atomic.Bool
+	ts := newQuotasServer(t, quotaService(t, &failing).URL, []string{"ADMIN"})
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, "", http.StatusUnauthorized},
+		{"invalid token", http.MethodGet, "forged", http.StatusUnauthorized},
+		{"missing role", http.MethodGet, "viewer", http.StatusForbidden},
+		{"missing role refresh", http.MethodPost, "viewer", http.StatusForbidden},
+		{"admin", http.MethodGet, "admin", http.StatusOK},
+		{"admin refresh", http.MethodPost, "admin", http.StatusOK},
+		{"admin other method", http.MethodDelete, "admin", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := call(t, tt.method, ts.URL+"/qt", tt.token); got != tt.want {
+				t.Fatalf("%s /qt = %d, want %d", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotasEndpointsDisabledWithoutAdminRoles(t *testing.T) {
+	useTestAuth(t)
+	var failing atomic.Bool
+	ts := newQuotasServer(t, quotaService(t, &failing).URL, []string{" "})
+
+	if got, _ := call(t, http.MethodGet, ts.URL+"/qt", "admin"); got != http.StatusNotFound {
+		t.Fatalf("GET /qt = %d, want %d (endpoint not registered)", got, http.StatusNotFound)
+	}
+}
+
+func TestQuotasEndpointsRefresh(t *testing.T) {
+	useTestAuth(t)
+	var failing atomic.Bool
+	ts := newQuotasServer(t, quotaService(t, &failing).URL, []string{"ADMIN"})
+
+	status, body := call(t, http.MethodPost, ts.URL+"/qt", "admin")
+	if status != http.StatusOK || body["ok"] != true {
+		t.Fatalf("POST /qt = %d %v, want 200 ok", status, body)
+	}
+	results := body["transports"].([]any)
+	if len(results) != 1 || results[0].(map[string]any)["routes_loaded"] != float64(2) {
+		t.Fatalf("transports = %v, want one transport with 2 routes", results)
+	}
+
+	// The quota service fails: 502, the last known good table stays active.
+	failing.Store(true)
+	status, body = call(t, http.MethodPost, ts.URL+"/qt", "admin")
+	if status != http.StatusBadGateway || body["ok"] != false {
+		t.Fatalf("POST /qt = %d %v, want 502 not ok", status, body)
+	}
+	res := body["transports"].([]any)[0].(map[string]any)
+	if msg, _ := res["error"].(string); res["routes_loaded"] != float64(2) || msg == "" {
+		t.Fatalf("transport = %v, want 2 routes kept and an error", res)
+	}
+
+	status, body = call(t, http.MethodGet, ts.URL+"/qt", "admin")
+	if status != http.StatusOK {
+		t.Fatalf("GET /qt = %d, want 200", status)
+	}
+	state := body["transports"].([]any)[0].(map[string]any)
+	routes := state["routes"].([]any)
+	if state["transport"] != "http" || state["enabled"] != true || len(routes) != 2 {
+		t.Fatalf("state = %v, want http enabled with 2 routes", state)
+	}
+	if a := routes[0].(map[string]any); a["route"] != "/v1/a" || a["quota"] == nil {
+		t.Fatalf("route = %v, want /v1/a with a route quota", a)
+	}
+	if b := routes[1].(map[string]any); b["route"] != "/v1/b" || len(b["clients"].([]any)) != 1 {
+		t.Fatalf("route = %v, want /v1/b with one client quota", b)
+	}
+}
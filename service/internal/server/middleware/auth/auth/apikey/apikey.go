@@ -0,0 +1,404 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service/internal/conf/v1"
+	"service/internal/data"
+	"service/internal/data/model"
+	"service/internal/server/middleware/auth/auth/authn"
+	"service/internal/server/middleware/auth/authz/endpoint"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+/*
+   API keys for machine clients (cron jobs, partner systems).
+
+   - Key format: "sk_<prefix>_<secret>"; only sha256(key) is stored, the
+     prefix is the lookup column.
+   - A verified key becomes an authn.Principal (Method "apikey"), so roles,
+     tenant and PrincipalFromContext work exactly as with tokens.
+   - Verified keys are cached for cache_ttl; revoking on this instance drops
+     the cache entry immediately, other replicas within cache_ttl.
+   - Unknown prefixes are remembered for a few seconds, so guessed "sk_" keys
+     do not reach the database on every request.
+   - The principal subject is "apikey:<id>": key names are not unique and
+     must not collide with usernames (rate-limit buckets, revocation).
+   - "last_used_at" is written at most once per last_used_every per key.
+*/
+
+const (
+	Name = "apikey" // name in auth.authenticators
+
+	keyPrefix           = "sk_"
+	defaultCacheTTL     = 30 * time.Second
+	defaultLastUsedStep = time.Minute
+	missTTL             = 10 * time.Second
+	maxMisses           = 10000
+	subjectPrefix       = "apikey:"
+)
+
+var (
+	ErrMalformedKey = errors.New("malformed api key")
+	ErrUnknownKey   = errors.New("unknown api key")
+	ErrKeyExpired   = errors.New("api key expired")
+	ErrKeyRevoked   = errors.New("api key revoked")
+	ErrEmptyName    = errors.New("name is required")
+)
+
+// Key is the public view of a stored key (never includes the secret).
+type Key struct {
+	ID         uint       `json:"id"`
+	Prefix     string     `json:"prefix"`
+	Name       string     `json:"name"`
+	Roles      []string   `json:"roles"`
+	CompanyID  uint       `json:"company_id,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateParams describes a new key.
+type CreateParams struct {
+	Name      string
+	Roles     []string
+	CompanyID uint
+	ExpiresAt *time.Time // nil = never expires
+	CreatedBy string
+}
+
+type cached struct {
+	principal *authn.Principal
+	id        uint
+	hash      string
+	expires   *time.Time
+	cachedAt  time.Time
+}
+
+type APIKeys struct {
+	data *data.Data
+
+	cacheTTL     time.Duration
+	lastUsedStep time.Duration
+	adminRoles   []string
+
+	mu       sync.Mutex
+	cache    map[string]cached    // prefix -> verified key
+	misses   map[string]time.Time // prefix -> lookup without row
+	lastUsed map[string]time.Time // prefix -> last "last_used_at" write
+
+	lookup func(ctx context.Context, prefix string) (model.APIKeys, error)
+
+	log *log.Helper
+}
+
+// NewAPIKeys creates the API key store and plugs it into the authz chain.
+// Returns nil when API keys are not active or the database is disabled.
+func NewAPIKeys(c *conf.Auth, d *data.Data, logger log.Logger) (*APIKeys, func(), error) {
+	h := log.NewHelper(logger)
+	kc := c.GetApiKey()
+	if kc == nil || !kc.GetActive() {
+		h.Infof("[APIKEY] [SKIPPED] API key authentication is disabled")
+		return nil, func() {}, nil
+	}
+	if d == nil {
+		h.Warnf("[APIKEY] [SKIPPED] API key authentication requires the database")
+		return nil, func() {}, nil
+	}
+
+	k := &APIKeys{
+		data:         d,
+		cacheTTL:     durationOr(kc.GetCacheTtl().AsDuration(), defaultCacheTTL),
+		lastUsedStep: durationOr(kc.GetLastUsedEvery().AsDuration(), defaultLastUsedStep),
+		adminRoles:   kc.GetAdminRoles(),
+		cache:        make(map[string]cached),
+		misses:       make(map[string]time.Time),
+		lastUsed:     make(map[string]time.Time),
+		log:          h,
+	}
+	k.lookup = k.byPrefix
+
+	authn.Register(Name, k)
+	h.Infof("[APIKEY] API key authentication active")
+
+	return k, func() { authn.Register(Name, nil) }, nil
+}
+
+// AdminRoles returns roles allowed to manage keys.
+func (k *APIKeys) AdminRoles() []string { return k.adminRoles }
+
+// Create stores a new key and returns it in clear text (shown only once).
+func (k *APIKeys) Create(ctx context.Context, p CreateParams) (string, Key, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return "", Key{}, ErrEmptyName
+	}
+
+	prefix, err := randomString(6)
+	if err != nil {
+		return "", Key{}, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", Key{}, err
+	}
+	raw := keyPrefix + prefix + "_" + secret
+
+	po := model.APIKeys{
+		Prefix:    prefix,
+		Hash:      hashKey(raw),
+		Name:      p.Name,
+		Roles:     strings.Join(cleanRoles(p.Roles), ","),
+		CompanyID: p.CompanyID,
+		CreatedBy: p.CreatedBy,
+		ExpiresAt: p.ExpiresAt,
+	}
+	if err := k.data.DB(ctx).Create(&po).Error; err != nil {
+		return "", Key{}, err
+	}
+
+	k.mu.Lock()
+	delete(k.misses, prefix)
+	k.mu.Unlock()
+
+	k.log.Infof("[APIKEY] created: id=%d name=%q prefix=%s by=%q", po.ID, po.Name, po.Prefix, po.CreatedBy)
+	return raw, toKey(po), nil
+}
+
+// List returns every key (revoked and expired included).
+func (k *APIKeys) List(ctx context.Context) ([]Key, error) {
+	var rows []model.APIKeys
+	if err := k.data.DB(ctx).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]Key, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toKey(r))
+	}
+	return out, nil
+}
+
+// Revoke marks a key as revoked; it stops working immediately on this instance.
+func (k *APIKeys) Revoke(ctx context.Context, id uint) (Key, error) {
+	var po model.APIKeys
+	if err := k.data.DB(ctx).First(&po, id).Error; err != nil {
+		return Key{}, err
+	}
+	if po.RevokedAt == nil {
+		now := time.Now()
+		if err := k.data.DB(ctx).Model(&po).Update("revoked_at", now).Error; err != nil {
+			return Key{}, err
+		}
+		po.RevokedAt = &now
+	}
+
+	k.mu.Lock()
+	delete(k.cache, po.Prefix)
+	k.mu.Unlock()
+
+	k.log.Infof("[APIKEY] revoked: id=%d name=%q prefix=%s", po.ID, po.Name, po.Prefix)
+	return toKey(po), nil
+}
+
+func (k *APIKeys) Name() string { return Name }
+
+// Reason implements authn.Reasoner.
+func (k *APIKeys) Reason(error) string { return "INVALID_API_KEY" }
+
+// Authenticate implements authn.Authenticator (requests without API key are not handled).
+func (k *APIKeys) Authenticate(ctx context.Context, cred authn.Credentials) (*authn.Principal, error) {
+	raw := strings.TrimSpace(cred.APIKey)
+	if raw == "" {
+		return nil, authn.ErrNotApplicable
+	}
+	prefix, ok := splitKey(raw)
+	if !ok {
+		return nil, ErrMalformedKey
+	}
+	hash := hashKey(raw)
+	now := time.Now()
+
+	k.mu.Lock()
+	c, hit := k.cache[prefix]
+	missedAt, missed := k.misses[prefix]
+	k.mu.Unlock()
+
+	if !hit && missed && now.Sub(missedAt) < missTTL {
+		return nil, ErrUnknownKey
+	}
+	if !hit || now.Sub(c.cachedAt) > k.cacheTTL {
+		po, err := k.lookup(ctx, prefix)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			k.miss(prefix, now)
+			return nil, ErrUnknownKey
+		}
+		if err != nil {
+			return nil, err
+		}
+		if po.RevokedAt != nil {
+			return nil, ErrKeyRevoked
+		}
+		c = cached{principal: toPrincipal(po), id: po.ID, hash: po.Hash, expires: po.ExpiresAt, cachedAt: now}
+
+		k.mu.Lock()
+		k.cache[prefix] = c
+		k.mu.Unlock()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(c.hash), []byte(hash)) != 1 {
+		return nil, ErrUnknownKey
+	}
+	if c.expires != nil && now.After(*c.expires) {
+		return nil, ErrKeyExpired
+	}
+
+	k.touch(prefix, c.id, now)
+
+	p := *c.principal
+	return &p, nil
+}
+
+func (k *APIKeys) byPrefix(ctx context.Context, prefix string) (model.APIKeys, error) {
+	var po model.APIKeys
+	err := k.data.DB(ctx).Where("prefix = ?", prefix).First(&po).Error
+	return po, err
+}
+
+// miss remembers an unknown prefix for missTTL (bounded: random prefixes
+// must not grow the map without limit).
+func (k *APIKeys) miss(prefix string, now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.misses) >= maxMisses {
+		for p, at := range k.misses {
+			if now.Sub(at) >= missTTL {
+				delete(k.misses, p)
+			}
+		}
+		if len(k.misses) >= maxMisses {
+			k.misses = make(map[string]time.Time)
+		}
+	}
+	k.misses[prefix] = now
+}
+
+// touch updates "last_used_at" in the background, throttled per key.
+func (k *APIKeys) touch(prefix string, id uint, now time.Time) {
+	k.mu.Lock()
+	if now.Sub(k.lastUsed[prefix]) < k.lastUsedStep {
+		k.mu.Unlock()
+		return
+	}
+	k.lastUsed[prefix] = now
+	k.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := k.data.DB(ctx).Model(&model.APIKeys{}).Where("id = ?", id).
+			Update("last_used_at", now).Error; err != nil {
+			k.log.Warnf("[APIKEY] last_used_at update error: %v", err)
+		}
+	}()
+}
+
+// FromContext returns the principal of an API key caller (false for token callers).
+func FromContext(ctx context.Context) (*authn.Principal, bool) {
+	p := endpoint.PrincipalFromContext(ctx)
+	if p == nil || p.Method != Name {
+		return nil, false
+	}
+	return p, true
+}
+
+// ----- helpers -----
+
+// splitKey returns the prefix of "sk_<prefix>_<secret>".
+func splitKey(raw string) (string, bool) {
+	if !strings.HasPrefix(raw, keyPrefix) {
+		return "", false
+	}
+	rest := raw[len(keyPrefix):]
+	i := strings.IndexByte(rest, '_')
+	if i <= 0 || i == len(rest)-1 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns n random bytes as unpadded base64url without "_" (it separates key parts).
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(base64.RawURLEncoding.EncodeToString(b), "_", "-"), nil
+}
+
+func cleanRoles(roles []string) []string {
+	out := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r = strings.TrimSpace(r); r != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func toPrincipal(po model.APIKeys) *authn.Principal {
+	p := &authn.Principal{
+		Method:    Name,
+		Subject:   subjectPrefix + strconv.FormatUint(uint64(po.ID), 10),
+		Roles:     cleanRoles(strings.Split(po.Roles, ",")),
+		CompanyID: po.CompanyID,
+		ID:        keyPrefix + po.Prefix,
+		IssuedAt:  po.CreatedAt.Unix(),
+		Details:   toKey(po),
+	}
+	if po.ExpiresAt != nil {
+		p.ExpiresAt = po.ExpiresAt.Unix()
+	}
+	return p
+}
+
+func toKey(po model.APIKeys) Key {
+	roles := cleanRoles(strings.Split(po.Roles, ","))
+	return Key{
+		ID:         po.ID,
+		Prefix:     keyPrefix + po.Prefix,
+		Name:       po.Name,
+		Roles:      roles,
+		CompanyID:  po.CompanyID,
+		CreatedBy:  po.CreatedBy,
+		CreatedAt:  po.CreatedAt,
+		ExpiresAt:  po.ExpiresAt,
+		LastUsedAt: po.LastUsedAt,
+		RevokedAt:  po.RevokedAt,
+	}
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
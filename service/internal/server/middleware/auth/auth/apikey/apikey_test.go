@@ -0,0 +1,211 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"service/internal/data/model"
+	"service/internal/server/middleware/auth/auth/authn"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	testKey    = "sk_abc123_s3cr3t"
+	testPrefix = "abc123"
+)
+
+// newTestKeys returns a store backed by rows (prefix -> row) and the number
+// of lookups it made.
+func newTestKeys(rows map[string]model.APIKeys) (*APIKeys, *int) {
+	lookups := 0
+	k := &APIKeys{
+		cacheTTL:     time.Minute,
+		lastUsedStep: time.Minute,
+		cache:        make(map[string]cached),
+		misses:       make(map[string]time.Time),
+		lastUsed:     make(map[string]time.Time),
+		log:          log.NewHelper(log.DefaultLogger),
+	}
+	k.lookup = func(_ context.Context, prefix string) (model.APIKeys, error) {
+		lookups++
+		po, ok := rows[prefix]
+		if !ok {
+			return model.APIKeys{}, gorm.ErrRecordNotFound
+		}
+		// no database: "last_used_at" writes are skipped
+		k.lastUsed[prefix] = time.Now().Add(time.Hour)
+		return po, nil
+	}
+	return k, &lookups
+}
+
+func testRow() model.APIKeys {
+	po := model.APIKeys{
+		Prefix:    testPrefix,
+		Hash:      hashKey(testKey),
+		Name:      "alice",
+		Roles:     "READER, WRITER,",
+		CompanyID: 7,
+	}
+	po.ID = 42
+	po.CreatedAt = time.Unix(1700000000, 0)
+	return po
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := []struct {
+		raw    string
+		prefix string
+		ok     bool
+	}{
+		{testKey, testPrefix, true},
+		{"sk_abc_def_ghi", "abc", true},
+		{"abc123_s3cr3t", "", false},
+		{"sk_abc123", "", false},
+		{"sk__s3cr3t", "", false},
+		{"sk_abc123_", "", false},
+	}
+	for _, tt := range tests {
+		prefix, ok := splitKey(tt.raw)
+		if prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("splitKey(%q) = %q, %t; want %q, %t", tt.raw, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
+
+func TestToPrincipal(t *testing.T) {
+	p := toPrincipal(testRow())
+	if p.Subject != "apikey:42" {
+		t.Fatalf("Subject = %q, want apikey:42 (key names are not unique)", p.Subject)
+	}
+	if p.Method != Name || p.ID != "sk_abc123" || p.CompanyID != 7 || p.IssuedAt != 1700000000 {
+		t.Fatalf("principal = %+v", p)
+	}
+	if len(p.Roles) != 2 || p.Roles[0] != "READER" || p.Roles[1] != "WRITER" {
+		t.Fatalf("Roles = %q", p.Roles)
+	}
+	if key, ok := p.Details.(Key); !ok || key.Name != "alice" {
+		t.Fatalf("Details = %#v, want the key", p.Details)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	valid := testRow()
+	expired := testRow()
+	expired.ExpiresAt = &past
+	revoked := testRow()
+	revoked.RevokedAt = &past
+
+	tests := []struct {
+		name    string
+		row     *model.APIKeys
+		raw     string
+		wantErr error
+	}{
+		{"no key", nil, "", authn.ErrNotApplicable},
+		{"malformed", nil, "s3cr3t", ErrMalformedKey},
+		{"unknown prefix", nil, testKey, ErrUnknownKey},
+		{"wrong secret", &valid, "sk_abc123_other", ErrUnknownKey},
+		{"revoked", &revoked, testKey, ErrKeyRevoked},
+		{"expired", &expired, testKey, ErrKeyExpired},
+		{"valid", &valid, testKey, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := map[string]model.APIKeys{}
+			if tt.row != nil {
+				rows[testPrefix] = *tt.row
+			}
+			k, _ := newTestKeys(rows)
+			p, err := k.Authenticate(context.Background(), authn.Credentials{APIKey: tt.raw})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && p.Subject != "apikey:42" {
+				t.Fatalf("Subject = %q", p.Subject)
+			}
+		})
+	}
+}
+
+func TestAuthenticateCache(t *testing.T) {
+	k, lookups := newTestKeys(map[string]model.APIKeys{testPrefix: testRow()})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		p, err := k.Authenticate(ctx, authn.Credentials{APIKey: testKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Roles = nil // callers get a copy
+	}
+	if *lookups != 1 {
+		t.Fatalf("lookups = %d, want 1 (verified keys are cached)", *lookups)
+	}
+	if p, _ := k.Authenticate(ctx, authn.Credentials{APIKey: testKey}); len(p.Roles) != 2 {
+		t.Fatal("cached principal modified by a caller")
+	}
+
+	k.mu.Lock()
+	c := k.cache[testPrefix]
+	c.cachedAt = time.Now().Add(-2 * k.cacheTTL)
+	k.cache[testPrefix] = c
+	k.mu.Unlock()
+
+	if _, err := k.Authenticate(ctx, authn.Credentials{APIKey: testKey}); err != nil {
+		t.Fatal(err)
+	}
+	if *lookups != 2 {
+		t.Fatalf("lookups = %d, want 2 (stale cache entry reloaded)", *lookups)
+	}
+}
+
+func TestAuthenticateNegativeCache(t *testing.T) {
+	k, lookups := newTestKeys(nil)
+	ctx := context.Background()
+	bogus := authn.Credentials{APIKey: "sk_nope_guess"}
+
+	for i := 0; i < 5; i++ {
+		if _, err := k.Authenticate(ctx, bogus); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("Authenticate() error = %v, want %v", err, ErrUnknownKey)
+		}
+	}
+	if *lookups != 1 {
+		t.Fatalf("lookups = %d, want 1 (unknown prefixes are cached)", *lookups)
+	}
+
+	k.mu.Lock()
+	k.misses["nope"] = time.Now().Add(-missTTL)
+	k.mu.Unlock()
+
+	if _, err := k.Authenticate(ctx, bogus); !errors.Is(err, ErrUnknownKey) {
+		t.Fatal(err)
+	}
+	if *lookups != 2 {
+		t.Fatalf("lookups = %d, want 2 (miss expired)", *lookups)
+	}
+}
+
+func TestMissBounded(t *testing.T) {
+	k, _ := newTestKeys(nil)
+	now := time.Now()
+	for i := 0; i < maxMisses; i++ {
+		k.misses["p"+strconv.Itoa(i)] = now.Add(-missTTL)
+	}
+	k.misses["fresh"] = now
+
+	k.miss("new", now)
+
+	if len(k.misses) != 2 {
+		t.Fatalf("misses = %d, want 2 (expired ones swept)", len(k.misses))
+	}
+	if _, ok := k.misses["fresh"]; !ok {
+		t.Fatal("unexpired miss dropped")
+	}
+}
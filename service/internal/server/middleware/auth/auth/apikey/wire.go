@@ -0,0 +1,5 @@
+package apikey
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewAPIKeys)
@@ -0,0 +1,196 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"service/internal/conf/v1"
+)
+
+/*
+   Authenticators resolve request credentials into a Principal.
+
+   - Implementations (paseto, jwt, apikey) Register themselves by name.
+   - auth.authenticators in config sets which ones are tried and in which order.
+   - An authenticator returns ErrNotApplicable when the credentials are not
+     its kind (e.g. a JWT for PASETO); the next one is tried. Any other error
+     ends the chain (the request is rejected).
+   - Every principal is checked against the Revoker (see package revocation),
+     whichever authenticator produced it.
+*/
+
+// DefaultOrder is used when auth.authenticators is empty.
+var DefaultOrder = []string{"paseto", "apikey"}
+
+var (
+	ErrNotApplicable = errors.New("credentials not handled by this authenticator")
+	ErrNoCredentials = errors.New("missing authorization header")
+	ErrRevoked       = errors.New("token revoked")
+)
+
+// Credentials read from the request.
+type Credentials struct {
+	Bearer string // "Authorization" without "Bearer "
+	APIKey string // "X-Secret-Access"
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Method    string         // authenticator name: "paseto", "jwt", "apikey"
+	Subject   string         // username / "sub" / "apikey:<id>"
+	Roles     []string       // roles used by authz
+	CompanyID uint           // tenant (0 = none)
+	ID        string         // token id ("jti") or key prefix
+	IssuedAt  int64          // unix seconds (0 = unknown)
+	ExpiresAt int64          // unix seconds (0 = never)
+	Claims    map[string]any // raw claims as received
+	Details   any            // authenticator-specific value (e.g. *paseto.Claims)
+}
+
+// Authenticator resolves credentials into a Principal.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, cred Credentials) (*Principal, error)
+}
+
+// Reasoner is implemented by authenticators that classify their errors
+// (e.g. "TOKEN_EXPIRED"); the reason is returned to the client.
+type Reasoner interface {
+	Reason(err error) string
+}
+
+// Revoker reports whether an authenticated principal was revoked.
+type Revoker interface {
+	Revoked(p *Principal) bool
+}
+
+type revokerHolder struct{ r Revoker }
+
+var currentRevoker atomic.Pointer[revokerHolder]
+
+// UseRevoker installs the denylist checked after every successful
+// authentication (nil disables it).
+func UseRevoker(r Revoker) {
+	currentRevoker.Store(&revokerHolder{r: r})
+}
+
+func revoked(p *Principal) bool {
+	h := currentRevoker.Load()
+	return h != nil && h.r != nil && h.r.Revoked(p)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Authenticator{}
+	order    atomic.Pointer[[]string]
+)
+
+// Register installs a (named) authenticator; nil removes it.
+func Register(name string, a Authenticator) {
+	mu.Lock()
+	defer mu.Unlock()
+	if a == nil {
+		delete(registry, name)
+		return
+	}
+	registry[name] = a
+}
+
+// Get returns a registered authenticator.
+func Get(name string) (Authenticator, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	a, ok := registry[name]
+	return a, ok
+}
+
+// Init sets the order of authenticators from config (call once at startup).
+func Init(c *conf.Auth) {
+	names := make([]string, 0, len(c.GetAuthenticators()))
+	for _, n := range c.GetAuthenticators() {
+		if n = strings.ToLower(strings.TrimSpace(n)); n != "" {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		names = DefaultOrder
+	}
+	order.Store(&names)
+}
+
+// Order returns the configured authenticator names.
+func Order() []string {
+	if p := order.Load(); p != nil {
+		return *p
+	}
+	return DefaultOrder
+}
+
+// Authenticate runs the configured chain. Unregistered names are skipped.
+// A result already stored in ctx by Resolve for the same credentials is reused,
+// so a request is verified once even if several middlewares need the caller.
+func Authenticate(ctx context.Context, cred Credentials) (*Principal, error) {
+	if r, ok := ctx.Value(ctxKeyResolved{}).(*resolved); ok && r.cred == cred {
+		return r.p, r.err
+	}
+	return authenticate(ctx, cred)
+}
+
+// Resolve is Authenticate that also returns ctx carrying the result for later
+// Authenticate calls of the same request.
+func Resolve(ctx context.Context, cred Credentials) (context.Context, *Principal, error) {
+	p, err := Authenticate(ctx, cred)
+	return context.WithValue(ctx, ctxKeyResolved{}, &resolved{cred: cred, p: p, err: err}), p, err
+}
+
+type ctxKeyResolved struct{}
+
+type resolved struct {
+	cred Credentials
+	p    *Principal
+	err  error
+}
+
+func authenticate(ctx context.Context, cred Credentials) (*Principal, error) {
+	if cred.Bearer == "" && cred.APIKey == "" {
+		return nil, ErrNoCredentials
+	}
+	for _, name := range Order() {
+		a, ok := Get(name)
+		if !ok {
+			continue
+		}
+		p, err := a.Authenticate(ctx, cred)
+		if errors.Is(err, ErrNotApplicable) {
+			continue
+		}
+		if err != nil {
+			reason := "INVALID_CREDENTIALS"
+			if r, ok := a.(Reasoner); ok {
+				reason = r.Reason(err)
+			}
+			return nil, &Error{Method: name, Reason: reason, Err: err}
+		}
+		if p.Method == "" {
+			p.Method = name
+		}
+		if revoked(p) {
+			return nil, &Error{Method: name, Reason: "TOKEN_REVOKED", Err: ErrRevoked}
+		}
+		return p, nil
+	}
+	return nil, ErrNotApplicable
+}
+
+// Error is a failure of a specific authenticator.
+type Error struct {
+	Method string
+	Reason string // e.g. "TOKEN_EXPIRED", "INVALID_API_KEY"
+	Err    error
+}
+
+func (e *Error) Error() string { return e.Method + ": " + e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
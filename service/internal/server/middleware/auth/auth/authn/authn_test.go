@@ -0,0 +1,69 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service/internal/conf/v1"
+)
+
+type fakeAuthenticator struct {
+	name string
+	p    *Principal
+}
+
+func (f fakeAuthenticator) Name() string { return f.name }
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, cred Credentials) (*Principal, error) {
+	if cred.Bearer == "" {
+		return nil, ErrNotApplicable
+	}
+	p := *f.p
+	return &p, nil
+}
+
+type denyID string
+
+func (d denyID) Revoked(p *Principal) bool { return p.ID == string(d) }
+
+func TestAuthenticateChecksRevocation(t *testing.T) {
+	Init(&conf.Auth{Authenticators: []string{"fake"}})
+	t.Cleanup(func() {
+		Init(nil)
+		Register("fake", nil)
+		UseRevoker(nil)
+	})
+
+	tests := []struct {
+		name    string
+		id      string
+		revoker Revoker
+		want    error
+	}{
+		{"no revoker", "t1", nil, nil},
+		{"not revoked", "t1", denyID("t2"), nil},
+		{"revoked", "t2", denyID("t2"), ErrRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Register("fake", fakeAuthenticator{name: "fake", p: &Principal{Subject: "alice", ID: tt.id}})
+			UseRevoker(tt.revoker)
+
+			p, err := Authenticate(context.Background(), Credentials{Bearer: "token"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				var ae *Error
+				if !errors.As(err, &ae) || ae.Reason != "TOKEN_REVOKED" || ae.Method != "fake" {
+					t.Errorf("error = %#v, want TOKEN_REVOKED from fake", err)
+				}
+				return
+			}
+			if p.Method != "fake" {
+				t.Errorf("method = %q, want fake", p.Method)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package jwt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/auth/authn"
+	"service/internal/server/middleware/auth/auth/paseto"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Name of the JWT authenticator in auth.authenticators.
+const Name = "jwt"
+
+const defaultRefreshEvery = time.Hour
+
+type options struct {
+	issuer       string
+	audience     []string
+	leeway       time.Duration
+	algorithms   map[string]struct{}
+	subjectClaim string
+	rolesClaim   string
+	companyClaim string
+}
+
+// Authenticator verifies JWTs (OIDC access/ID tokens) against a JWKS.
+type Authenticator struct {
+	jwks *JWKS
+	opts options
+	log  *log.Helper
+}
+
+// NewAuthenticator creates the JWT authenticator and registers it in authn.
+// Returns nil when "jwt" is not listed in auth.authenticators.
+func NewAuthenticator(c *conf.Auth, logger log.Logger) (*Authenticator, func(), error) {
+	h := log.NewHelper(logger)
+	if !listed(c.GetAuthenticators(), Name) {
+		h.Infof("[JWT] [SKIPPED] JWT authenticator is not enabled")
+		return nil, func() {}, nil
+	}
+	jc := c.GetJwt()
+	if strings.TrimSpace(jc.GetJwks()) == "" {
+		h.Warnf("[JWT] [SKIPPED] auth.jwt.jwks is empty")
+		return nil, func() {}, nil
+	}
+
+	a := &Authenticator{
+		jwks: NewJWKS(jc.GetJwks(), durationOr(jc.GetRefreshEvery().AsDuration(), defaultRefreshEvery), logger),
+		opts: optionsFromConf(jc),
+		log:  h,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := a.jwks.Reload(ctx); err != nil {
+		h.Errorf("[JWT] jwks load error (will retry): %v", err)
+	} else {
+		h.Infof("[JWT] jwks loaded: %d keys from %s", a.jwks.Len(), jc.GetJwks())
+	}
+	go a.jwks.Run(ctx, func(err error) { h.Warnf("[JWT] jwks reload error: %v", err) })
+
+	authn.Register(Name, a)
+	return a, func() {
+		cancel()
+		authn.Register(Name, nil)
+	}, nil
+}
+
+func optionsFromConf(jc *conf.Auth_Jwt) options {
+	o := options{
+		issuer:       strings.TrimSpace(jc.GetIssuer()),
+		audience:     jc.GetAudience(),
+		leeway:       jc.GetLeeway().AsDuration(),
+		algorithms:   map[string]struct{}{},
+		subjectClaim: strOr(jc.GetSubjectClaim(), "sub"),
+		rolesClaim:   strOr(jc.GetRolesClaim(), "roles"),
+		companyClaim: strOr(jc.GetCompanyClaim(), "company_id"),
+	}
+	algs := jc.GetAlgorithms()
+	if len(algs) == 0 {
+		algs = DefaultAlgorithms
+	}
+	for _, a := range algs {
+		o.algorithms[strings.TrimSpace(a)] = struct{}{}
+	}
+	return o
+}
+
+func (a *Authenticator) Name() string { return Name }
+
+// Reason implements authn.Reasoner.
+func (a *Authenticator) Reason(err error) string { return Reason(err) }
+
+func (a *Authenticator) Authenticate(ctx context.Context, cred authn.Credentials) (*authn.Principal, error) {
+	raw := paseto.SkipBearer(cred.Bearer)
+	if !looksLikeJWT(raw) {
+		return nil, authn.ErrNotApplicable
+	}
+
+	t, err := parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := a.opts.algorithms[t.header.Alg]; !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key, err := a.jwks.Key(ctx, t.header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(t, key); err != nil {
+		return nil, err
+	}
+
+	c, err := decodeClaims(t.payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(&a.opts, time.Now()); err != nil {
+		return nil, err
+	}
+	return a.principal(c)
+}
+
+func (a *Authenticator) principal(c claims) (*authn.Principal, error) {
+	sub := c.string(a.opts.subjectClaim)
+	if sub == "" {
+		return nil, ErrMissingSubject
+	}
+	p := &authn.Principal{
+		Method:  Name,
+		Subject: sub,
+		Roles:   c.strings(a.opts.rolesClaim),
+		ID:      c.string("jti"),
+		Claims:  c,
+	}
+	if company, ok := c.int(a.opts.companyClaim); ok && company > 0 {
+		p.CompanyID = uint(company)
+	}
+	p.IssuedAt, _ = c.int("iat")
+	p.ExpiresAt, _ = c.int("exp")
+	return p, nil
+}
+
+func listed(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func strOr(s, def string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return def
+	}
+	return s
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,166 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed            = errors.New("malformed jwt")
+	ErrUnsupportedAlgorithm = errors.New("unsupported jwt algorithm")
+	ErrUnknownKeyID         = errors.New("unknown key id")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotYetValid     = errors.New("token not valid yet")
+	ErrTokenIssuedInFuture  = errors.New("token issued in the future")
+	ErrInvalidIssuer        = errors.New("invalid issuer")
+	ErrInvalidAudience      = errors.New("invalid audience")
+	ErrMissingSubject       = errors.New("missing subject")
+	ErrMissingExpiration    = errors.New("missing exp")
+)
+
+// Reason maps an error to a short code for clients, same codes as PASETO where they overlap.
+func Reason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return "TOKEN_EXPIRED"
+	case errors.Is(err, ErrTokenNotYetValid):
+		return "TOKEN_NOT_YET_VALID"
+	case errors.Is(err, ErrTokenIssuedInFuture):
+		return "TOKEN_ISSUED_IN_FUTURE"
+	case errors.Is(err, ErrInvalidIssuer):
+		return "INVALID_ISSUER"
+	case errors.Is(err, ErrInvalidAudience):
+		return "INVALID_AUDIENCE"
+	case errors.Is(err, ErrMissingSubject), errors.Is(err, ErrMissingExpiration):
+		return "MISSING_CLAIM"
+	case errors.Is(err, ErrUnsupportedAlgorithm):
+		return "UNSUPPORTED_TOKEN"
+	case errors.Is(err, ErrUnknownKeyID):
+		return "UNKNOWN_KEY_ID"
+	case errors.Is(err, ErrInvalidSignature):
+		return "INVALID_SIGNATURE"
+	default:
+		return "INVALID_TOKEN"
+	}
+}
+
+type claims map[string]any
+
+func decodeClaims(payload []byte) (claims, error) {
+	var c claims
+	d := json.NewDecoder(strings.NewReader(string(payload)))
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil || c == nil {
+		return nil, ErrMalformed
+	}
+	return c, nil
+}
+
+// validate checks time claims, issuer and audience. Access tokens must expire:
+// a token without "exp" is rejected.
+func (c claims) validate(o *options, now time.Time) error {
+	unix := now.Unix()
+	leeway := int64(o.leeway / time.Second)
+
+	exp, ok := c.int("exp")
+	if !ok {
+		return ErrMissingExpiration
+	}
+	if unix >= exp+leeway { // expired from its exp second on, as PASETO tokens
+		return ErrTokenExpired
+	}
+	if nbf, ok := c.int("nbf"); ok && unix+leeway < nbf {
+		return ErrTokenNotYetValid
+	}
+	if iat, ok := c.int("iat"); ok && iat > unix+leeway {
+		return ErrTokenIssuedInFuture
+	}
+	if o.issuer != "" && c.string("iss") != o.issuer {
+		return ErrInvalidIssuer
+	}
+	if len(o.audience) > 0 && !anyIn(c.strings("aud"), o.audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// get resolves a dotted path, e.g. "realm_access.roles".
+func (c claims) get(path string) (any, bool) {
+	var cur any = map[string]any(c)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func (c claims) string(path string) string {
+	v, _ := c.get(path)
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	}
+	return ""
+}
+
+func (c claims) int(path string) (int64, bool) {
+	v, ok := c.get(path)
+	if !ok {
+		return 0, false
+	}
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, true
+		}
+		if f, err := t.Float64(); err == nil {
+			return int64(f), true
+		}
+	case string:
+		if i, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// strings reads a list claim: JSON array, or a string separated by commas/spaces.
+func (c claims) strings(path string) []string {
+	v, _ := c.get(path)
+	var out []string
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			if s, ok := e.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		for _, s := range strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ' ' }) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func anyIn(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
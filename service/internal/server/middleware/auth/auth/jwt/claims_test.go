@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	base := options{issuer: "https://idp", audience: []string{"api"}, leeway: 5 * time.Second}
+
+	tests := []struct {
+		name   string
+		claims string
+		want   error
+	}{
+		{"valid", `{"iss":"https://idp","aud":"api","exp":1700000060,"iat":1699999990}`, nil},
+		{"audience list", `{"iss":"https://idp","aud":["web","api"],"exp":1700000060}`, nil},
+		{"missing exp", `{"iss":"https://idp","aud":"api"}`, ErrMissingExpiration},
+		{"exp not a number", `{"iss":"https://idp","aud":"api","exp":"soon"}`, ErrMissingExpiration},
+		{"expired", `{"iss":"https://idp","aud":"api","exp":1699999990}`, ErrTokenExpired},
+		{"expired within leeway", `{"iss":"https://idp","aud":"api","exp":1699999997}`, nil},
+		{"at exp second", `{"iss":"https://idp","aud":"api","exp":1699999995}`, ErrTokenExpired},
+		{"one second before exp", `{"iss":"https://idp","aud":"api","exp":1699999996}`, nil},
+		{"not yet valid", `{"iss":"https://idp","aud":"api","exp":1700000060,"nbf":1700000030}`, ErrTokenNotYetValid},
+		{"issued in future", `{"iss":"https://idp","aud":"api","exp":1700000060,"iat":1700000030}`, ErrTokenIssuedInFuture},
+		{"wrong issuer", `{"iss":"https://other","aud":"api","exp":1700000060}`, ErrInvalidIssuer},
+		{"wrong audience", `{"iss":"https://idp","aud":"web","exp":1700000060}`, ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeClaims([]byte(tt.claims))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.validate(&base, now); !errors.Is(err, tt.want) {
+				t.Errorf("validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+/*
+   JWKS: public keys of the identity provider.
+
+   - Source is an http(s) URL or a file path (handy for tests and air-gapped setups).
+   - Keys are cached and reloaded every refreshEvery; an unknown "kid" triggers
+     an early reload (at most once per minRefetch since the last attempt,
+     successful or not) to pick up key rotation.
+   - Concurrent reloads share a single fetch.
+   - A failed reload keeps the previous keys.
+   - Keys that cannot verify signatures (encryption keys, unknown kty/crv/alg)
+     are skipped and logged; the rest of the set is used.
+*/
+
+const minRefetch = 30 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDoc struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKS is a cached set of verification keys.
+type JWKS struct {
+	source       string
+	refreshEvery time.Duration
+	rest         *resty.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey // kid -> key ("" if the JWK has no kid)
+	attemptedAt time.Time                   // last reload, failed ones included
+	reloads     singleflight.Group
+
+	log *log.Helper
+}
+
+func NewJWKS(source string, refreshEvery time.Duration, logger log.Logger) *JWKS {
+	return &JWKS{
+		source:       strings.TrimSpace(source),
+		refreshEvery: refreshEvery,
+		rest:         resty.New().SetTimeout(5 * time.Second),
+		keys:         map[string]crypto.PublicKey{},
+		log:          log.NewHelper(logger),
+	}
+}
+
+// Reload fetches the JWKS; on error the current keys are kept.
+func (j *JWKS) Reload(ctx context.Context) error {
+	_, err, _ := j.reloads.Do("jwks", func() (any, error) {
+		return nil, j.reload(ctx)
+	})
+	return err
+}
+
+func (j *JWKS) reload(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = time.Now()
+	j.mu.Unlock()
+
+	raw, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys, skipped, err := parseJWKS(raw)
+	for _, err := range skipped {
+		j.log.Warnf("[JWT] jwks key skipped: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// Key returns the key for kid, reloading once if it is unknown.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if k, ok := j.lookup(kid); ok {
+		return k, nil
+	}
+
+	j.mu.RLock()
+	recent := time.Since(j.attemptedAt) < minRefetch
+	j.mu.RUnlock()
+	if !recent {
+		if err := j.Reload(ctx); err != nil {
+			return nil, err
+		}
+		if k, ok := j.lookup(kid); ok {
+			return k, nil
+		}
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// Len returns the number of loaded keys.
+func (j *JWKS) Len() int {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return len(j.keys)
+}
+
+// Run reloads the JWKS every refreshEvery until ctx is done.
+func (j *JWKS) Run(ctx context.Context, onErr func(error)) {
+	if j.refreshEvery <= 0 {
+		return
+	}
+	t := time.NewTicker(j.refreshEvery)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := j.Reload(ctx); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if k, ok := j.keys[kid]; ok {
+		return k, true
+	}
+	// token without kid and a single key
+	if kid == "" && len(j.keys) == 1 {
+		for _, k := range j.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if j.source == "" {
+		return nil, errors.New("jwks source is empty")
+	}
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+	resp, err := j.rest.R().SetContext(ctx).Get(j.source)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("jwks: bad HTTP status: %s", resp.Status())
+	}
+	return resp.Body(), nil
+}
+
+// parseJWKS returns the signing keys of a JWKS document; unusable keys are
+// reported in skipped. It fails only when no signing key is left.
+func parseJWKS(raw []byte) (keys map[string]crypto.PublicKey, skipped []error, err error) {
+	var doc jwksDoc
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, fmt.Errorf("jwks: %w", err)
+	}
+	keys = make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if k.Alg != "" && !signingAlgorithm(k.Alg) {
+			skipped = append(skipped, fmt.Errorf("kid %q: unsupported alg %q", k.Kid, k.Alg))
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("kid %q: %w", k.Kid, err))
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, skipped, errors.New("jwks: no signing keys")
+	}
+	return keys, skipped, nil
+}
+
+func signingAlgorithm(alg string) bool {
+	for _, a := range DefaultAlgorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := b64Int(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64Int(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64Int(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64Int(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func b64Int(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
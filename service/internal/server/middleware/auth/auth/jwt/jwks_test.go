@@ -0,0 +1,223 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+	"service/internal/server/middleware/auth/auth/authn"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func okpKey(kid string, pub ed25519.PublicKey) string {
+	return fmt.Sprintf(`{"kty":"OKP","crv":"Ed25519","kid":%q,"use":"sig","alg":"EdDSA","x":%q}`, kid, b64(pub))
+}
+
+func ecKey(t *testing.T, kid string) string {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf(`{"kty":"EC","crv":"P-256","kid":%q,"x":%q,"y":%q}`,
+		kid, b64(k.X.FillBytes(make([]byte, 32))), b64(k.Y.FillBytes(make([]byte, 32))))
+}
+
+func TestParseJWKS(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	ed := okpKey("ed", pub)
+	ec := ecKey(t, "ec")
+
+	tests := []struct {
+		name    string
+		doc     string
+		kids    []string
+		skipped int
+		err     bool
+	}{
+		{"signing keys", `{"keys":[` + ed + `,` + ec + `]}`, []string{"ed", "ec"}, 0, false},
+		{"encryption key ignored", `{"keys":[` + ed + `,{"kty":"RSA","kid":"enc","use":"enc","n":"AQ","e":"AQAB"}]}`, []string{"ed"}, 0, false},
+		{"unsupported kty skipped", `{"keys":[` + ed + `,{"kty":"oct","kid":"hs","k":"c2VjcmV0"}]}`, []string{"ed"}, 1, false},
+		{"unsupported alg skipped", `{"keys":[` + ed + `,{"kty":"RSA","kid":"oaep","alg":"RSA-OAEP","n":"AQ","e":"AQAB"}]}`, []string{"ed"}, 1, false},
+		{"unsupported curve skipped", `{"keys":[{"kty":"OKP","crv":"X25519","kid":"x","x":"AA"},` + ec + `]}`, []string{"ec"}, 1, false},
+		{"only unusable keys", `{"keys":[{"kty":"oct","kid":"hs","k":"c2VjcmV0"}]}`, nil, 1, true},
+		{"empty set", `{"keys":[]}`, nil, 0, true},
+		{"not json", `keys`, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, skipped, err := parseJWKS([]byte(tt.doc))
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if len(skipped) != tt.skipped {
+				t.Errorf("skipped = %v, want %d", skipped, tt.skipped)
+			}
+			if len(keys) != len(tt.kids) {
+				t.Errorf("keys = %d, want %v", len(keys), tt.kids)
+			}
+			for _, kid := range tt.kids {
+				if _, ok := keys[kid]; !ok {
+					t.Errorf("kid %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+// sign returns a compact EdDSA JWS of claims.
+func sign(t *testing.T, priv ed25519.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	h, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": kid, "typ": "JWT"})
+	p, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := b64(h) + "." + b64(p)
+	return signed + "." + b64(ed25519.Sign(priv, []byte(signed)))
+}
+
+func TestAuthenticateWithJWKSServer(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+
+	doc := `{"keys":[` + okpKey("k1", pub) + `,{"kty":"RSA","kid":"enc","alg":"RSA-OAEP-256","n":"AQ","e":"AQAB"}]}`
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer srv.Close()
+
+	a := &Authenticator{
+		jwks: NewJWKS(srv.URL, 0, log.DefaultLogger),
+		opts: optionsFromConf(&conf.Auth_Jwt{Issuer: "https://idp", Audience: []string{"api"}}),
+	}
+	if err := a.jwks.Reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if a.jwks.Len() != 1 {
+		t.Fatalf("keys = %d, want 1 (unusable key skipped)", a.jwks.Len())
+	}
+
+	exp := time.Now().Add(time.Minute).Unix()
+	valid := map[string]any{"iss": "https://idp", "aud": "api", "sub": "alice", "exp": exp, "roles": []string{"VIEWER"}, "company_id": 7}
+	noExp := map[string]any{"iss": "https://idp", "aud": "api", "sub": "alice"}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", sign(t, priv, "k1", valid), nil},
+		{"no exp", sign(t, priv, "k1", noExp), ErrMissingExpiration},
+		{"wrong key", sign(t, other, "k1", valid), ErrInvalidSignature},
+		{"unknown kid", sign(t, priv, "k2", valid), ErrUnknownKeyID},
+		{"not a jwt", "v4.public.xyz", authn.ErrNotApplicable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(context.Background(), authn.Credentials{Bearer: "Bearer " + tt.token})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if p.Subject != "alice" || p.CompanyID != 7 || len(p.Roles) != 1 || p.ExpiresAt != exp {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+	if n := hits.Load(); n > 2 {
+		t.Errorf("jwks fetched %d times, want at most 2 (unknown kid refetch is rate limited)", n)
+	}
+}
+
+func TestJWKSFailingEndpoint(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	doc := `{"keys":[` + okpKey("k1", pub) + `]}`
+
+	var hits atomic.Int32
+	var failing atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			<-release
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer srv.Close()
+
+	j := NewJWKS(srv.URL, 0, log.DefaultLogger)
+	if err := j.Reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	// The IdP goes down: concurrent unknown-kid requests share one fetch.
+	failing.Store(true)
+	j.mu.Lock()
+	j.attemptedAt = time.Time{}
+	j.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := j.Key(context.Background(), "k2")
+			errs <- err
+		}()
+	}
+	for hits.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // let the other callers join the reload
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil {
+			t.Fatal("Key() succeeded for an unknown kid")
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("jwks fetched %d times, want 2 (concurrent reloads collapsed)", n)
+	}
+
+	// The failed attempt rate-limits further refetches.
+	for i := 0; i < 10; i++ {
+		if _, err := j.Key(context.Background(), "k3"); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("Key() error = %v, want %v", err, ErrUnknownKeyID)
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("jwks fetched %d times after a failed reload, want 2", n)
+	}
+
+	// Previous keys are kept.
+	if _, err := j.Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("known key lost after a failed reload: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// DefaultAlgorithms are accepted when jwt.algorithms is empty ("none" and HMAC never are).
+var DefaultAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// token is a parsed (not yet verified) compact JWS.
+type token struct {
+	header  header
+	signed  []byte // "<header>.<payload>"
+	payload []byte
+	sig     []byte
+}
+
+// looksLikeJWT reports whether s is "<b64 json>.<b64>.<b64>".
+func looksLikeJWT(s string) bool {
+	if strings.Count(s, ".") != 2 {
+		return false
+	}
+	h, err := base64.RawURLEncoding.DecodeString(s[:strings.IndexByte(s, '.')])
+	return err == nil && len(h) > 0 && h[0] == '{'
+}
+
+func parse(s string) (*token, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	hb, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	pb, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	t := &token{
+		signed:  []byte(parts[0] + "." + parts[1]),
+		payload: pb,
+		sig:     sig,
+	}
+	if err := json.Unmarshal(hb, &t.header); err != nil {
+		return nil, ErrMalformed
+	}
+	return t, nil
+}
+
+// verifySignature checks the signature of t with key for the header algorithm.
+func verifySignature(t *token, key crypto.PublicKey) error {
+	alg := t.header.Alg
+	switch alg {
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		h := hashFor(alg[2:])
+		digest := sum(h, t.signed)
+		if alg[0] == 'R' {
+			if rsa.VerifyPKCS1v15(pub, h, digest, t.sig) != nil {
+				return ErrInvalidSignature
+			}
+			return nil
+		}
+		if rsa.VerifyPSS(pub, h, digest, t.sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "ES256", "ES384", "ES512":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(t.sig) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(t.sig[:size])
+		s := new(big.Int).SetBytes(t.sig[size:])
+		if !ecdsa.Verify(pub, sum(hashFor(alg[2:]), t.signed), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, t.signed, t.sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrUnsupportedAlgorithm
+}
+
+func hashFor(bits string) crypto.Hash {
+	switch bits {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+func sum(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
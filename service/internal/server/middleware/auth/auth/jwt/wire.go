@@ -0,0 +1,5 @@
+package jwt
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewAuthenticator)
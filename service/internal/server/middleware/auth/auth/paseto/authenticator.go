@@ -0,0 +1,66 @@
+package paseto
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"service/internal/server/middleware/auth/auth/authn"
+)
+
+// Name of the PASETO authenticator in auth.authenticators.
+const Name = "paseto"
+
+func init() { authn.Register(Name, Authenticator{}) }
+
+// Authenticator verifies PASETO bearer tokens (v2.local, v2.public, v4.public).
+type Authenticator struct{}
+
+func (Authenticator) Name() string { return Name }
+
+func (Authenticator) Authenticate(_ context.Context, cred authn.Credentials) (*authn.Principal, error) {
+	if !IsPaseto(cred.Bearer) {
+		return nil, authn.ErrNotApplicable
+	}
+	c, err := VerifyAccessToken(cred.Bearer)
+	if err != nil {
+		return nil, err
+	}
+	return c.Principal(), nil
+}
+
+// Reason implements authn.Reasoner.
+func (Authenticator) Reason(err error) string { return Reason(err) }
+
+// IsPaseto reports whether token looks like a PASETO token this service can verify.
+func IsPaseto(token string) bool {
+	token = SkipBearer(token)
+	return strings.HasPrefix(token, headerV2Local) ||
+		strings.HasPrefix(token, headerV2Public) ||
+		strings.HasPrefix(token, headerV4Public)
+}
+
+// Principal converts verified claims into an authn.Principal.
+func (c *Claims) Principal() *authn.Principal {
+	var raw map[string]any
+	if b, err := json.Marshal(c); err == nil {
+		_ = json.Unmarshal(b, &raw)
+	}
+	roles := make([]string, 0)
+	for _, r := range strings.Split(c.Roles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return &authn.Principal{
+		Method:    Name,
+		Subject:   c.Username,
+		Roles:     roles,
+		CompanyID: c.CompanyID,
+		ID:        c.Jti,
+		IssuedAt:  c.Iat,
+		ExpiresAt: c.Exp,
+		Claims:    raw,
+		Details:   c,
+	}
+}
@@ -1,7 +1,9 @@
 package paseto
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -17,10 +19,71 @@ type Claims struct {
 	Expired   string `json:"expired"`
 	Exp       int64  `json:"exp"`
 	Iat       int64  `json:"iat"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Jti       string `json:"jti,omitempty"`
 }
 
-func (c *Claims) valid() bool {
-	return time.Now().Unix() < c.Exp
+// validate applies policy p to the claims at time now.
+func (c *Claims) validate(p *Policy, now time.Time) error {
+	if p.Type != "" && c.Type != p.Type {
+		return ErrNotAnAccessToken
+	}
+	for _, name := range p.RequiredClaims {
+		if !c.has(name) {
+			return fmt.Errorf("%w: %s", ErrMissingClaim, name)
+		}
+	}
+
+	leeway := int64(p.Leeway / time.Second)
+	unix := now.Unix()
+
+	if unix >= c.Exp+leeway { // RFC 7519: now must be before exp (same rule for JWT)
+		return ErrTokenExpired
+	}
+	if c.Nbf != 0 && unix+leeway < c.Nbf {
+		return ErrTokenNotYetValid
+	}
+	if c.Iat != 0 && c.Iat > unix+leeway {
+		return ErrTokenIssuedInFuture
+	}
+	if p.MaxAge > 0 && c.Iat != 0 && unix-c.Iat > int64(p.MaxAge/time.Second)+leeway {
+		return ErrTokenTooOld
+	}
+	if p.Issuer != "" && c.Iss != p.Issuer {
+		return ErrInvalidIssuer
+	}
+	if len(p.Audience) > 0 && !contains(p.Audience, c.Aud) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// has reports whether the claim (json name) is present and non-zero.
+func (c *Claims) has(name string) bool {
+	var m map[string]interface{}
+	data, _ := json.Marshal(c)
+	_ = json.Unmarshal(data, &m)
+	switch v := m[name].(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	default:
+		return true
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
 func SkipBearer(token string) string {
@@ -32,7 +95,45 @@ func SkipBearer(token string) string {
 }
 
 var (
-	ErrInvalidSignature = errors.New("invalid signature")
-	ErrNotAnAccessToken = errors.New("not an access token")
-	ErrTokenExpired     = errors.New("token expired")
+	ErrInvalidSignature    = errors.New("invalid signature")
+	ErrNotAnAccessToken    = errors.New("not an access token")
+	ErrTokenExpired        = errors.New("token expired")
+	ErrUnsupportedToken    = errors.New("unsupported token type")
+	ErrUnknownKeyID        = errors.New("unknown key id")
+	ErrTokenNotYetValid    = errors.New("token not yet valid")
+	ErrTokenIssuedInFuture = errors.New("token issued in the future")
+	ErrTokenTooOld         = errors.New("token too old")
+	ErrInvalidIssuer       = errors.New("invalid issuer")
+	ErrInvalidAudience     = errors.New("invalid audience")
+	ErrMissingClaim        = errors.New("missing claim")
 )
+
+// Reason maps a validation error to a stable code for error metadata.
+func Reason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return "TOKEN_EXPIRED"
+	case errors.Is(err, ErrTokenNotYetValid):
+		return "TOKEN_NOT_YET_VALID"
+	case errors.Is(err, ErrTokenIssuedInFuture):
+		return "TOKEN_ISSUED_IN_FUTURE"
+	case errors.Is(err, ErrTokenTooOld):
+		return "TOKEN_TOO_OLD"
+	case errors.Is(err, ErrInvalidIssuer):
+		return "INVALID_ISSUER"
+	case errors.Is(err, ErrInvalidAudience):
+		return "INVALID_AUDIENCE"
+	case errors.Is(err, ErrMissingClaim):
+		return "MISSING_CLAIM"
+	case errors.Is(err, ErrNotAnAccessToken):
+		return "INVALID_TOKEN_TYPE"
+	case errors.Is(err, ErrUnsupportedToken):
+		return "UNSUPPORTED_TOKEN"
+	case errors.Is(err, ErrUnknownKeyID):
+		return "UNKNOWN_KEY_ID"
+	case errors.Is(err, ErrInvalidSignature):
+		return "INVALID_SIGNATURE"
+	default:
+		return "INVALID_TOKEN"
+	}
+}
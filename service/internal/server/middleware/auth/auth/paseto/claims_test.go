@@ -0,0 +1,66 @@
+package paseto
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	const unix = 1_700_000_000
+	base := Policy{Type: "access"}
+	strict := Policy{
+		Type:           "access",
+		Leeway:         5 * time.Second,
+		Issuer:         "https://idp",
+		Audience:       []string{"api", "web"},
+		RequiredClaims: []string{"jti", "company_id"},
+		MaxAge:         time.Hour,
+	}
+	valid := Claims{Type: "access", Exp: unix + 60, Iat: unix - 10, Iss: "https://idp", Aud: "api", Jti: "t1", CompanyID: 7}
+	with := func(f func(c *Claims)) Claims {
+		c := valid
+		f(&c)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		claims Claims
+		want   error
+	}{
+		{"valid", strict, valid, nil},
+		{"default policy", base, Claims{Type: "access", Exp: unix + 1}, nil},
+		{"wrong type", base, Claims{Type: "refresh", Exp: unix + 60}, ErrNotAnAccessToken},
+		{"no exp", base, Claims{Type: "access"}, ErrTokenExpired},
+		{"at exp second", base, Claims{Type: "access", Exp: unix}, ErrTokenExpired},
+		{"expired within leeway", strict, with(func(c *Claims) { c.Exp = unix - 4 }), nil},
+		{"expired past leeway", strict, with(func(c *Claims) { c.Exp = unix - 5 }), ErrTokenExpired},
+		{"nbf within leeway", strict, with(func(c *Claims) { c.Nbf = unix + 5 }), nil},
+		{"not yet valid", strict, with(func(c *Claims) { c.Nbf = unix + 6 }), ErrTokenNotYetValid},
+		{"iat within leeway", strict, with(func(c *Claims) { c.Iat = unix + 5 }), nil},
+		{"issued in future", strict, with(func(c *Claims) { c.Iat = unix + 6 }), ErrTokenIssuedInFuture},
+		{"max age within leeway", strict, with(func(c *Claims) { c.Iat = unix - 3605 }), nil},
+		{"too old", strict, with(func(c *Claims) { c.Iat = unix - 3606 }), ErrTokenTooOld},
+		{"max age without iat", strict, with(func(c *Claims) { c.Iat = 0 }), nil},
+		{"wrong issuer", strict, with(func(c *Claims) { c.Iss = "https://other" }), ErrInvalidIssuer},
+		{"no issuer", strict, with(func(c *Claims) { c.Iss = "" }), ErrInvalidIssuer},
+		{"second audience", strict, with(func(c *Claims) { c.Aud = "web" }), nil},
+		{"wrong audience", strict, with(func(c *Claims) { c.Aud = "admin" }), ErrInvalidAudience},
+		{"missing jti", strict, with(func(c *Claims) { c.Jti = "" }), ErrMissingClaim},
+		{"missing company", strict, with(func(c *Claims) { c.CompanyID = 0 }), ErrMissingClaim},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.claims.validate(&tt.policy, now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("validate() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil && Reason(err) == "INVALID_TOKEN" {
+				t.Errorf("Reason(%v) has no specific code", err)
+			}
+		})
+	}
+}
@@ -1,25 +1,91 @@
 package paseto
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
+	"strings"
+	"time"
 )
 
 func (v *Validator) verifyAccessTokenClaims(token string) (*Claims, error) {
 	token = SkipBearer(token)
 
 	var claims Claims
-	var footer string
+	if err := v.decode(token, &claims); err != nil {
+		return nil, err
+	}
+	if err := claims.validate(policy(), time.Now()); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
 
-	if err := v.paseto.Decrypt(token, v.secret, &claims, &footer); err != nil {
-		return nil, ErrInvalidSignature
+// decode verifies the token (by version/purpose) and fills claims.
+func (v *Validator) decode(token string, claims *Claims) error {
+	switch {
+	case strings.HasPrefix(token, headerV2Local):
+		if v.secret == nil {
+			return ErrUnsupportedToken
+		}
+		var footer string
+		if err := v.paseto.Decrypt(token, v.secret, claims, &footer); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case strings.HasPrefix(token, headerV2Public), strings.HasPrefix(token, headerV4Public):
+		if v.keys == nil {
+			return ErrUnsupportedToken
+		}
+		return v.verifyPublic(token, claims)
+	default:
+		return ErrUnsupportedToken
 	}
-	if claims.Type != "access" {
-		return nil, ErrNotAnAccessToken
+}
+
+// verifyPublic picks the key by "kid" from the footer (or tries every key when absent).
+func (v *Validator) verifyPublic(token string, claims *Claims) error {
+	var keys []ed25519.PublicKey
+	if kid := footerKeyID(tokenFooter(token)); kid != "" {
+		key, ok := v.keys.Get(kid)
+		if !ok {
+			return ErrUnknownKeyID
+		}
+		keys = []ed25519.PublicKey{key}
+	} else {
+		keys = v.keys.All()
 	}
-	if !claims.valid() {
-		return nil, ErrTokenExpired
+
+	for _, key := range keys {
+		var payload []byte
+		var err error
+		if strings.HasPrefix(token, headerV4Public) {
+			payload, err = verifyV4Public(token, key)
+		} else {
+			err = v.paseto.Verify(token, key, &payload, nil)
+		}
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(payload, claims); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
 	}
-	return &claims, nil
+	return ErrInvalidSignature
+}
+
+// footerKeyID extracts "kid" from a JSON footer ({"kid":"2025-01"}).
+func footerKeyID(footer []byte) string {
+	if len(footer) == 0 || footer[0] != '{' {
+		return ""
+	}
+	var f struct {
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return ""
+	}
+	return f.Kid
 }
 
 func (v *Validator) verifyAccessTokenMap(token string) (map[string]interface{}, error) {
@@ -0,0 +1,90 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	o1egl "github.com/o1egl/paseto"
+)
+
+// Official v2.public test vectors 2-S-2 and 2-S-3 (same key as 4-S-1).
+const (
+	vector2S2 = "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwaXJlcyI6IjIwMTktMDEtMDFUMDA6MDA6MDArMDA6MDAifSUGY_L1YtOvo1JeNVAWQkOBILGSjtkX_9-g2pVPad7_SAyejb6Q2TDOvfCOpWYH5DaFeLOwwpTnaTXeg8YbUwI"
+	vector2S3 = "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwaXJlcyI6IjIwMTktMDEtMDFUMDA6MDA6MDArMDA6MDAifcMYjoUaEYXAtzTDwlcOlxdcZWIZp8qZga3jFS8JwdEjEvurZhs6AmTU3bRW5pB9fOQwm43rzmibZXcAkQ4AzQs.UGFyYWdvbiBJbml0aWF0aXZlIEVudGVycHJpc2Vz"
+)
+
+// writeKey writes pub to dir/name as hex, or PEM when name ends in ".pem".
+func writeKey(t *testing.T, dir, name string, pub ed25519.PublicKey) {
+	t.Helper()
+	data := []byte(hex.EncodeToString(pub))
+	if filepath.Ext(name) == ".pem" {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodePublic(t *testing.T) {
+	vectorPub := ed25519.PublicKey(hexKey(t, vectorPublicKey))
+	pub1, priv1, _ := ed25519.GenerateKey(nil)
+	pub2, priv2, _ := ed25519.GenerateKey(nil)
+
+	dir := t.TempDir()
+	writeKey(t, dir, "k1.pem", pub1)
+	writeKey(t, dir, "k2.hex", pub2)
+	writeKey(t, dir, "vector", vectorPub)
+	kr, err := NewKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Validator{keys: kr, paseto: o1egl.NewV2()}
+
+	claims, _ := json.Marshal(map[string]any{"username": "alice", "type": "access"})
+	kid := func(k string) []byte { return []byte(`{"kid":"` + k + `"}`) }
+	v2, err := o1egl.NewV2().Sign(priv2, claims, string(kid("k2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"v2 vector", vector2S2, nil},
+		{"v2 vector with footer", vector2S3, nil},
+		{"v2 vector tampered", vector2S2[:20] + "x" + vector2S2[21:], ErrInvalidSignature},
+		{"v2 by kid", v2, nil},
+		{"v4 by kid", signV4(priv1, claims, kid("k1")), nil},
+		{"v4 without kid tries every key", signV4(priv2, claims, nil), nil},
+		{"v4 kid of another key", signV4(priv1, claims, kid("k2")), ErrInvalidSignature},
+		{"v4 unknown kid", signV4(priv1, claims, kid("k9")), ErrUnknownKeyID},
+		{"v4 not json", signV4(priv1, []byte("hello"), kid("k1")), ErrInvalidSignature},
+		{"v2.local without secret", "v2.local.AAAA", ErrUnsupportedToken},
+		{"v3", "v3.public.AAAA", ErrUnsupportedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Claims
+			err := v.decode(tt.token, &c)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("decode() = %v, want %v", err, tt.want)
+			}
+			if err == nil && tt.token != vector2S2 && tt.token != vector2S3 && c.Username != "alice" {
+				t.Errorf("claims = %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"service/pkg/logger"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Keyring holds Ed25519 public keys (v2.public / v4.public) indexed by key id (kid).
+//
+// Source is either:
+//   - a directory: every file is one key, kid = file name without extension;
+//   - a JSON file: {"<kid>": "<key>", ...}.
+//
+// Keys may be PEM ("PUBLIC KEY"), hex (64 chars) or base64 (32 bytes).
+type Keyring struct {
+	path string
+
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+// NewKeyring loads the keyring from path (file or directory).
+func NewKeyring(path string) (*Keyring, error) {
+	kr := &Keyring{path: path}
+	if err := kr.Reload(); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+// Reload re-reads keys from disk. On error the previous keys are kept.
+func (k *Keyring) Reload() error {
+	keys, err := loadKeys(k.path)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no public keys found in %s", k.path)
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// Get returns the key for kid.
+func (k *Keyring) Get(kid string) (ed25519.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// All returns every active key (used when the token has no kid).
+func (k *Keyring) All() []ed25519.PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	out := make([]ed25519.PublicKey, 0, len(kids))
+	for _, kid := range kids {
+		out = append(out, k.keys[kid])
+	}
+	return out
+}
+
+// Len returns the number of active keys.
+func (k *Keyring) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.keys)
+}
+
+// Watch reloads the keyring when the source changes, until stop is closed.
+// The parent directory is watched so atomic replaces (rename, k8s secrets) are seen.
+func (k *Keyring) Watch(stop <-chan struct{}) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dir := k.path
+	if fi, err := os.Stat(k.path); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(k.path)
+	}
+	if err := w.Add(dir); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	go func() {
+		defer w.Close()
+		// debounce bursts of events (editors, atomic writes)
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-stop:
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					timer = time.After(500 * time.Millisecond)
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("PASETO keyring: watch error", map[string]interface{}{"error": err})
+			case <-timer:
+				timer = nil
+				if err := k.Reload(); err != nil {
+					logger.Error("PASETO keyring: reload failed, keeping previous keys",
+						map[string]interface{}{"path": k.path, "error": err})
+					continue
+				}
+				logger.Info("PASETO keyring: reloaded", map[string]interface{}{"path": k.path, "keys": k.Len()})
+			}
+		}
+	}()
+	return nil
+}
+
+func loadKeys(path string) (map[string]ed25519.PublicKey, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]ed25519.PublicKey)
+
+	if !fi.IsDir() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]string
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, fmt.Errorf("keyring file %s: %w", path, err)
+		}
+		for kid, s := range m {
+			key, err := parsePublicKey([]byte(s))
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", kid, err)
+			}
+			keys[kid] = key
+		}
+		return keys, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		// skip hidden files and k8s "..data" links
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		full := filepath.Join(path, e.Name())
+		if fi, err := os.Stat(full); err != nil || fi.IsDir() {
+			continue
+		}
+		raw, err := os.ReadFile(full)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePublicKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key file %s: %w", full, err)
+		}
+		kid := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+func parsePublicKey(raw []byte) (ed25519.PublicKey, error) {
+	s := strings.TrimSpace(string(raw))
+
+	if strings.HasPrefix(s, "-----BEGIN") {
+		block, _ := pem.Decode([]byte(s))
+		if block == nil {
+			return nil, errors.New("invalid PEM")
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("PEM key is not Ed25519")
+		}
+		return key, nil
+	}
+
+	if b, err := hex.DecodeString(s); err == nil && len(b) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(b), nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == ed25519.PublicKeySize {
+			return ed25519.PublicKey(b), nil
+		}
+	}
+	return nil, errors.New("unsupported key format (want PEM, hex or base64 Ed25519 public key)")
+}
@@ -0,0 +1,132 @@
+package paseto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewKeyring(t *testing.T) {
+	pub1, _, _ := ed25519.GenerateKey(nil)
+	pub2, _, _ := ed25519.GenerateKey(nil)
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeKey(t, dir, "2025-01.pem", pub1)
+		writeKey(t, dir, "2025-02.hex", pub2)
+		writeKey(t, dir, ".hidden", pub2)
+		kr, err := NewKeyring(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k, ok := kr.Get("2025-01"); !ok || !k.Equal(pub1) {
+			t.Error("kid 2025-01 (PEM) missing")
+		}
+		if k, ok := kr.Get("2025-02"); !ok || !k.Equal(pub2) {
+			t.Error("kid 2025-02 (hex) missing")
+		}
+		if kr.Len() != 2 {
+			t.Errorf("keys = %d, want 2 (hidden files skipped)", kr.Len())
+		}
+	})
+
+	t.Run("json file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		raw, _ := json.Marshal(map[string]string{"a": base64.StdEncoding.EncodeToString(pub1)})
+		if err := os.WriteFile(path, raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		kr, err := NewKeyring(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k, ok := kr.Get("a"); !ok || !k.Equal(pub1) {
+			t.Error("kid a (base64) missing")
+		}
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		dir := t.TempDir()
+		if _, err := NewKeyring(dir); err == nil {
+			t.Error("empty directory: want an error")
+		}
+		if err := os.WriteFile(filepath.Join(dir, "bad"), []byte("not a key"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewKeyring(dir); err == nil {
+			t.Error("bad key file: want an error")
+		}
+	})
+}
+
+func TestKeyringReloadKeepsPreviousKeys(t *testing.T) {
+	pub1, _, _ := ed25519.GenerateKey(nil)
+	pub2, _, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	writeKey(t, dir, "k1", pub1)
+	kr, err := NewKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "k2"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := kr.Reload(); err == nil {
+		t.Fatal("reload of a broken key file: want an error")
+	}
+	if _, ok := kr.Get("k1"); !ok || kr.Len() != 1 {
+		t.Fatal("previous keys lost after a failed reload")
+	}
+
+	writeKey(t, dir, "k2", pub2)
+	if err := kr.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if k, ok := kr.Get("k2"); !ok || !k.Equal(pub2) {
+		t.Error("fixed key not loaded")
+	}
+}
+
+func TestKeyringWatch(t *testing.T) {
+	pub1, _, _ := ed25519.GenerateKey(nil)
+	pub2, _, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	writeKey(t, dir, "k1", pub1)
+	kr, err := NewKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := kr.Watch(stop); err != nil {
+		t.Fatal(err)
+	}
+
+	// a broken file is ignored: the previous keys stay
+	if err := os.WriteFile(filepath.Join(dir, "k2"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(800 * time.Millisecond) // past the reload debounce
+	if _, ok := kr.Get("k1"); !ok || kr.Len() != 1 {
+		t.Fatal("previous keys lost after a broken key file")
+	}
+
+	// fixing it is picked up
+	writeKey(t, dir, "k2", pub2)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if k, ok := kr.Get("k2"); ok && bytes.Equal(k, pub2) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watch did not reload the fixed key")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+package paseto
+
+import (
+	"sync/atomic"
+	"time"
+
+	"service/internal/conf/v1"
+)
+
+// Policy is the claim validation policy applied after signature verification.
+type Policy struct {
+	Leeway         time.Duration // clock skew tolerance for exp/nbf/iat
+	Issuer         string        // expected "iss" (empty = not checked)
+	Audience       []string      // accepted "aud" values (empty = not checked)
+	RequiredClaims []string      // claims that must be present (json names)
+	Type           string        // expected "type" claim
+	MaxAge         time.Duration // maximum age since "iat" (0 = not checked)
+}
+
+// DefaultPolicy keeps the historical behaviour: access tokens, exp only.
+var DefaultPolicy = Policy{Type: "access"}
+
+var currentPolicy atomic.Pointer[Policy]
+
+// Init sets the validation policy from config (call once at startup).
+func Init(c *conf.Auth) {
+	p := PolicyFromConf(c)
+	currentPolicy.Store(&p)
+}
+
+// PolicyFromConf builds a Policy from the "auth.token" config section.
+func PolicyFromConf(c *conf.Auth) Policy {
+	p := DefaultPolicy
+	t := c.GetToken()
+	if t == nil {
+		return p
+	}
+	p.Leeway = t.GetLeeway().AsDuration()
+	p.Issuer = t.GetIssuer()
+	p.Audience = t.GetAudience()
+	p.RequiredClaims = t.GetRequiredClaims()
+	if t.GetType() != "" {
+		p.Type = t.GetType()
+	}
+	p.MaxAge = t.GetMaxAge().AsDuration()
+	return p
+}
+
+func policy() *Policy {
+	if p := currentPolicy.Load(); p != nil {
+		return p
+	}
+	return &DefaultPolicy
+}
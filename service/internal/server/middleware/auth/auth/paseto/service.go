@@ -4,30 +4,62 @@ import (
 	"os"
 	"sync"
 
+	"service/pkg/utils"
+
 	"github.com/o1egl/paseto"
 )
 
 type Validator struct {
-	secret []byte
+	secret []byte   // v2.local (symmetric), optional
+	keys   *Keyring // v2.public / v4.public (asymmetric), optional
 	paseto *paseto.V2
 }
 
 var (
 	validatorInstance *Validator
 	onceValidator     sync.Once
+
+	stopWatch = make(chan struct{}) // closed by Close
+	onceClose sync.Once
 )
 
-// NewValidator creates a new validator
+// Close stops reloading PASETO_PUBLIC_KEYS on change (call on shutdown).
+func Close() {
+	onceClose.Do(func() { close(stopWatch) })
+}
+
+// NewValidator creates a new validator.
+//   - SK_PASETO: 32-byte secret, enables v2.local tokens;
+//   - PASETO_PUBLIC_KEYS: file or directory with Ed25519 public keys, enables
+//     v2.public / v4.public tokens (key chosen by "kid" in the footer, reloaded on change).
+//
+// At least one of them must be set.
 func NewValidator() *Validator {
 	onceValidator.Do(func() {
-		secret := []byte(os.Getenv("SK_PASETO"))
-		if len(secret) != 32 {
-			panic("SK_PASETO must be exactly 32 bytes for V2.Local")
+		v := &Validator{paseto: paseto.NewV2()}
+
+		if secret := os.Getenv("SK_PASETO"); secret != "" {
+			if len(secret) != 32 {
+				panic("SK_PASETO must be exactly 32 bytes for V2.Local")
+			}
+			v.secret = []byte(secret)
+		}
+
+		if path := utils.EnvFirst("PASETO_PUBLIC_KEYS"); path != "" {
+			kr, err := NewKeyring(path)
+			if err != nil {
+				panic("PASETO_PUBLIC_KEYS: " + err.Error())
+			}
+			if err := kr.Watch(stopWatch); err != nil {
+				panic("PASETO_PUBLIC_KEYS: watch: " + err.Error())
+			}
+			v.keys = kr
 		}
-		validatorInstance = &Validator{
-			secret: secret,
-			paseto: paseto.NewV2(),
+
+		if v.secret == nil && v.keys == nil {
+			panic("SK_PASETO or PASETO_PUBLIC_KEYS must be set")
 		}
+		validatorInstance = v
 	})
 	return validatorInstance
 }
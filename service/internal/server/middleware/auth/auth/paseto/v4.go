@@ -0,0 +1,87 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// v4.public is not implemented by o1egl/paseto, so verification is done here.
+// Spec: https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md
+
+const (
+	headerV2Local  = "v2.local."
+	headerV2Public = "v2.public."
+	headerV4Public = "v4.public."
+)
+
+var errMalformedToken = errors.New("malformed token")
+
+// verifyV4Public checks the Ed25519 signature of a v4.public token (no implicit assertion)
+// and returns the raw payload.
+func verifyV4Public(token string, pub ed25519.PublicKey) ([]byte, error) {
+	body, footer, err := splitPublicToken(token, headerV4Public)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, errMalformedToken
+	}
+	msg := body[:len(body)-ed25519.SignatureSize]
+	sig := body[len(body)-ed25519.SignatureSize:]
+
+	if !ed25519.Verify(pub, pae([]byte(headerV4Public), msg, footer, nil), sig) {
+		return nil, ErrInvalidSignature
+	}
+	return msg, nil
+}
+
+// splitPublicToken decodes "<header><body>[.<footer>]".
+func splitPublicToken(token, header string) (body, footer []byte, err error) {
+	if !strings.HasPrefix(token, header) {
+		return nil, nil, errMalformedToken
+	}
+	parts := strings.Split(strings.TrimPrefix(token, header), ".")
+	if len(parts) > 2 {
+		return nil, nil, errMalformedToken
+	}
+	if body, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+		return nil, nil, errMalformedToken
+	}
+	if len(parts) == 2 {
+		if footer, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			return nil, nil, errMalformedToken
+		}
+	}
+	return body, footer, nil
+}
+
+// tokenFooter returns the decoded footer of any PASETO token (empty if absent).
+func tokenFooter(token string) []byte {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// pae is PASETO Pre-Authentication Encoding.
+func pae(pieces ...[]byte) []byte {
+	le64 := func(n int) []byte {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(n)&^(1<<63))
+		return b
+	}
+	out := le64(len(pieces))
+	for _, p := range pieces {
+		out = append(out, le64(len(p))...)
+		out = append(out, p...)
+	}
+	return out
+}
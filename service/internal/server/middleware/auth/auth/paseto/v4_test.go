@@ -0,0 +1,101 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Official test vector 4-S-1 (paseto-spec docs/../test-vectors/v4.json).
+const (
+	vectorPublicKey = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	vectorSecretKey = "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" + vectorPublicKey
+	vector4S1       = "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+	vectorPayload   = `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+)
+
+func hexKey(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// signV4 builds a v4.public token (no implicit assertion).
+func signV4(priv ed25519.PrivateKey, payload, footer []byte) string {
+	sig := ed25519.Sign(priv, pae([]byte(headerV4Public), payload, footer, nil))
+	tok := headerV4Public + base64.RawURLEncoding.EncodeToString(append(append([]byte{}, payload...), sig...))
+	if len(footer) > 0 {
+		tok += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return tok
+}
+
+func TestPAE(t *testing.T) {
+	// examples of the PASETO spec (Common.md, PAE)
+	tests := []struct {
+		pieces [][]byte
+		want   string
+	}{
+		{nil, "0000000000000000"},
+		{[][]byte{{}}, "01000000000000000000000000000000"},
+		{[][]byte{{}, {}}, "020000000000000000000000000000000000000000000000"},
+		{[][]byte{[]byte("test")}, "0100000000000000040000000000000074657374"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(pae(tt.pieces...)); got != tt.want {
+			t.Errorf("pae(%q) = %s, want %s", tt.pieces, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyV4PublicVector(t *testing.T) {
+	pub := ed25519.PublicKey(hexKey(t, vectorPublicKey))
+	priv := ed25519.PrivateKey(hexKey(t, vectorSecretKey))
+
+	payload, err := verifyV4Public(vector4S1, pub)
+	if err != nil {
+		t.Fatalf("4-S-1: %v", err)
+	}
+	if string(payload) != vectorPayload {
+		t.Errorf("4-S-1 payload = %s", payload)
+	}
+	if got := signV4(priv, []byte(vectorPayload), nil); got != vector4S1 {
+		t.Errorf("signV4 = %s, want 4-S-1", got)
+	}
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	withFooter := signV4(priv, []byte(vectorPayload), []byte(`{"kid":"k1"}`))
+	body := withFooter[:strings.LastIndexByte(withFooter, '.')]
+	raw, _ := base64.RawURLEncoding.DecodeString(vector4S1[len(headerV4Public):])
+	raw[10] ^= 1 // "data" -> "dbta"
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"footer", withFooter, nil},
+		{"footer added", vector4S1 + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"k1"}`)), ErrInvalidSignature},
+		{"footer changed", body + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"k2"}`)), ErrInvalidSignature},
+		{"footer removed", body, ErrInvalidSignature},
+		{"payload changed", headerV4Public + base64.RawURLEncoding.EncodeToString(raw), ErrInvalidSignature},
+		{"other key", signV4(other, []byte(vectorPayload), nil), ErrInvalidSignature},
+		{"v2 header", "v2.public." + vector4S1[len(headerV4Public):], errMalformedToken},
+		{"too short", headerV4Public + "AAAA", errMalformedToken},
+		{"bad base64", headerV4Public + "!!!", errMalformedToken},
+		{"extra part", vector4S1 + ".e30.e30", errMalformedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifyV4Public(tt.token, pub); !errors.Is(err, tt.want) {
+				t.Errorf("verifyV4Public() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
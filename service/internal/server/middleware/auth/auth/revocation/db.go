@@ -0,0 +1,54 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"service/internal/data"
+	"service/internal/data/model"
+)
+
+// dbStore persists entries in "revoked_tokens" through data.Data.
+type dbStore struct {
+	data *data.Data
+}
+
+func (s *dbStore) insert(ctx context.Context, e Entry) error {
+	po := model.RevokedTokens{
+		JTI:       e.JTI,
+		Username:  e.Username,
+		Iat:       e.Iat,
+		Reason:    e.Reason,
+		RevokedAt: time.Unix(e.RevokedAt, 0),
+		ExpiresAt: time.Unix(e.ExpiresAt, 0),
+	}
+	return s.data.DB(ctx).WithContext(ctx).Create(&po).Error
+}
+
+func (s *dbStore) active(ctx context.Context, now time.Time) ([]Entry, error) {
+	var rows []model.RevokedTokens
+	if err := s.data.DB(ctx).WithContext(ctx).
+		Where("expires_at >= ?", now).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, Entry{
+			JTI:       r.JTI,
+			Username:  r.Username,
+			Iat:       r.Iat,
+			Reason:    r.Reason,
+			RevokedAt: r.RevokedAt.Unix(),
+			ExpiresAt: r.ExpiresAt.Unix(),
+		})
+	}
+	return out, nil
+}
+
+func (s *dbStore) deleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := s.data.DB(ctx).WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&model.RevokedTokens{})
+	return res.RowsAffected, res.Error
+}
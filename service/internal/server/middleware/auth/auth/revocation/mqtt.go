@@ -0,0 +1,44 @@
+package revocation
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	mymqtt "service/pkg/mqtt"
+)
+
+// publish broadcasts a new entry to other replicas (no-op without topic/broker).
+func (r *Revocation) publish(e Entry) {
+	if r.topic == "" {
+		return
+	}
+	m := mymqtt.GetMosquitero()
+	if m == nil {
+		return
+	}
+	if err := m.SendJSON(r.topic, e); err != nil {
+		r.log.Warnf("[REVOCATION] publish error: %v", err)
+	}
+}
+
+// HandleMessage applies an entry received on the revocation topic.
+func (r *Revocation) HandleMessage(payload []byte) {
+	var e Entry
+	if err := json.Unmarshal(payload, &e); err != nil {
+		r.log.Warnf("[REVOCATION] bad message: %v", err)
+		return
+	}
+	e.JTI = strings.TrimSpace(e.JTI)
+	e.Username = strings.TrimSpace(e.Username)
+	if e.JTI == "" && e.Username == "" {
+		r.log.Warnf("[REVOCATION] bad message: %v", ErrEmptyEntry)
+		return
+	}
+	if err := r.check(e, time.Now()); err != nil {
+		r.log.Warnf("[REVOCATION] bad message: %v (jti=%q username=%q revoked_at=%d expires_at=%d)",
+			err, e.JTI, e.Username, e.RevokedAt, e.ExpiresAt)
+		return
+	}
+	r.mem.add(e)
+}
@@ -0,0 +1,261 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"service/internal/conf/v1"
+	"service/internal/data"
+	"service/internal/server/middleware/auth/auth/authn"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+/*
+   Revocation: denylist checked by authn for every authenticated principal
+   (PASETO, JWT, API keys): JTI against Principal.ID, Username/Iat against
+   Principal.Subject/IssuedAt.
+
+   - The in-memory set is what every request is checked against (no I/O).
+   - If the database is active, entries are persisted and re-synced periodically,
+     so every replica (and restarts) see the same list.
+   - If an MQTT topic is configured, new entries are published and received
+     from other replicas within seconds.
+   - Expired entries (ExpiresAt < now) are removed periodically.
+   - A single token (JTI or Username+Iat) without "exp" is kept for the
+     configured token lifetime (auth.token.max_age); without one its expiry
+     is unknown and the entry is rejected.
+*/
+
+const (
+	defaultTTL          = 24 * time.Hour
+	defaultCleanupEvery = 10 * time.Minute
+	maxEntryTTL         = 30 * 24 * time.Hour // upper bound for "expires_at" (unless a configured lifetime is longer)
+	maxClockSkew        = time.Minute         // tolerated "revoked_at" ahead of local time
+)
+
+var (
+	ErrEmptyEntry     = errors.New("jti or username is required")
+	ErrMissingExpiry  = errors.New("exp is required to revoke a single token (auth.token.max_age is not set)")
+	ErrInvalidExpiry  = errors.New("expires_at is in the past or too far in the future")
+	ErrInvalidRevoked = errors.New("revoked_at is in the future")
+)
+
+// Entry revokes:
+//   - a single token by JTI;
+//   - a single token by (Username, Iat);
+//   - every token of Username issued at or before RevokedAt (Iat == 0).
+type Entry struct {
+	JTI       string `json:"jti,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	RevokedAt int64  `json:"revoked_at"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type Revocation struct {
+	mem *memStore
+	db  *dbStore // nil if database is not active
+
+	topic        string
+	defaultTTL   time.Duration // username cut-offs without exp
+	maxLifetime  time.Duration // token max_age + leeway (0 = unknown)
+	maxTTL       time.Duration
+	cleanupEvery time.Duration
+	syncEvery    time.Duration
+	adminRoles   []string
+
+	log *log.Helper
+}
+
+// NewRevocation creates the denylist and installs it into the authn chain.
+// Returns nil when revocation is not active.
+func NewRevocation(c *conf.Auth, d *data.Data, logger log.Logger) (*Revocation, func(), error) {
+	h := log.NewHelper(logger)
+	rc := c.GetRevocation()
+	if rc == nil || !rc.GetActive() {
+		h.Infof("[REVOCATION] [SKIPPED] Token revocation is disabled")
+		return nil, func() {}, nil
+	}
+
+	r := &Revocation{
+		mem:          newMemStore(),
+		topic:        strings.TrimSpace(rc.GetMqttTopic()),
+		defaultTTL:   durationOr(rc.GetDefaultTtl().AsDuration(), defaultTTL),
+		cleanupEvery: durationOr(rc.GetCleanupEvery().AsDuration(), defaultCleanupEvery),
+		syncEvery:    rc.GetSyncEvery().AsDuration(),
+		adminRoles:   rc.GetAdminRoles(),
+		log:          h,
+	}
+	if tc := c.GetToken(); tc.GetMaxAge().AsDuration() > 0 {
+		r.maxLifetime = tc.GetMaxAge().AsDuration() + tc.GetLeeway().AsDuration()
+	}
+	r.maxTTL = maxDuration(maxEntryTTL, r.defaultTTL, r.maxLifetime)
+	if d != nil {
+		r.db = &dbStore{data: d}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.sync(ctx)
+	go r.loop(ctx)
+
+	authn.UseRevoker(r)
+	h.Infof("[REVOCATION] denylist active (database: %t, mqtt topic: %q)", r.db != nil, r.topic)
+
+	return r, func() {
+		cancel()
+		authn.UseRevoker(nil)
+	}, nil
+}
+
+// Revoke adds an entry (persisting and broadcasting it when configured).
+func (r *Revocation) Revoke(ctx context.Context, e Entry) (Entry, error) {
+	e.JTI = strings.TrimSpace(e.JTI)
+	e.Username = strings.TrimSpace(e.Username)
+	if e.JTI == "" && e.Username == "" {
+		return e, ErrEmptyEntry
+	}
+	now := time.Now()
+	if e.RevokedAt == 0 {
+		e.RevokedAt = now.Unix()
+	}
+	if e.ExpiresAt == 0 {
+		ttl, err := r.ttl(e)
+		if err != nil {
+			return e, err
+		}
+		e.ExpiresAt = now.Add(ttl).Unix()
+	}
+	if err := r.check(e, now); err != nil {
+		return e, err
+	}
+
+	if r.db != nil {
+		if err := r.db.insert(ctx, e); err != nil {
+			return e, err
+		}
+	}
+	r.mem.add(e)
+	r.publish(e)
+
+	r.log.Infof("[REVOCATION] revoked: jti=%q username=%q iat=%d reason=%q", e.JTI, e.Username, e.Iat, e.Reason)
+	return e, nil
+}
+
+// Revoked implements authn.Revoker.
+func (r *Revocation) Revoked(p *authn.Principal) bool {
+	return r.mem.revoked(p.ID, p.Subject, p.IssuedAt)
+}
+
+// Len returns the number of active entries.
+func (r *Revocation) Len() int { return r.mem.len() }
+
+// AdminRoles returns roles allowed to revoke tokens.
+func (r *Revocation) AdminRoles() []string { return r.adminRoles }
+
+// Topic returns the MQTT topic for revocations ("" = none).
+func (r *Revocation) Topic() string {
+	if r == nil {
+		return ""
+	}
+	return r.topic
+}
+
+// ttl returns how long an entry without exp must be kept: any token alive now
+// expires within the configured lifetime; a username cut-off falls back to
+// default_ttl.
+func (r *Revocation) ttl(e Entry) (time.Duration, error) {
+	switch {
+	case r.maxLifetime > 0:
+		return maxDuration(r.maxLifetime, r.defaultTTL), nil
+	case e.JTI != "" || e.Iat != 0:
+		return 0, ErrMissingExpiry
+	default:
+		return r.defaultTTL, nil
+	}
+}
+
+// check rejects entries that would be swept at once or never expire.
+func (r *Revocation) check(e Entry, now time.Time) error {
+	if e.ExpiresAt <= now.Unix() || e.ExpiresAt > now.Add(r.maxTTL).Unix() {
+		return ErrInvalidExpiry
+	}
+	if e.RevokedAt <= 0 || e.RevokedAt > now.Add(maxClockSkew).Unix() {
+		return ErrInvalidRevoked
+	}
+	return nil
+}
+
+func (r *Revocation) loop(ctx context.Context) {
+	cleanup := time.NewTicker(r.cleanupEvery)
+	defer cleanup.Stop()
+
+	var syncC <-chan time.Time
+	if r.db != nil && r.syncEvery > 0 {
+		t := time.NewTicker(r.syncEvery)
+		defer t.Stop()
+		syncC = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		case <-syncC:
+			r.sync(ctx)
+		}
+	}
+}
+
+// sync loads non-expired entries from the database.
+func (r *Revocation) sync(ctx context.Context) {
+	if r.db == nil {
+		return
+	}
+	entries, err := r.db.active(ctx, time.Now())
+	if err != nil {
+		r.log.Errorf("[REVOCATION] sync error: %v", err)
+		return
+	}
+	for _, e := range entries {
+		r.mem.add(e)
+	}
+}
+
+// cleanup removes expired entries from memory and database.
+func (r *Revocation) cleanup(ctx context.Context) {
+	now := time.Now()
+	n := r.mem.cleanup(now.Unix())
+	if r.db != nil {
+		deleted, err := r.db.deleteExpired(ctx, now)
+		if err != nil {
+			r.log.Errorf("[REVOCATION] cleanup error: %v", err)
+		}
+		n += int(deleted)
+	}
+	if n > 0 {
+		r.log.Debugf("[REVOCATION] cleanup: %d expired entries removed", n)
+	}
+}
+
+func maxDuration(ds ...time.Duration) time.Duration {
+	var m time.Duration
+	for _, d := range ds {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
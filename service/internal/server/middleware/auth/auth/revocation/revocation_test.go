@@ -0,0 +1,189 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"service/internal/server/middleware/auth/auth/authn"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func newTestRevocation(maxLifetime time.Duration) *Revocation {
+	r := &Revocation{
+		mem:         newMemStore(),
+		defaultTTL:  defaultTTL,
+		maxLifetime: maxLifetime,
+		log:         log.NewHelper(log.DefaultLogger),
+	}
+	r.maxTTL = maxDuration(maxEntryTTL, r.defaultTTL, r.maxLifetime)
+	return r
+}
+
+func TestMemStore(t *testing.T) {
+	s := newMemStore()
+	s.add(Entry{JTI: "j1", ExpiresAt: 100})
+	s.add(Entry{Username: "ana", Iat: 10, ExpiresAt: 100})
+	s.add(Entry{Username: "bob", RevokedAt: 50, ExpiresAt: 200})
+
+	tests := []struct {
+		name     string
+		jti      string
+		username string
+		iat      int64
+		want     bool
+	}{
+		{"jti", "j1", "", 0, true},
+		{"other jti", "j2", "", 0, false},
+		{"username and iat", "", "ana", 10, true},
+		{"same username other iat", "", "ana", 11, false},
+		{"cut-off before", "", "bob", 40, true},
+		{"cut-off at", "", "bob", 50, true},
+		{"issued after cut-off", "", "bob", 51, false},
+		{"unknown user", "", "carl", 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.revoked(tt.jti, tt.username, tt.iat); got != tt.want {
+				t.Fatalf("revoked(%q, %q, %d) = %t, want %t", tt.jti, tt.username, tt.iat, got, tt.want)
+			}
+		})
+	}
+
+	// A later entry never shortens the expiry of an earlier one.
+	s.add(Entry{JTI: "j1", ExpiresAt: 50})
+	if s.jti["j1"] != 100 {
+		t.Fatalf("jti expiry = %d, want 100", s.jti["j1"])
+	}
+}
+
+func TestMemStoreCleanup(t *testing.T) {
+	s := newMemStore()
+	s.add(Entry{JTI: "j1", ExpiresAt: 100})
+	s.add(Entry{Username: "ana", Iat: 10, ExpiresAt: 100})
+	s.add(Entry{Username: "bob", RevokedAt: 50, ExpiresAt: 200})
+
+	if n := s.cleanup(100); n != 0 {
+		t.Fatalf("cleanup(100) = %d, want 0 (entries are kept through their expiry second)", n)
+	}
+	if n := s.cleanup(101); n != 2 {
+		t.Fatalf("cleanup(101) = %d, want 2", n)
+	}
+	if s.revoked("j1", "", 0) || s.revoked("", "ana", 10) {
+		t.Fatal("expired entries still revoke")
+	}
+	if !s.revoked("", "bob", 40) || s.len() != 1 {
+		t.Fatalf("cut-off removed early (len %d)", s.len())
+	}
+	if n := s.cleanup(201); n != 1 || s.len() != 0 {
+		t.Fatalf("cleanup(201) = %d, len %d; want 1, 0", n, s.len())
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name        string
+		maxLifetime time.Duration
+		entry       Entry
+		wantErr     error
+		wantTTL     time.Duration // expected ExpiresAt - now when entry has no exp
+	}{
+		{"empty", 0, Entry{JTI: "  "}, ErrEmptyEntry, 0},
+		{"jti without exp nor lifetime", 0, Entry{JTI: "j1"}, ErrMissingExpiry, 0},
+		{"pair without exp nor lifetime", 0, Entry{Username: "ana", Iat: now}, ErrMissingExpiry, 0},
+		{"jti uses lifetime", 72 * time.Hour, Entry{JTI: "j1"}, nil, 72 * time.Hour},
+		{"lifetime shorter than default", time.Hour, Entry{JTI: "j1"}, nil, defaultTTL},
+		{"cut-off uses default", 0, Entry{Username: "ana"}, nil, defaultTTL},
+		{"explicit exp", 0, Entry{JTI: "j1", ExpiresAt: now + 60}, nil, 60 * time.Second},
+		{"exp in the past", 0, Entry{JTI: "j1", ExpiresAt: now - 1}, ErrInvalidExpiry, 0},
+		{"exp too far", 0, Entry{JTI: "j1", ExpiresAt: now + int64(maxEntryTTL/time.Second) + 60}, ErrInvalidExpiry, 0},
+		{"revoked in the future", 0, Entry{Username: "ana", RevokedAt: now + 3600}, ErrInvalidRevoked, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRevocation(tt.maxLifetime)
+			e, err := r.Revoke(ctx, tt.entry)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if r.Len() != 0 {
+					t.Fatalf("rejected entry stored (len %d)", r.Len())
+				}
+				return
+			}
+			if d := e.ExpiresAt - now - int64(tt.wantTTL/time.Second); d < 0 || d > 1 {
+				t.Fatalf("ExpiresAt = now%+d, want now+%d", e.ExpiresAt-now, int64(tt.wantTTL/time.Second))
+			}
+			if e.RevokedAt == 0 || r.Len() != 1 {
+				t.Fatalf("entry not stored: %+v (len %d)", e, r.Len())
+			}
+		})
+	}
+}
+
+func TestRevoked(t *testing.T) {
+	r := newTestRevocation(time.Hour)
+	if _, err := r.Revoke(context.Background(), Entry{Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	if !r.Revoked(&authn.Principal{Subject: "ana", IssuedAt: now - 60}) {
+		t.Fatal("token issued before the cut-off is not revoked")
+	}
+	if r.Revoked(&authn.Principal{Subject: "ana", IssuedAt: now + 60}) {
+		t.Fatal("token issued after the cut-off is revoked")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	r := newTestRevocation(0)
+	now := time.Now().Unix()
+	r.mem.add(Entry{JTI: "old", ExpiresAt: now - 10})
+	r.mem.add(Entry{JTI: "new", ExpiresAt: now + 3600})
+
+	r.cleanup(context.Background())
+
+	if r.Len() != 1 || !r.Revoked(&authn.Principal{ID: "new"}) {
+		t.Fatalf("cleanup kept %d entries, want only the active one", r.Len())
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	now := time.Now().Unix()
+	tests := []struct {
+		name    string
+		payload string
+		entry   *Entry
+		want    bool
+	}{
+		{"invalid json", "{", nil, false},
+		{"empty entry", "", &Entry{RevokedAt: now, ExpiresAt: now + 60}, false},
+		{"valid", "", &Entry{JTI: "j1", RevokedAt: now, ExpiresAt: now + 60}, true},
+		{"zero expires_at", "", &Entry{JTI: "j1", RevokedAt: now}, false},
+		{"expired", "", &Entry{JTI: "j1", RevokedAt: now - 120, ExpiresAt: now - 60}, false},
+		{"huge expires_at", "", &Entry{JTI: "j1", RevokedAt: now, ExpiresAt: 1 << 62}, false},
+		{"zero revoked_at", "", &Entry{Username: "ana", ExpiresAt: now + 60}, false},
+		{"revoked_at in the future", "", &Entry{Username: "ana", RevokedAt: now + 3600, ExpiresAt: now + 7200}, false},
+		{"small clock skew", "", &Entry{Username: "ana", RevokedAt: now + 5, ExpiresAt: now + 60}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := []byte(tt.payload)
+			if tt.entry != nil {
+				payload, _ = json.Marshal(tt.entry)
+			}
+			r := newTestRevocation(0)
+			r.HandleMessage(payload)
+			if got := r.Len() == 1; got != tt.want {
+				t.Fatalf("entry applied = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package revocation
+
+import (
+	"strconv"
+	"sync"
+)
+
+// memStore is the in-memory denylist (jti / username+iat / username cut-off).
+type memStore struct {
+	mu    sync.RWMutex
+	jti   map[string]int64      // jti -> expiresAt
+	pairs map[string]int64      // username|iat -> expiresAt
+	users map[string]userCutoff // username -> every token with iat <= before
+}
+
+type userCutoff struct {
+	before    int64
+	expiresAt int64
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		jti:   make(map[string]int64),
+		pairs: make(map[string]int64),
+		users: make(map[string]userCutoff),
+	}
+}
+
+func pairKey(username string, iat int64) string {
+	return username + "|" + strconv.FormatInt(iat, 10)
+}
+
+func (s *memStore) add(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case e.JTI != "":
+		s.jti[e.JTI] = maxInt64(s.jti[e.JTI], e.ExpiresAt)
+	case e.Iat != 0:
+		k := pairKey(e.Username, e.Iat)
+		s.pairs[k] = maxInt64(s.pairs[k], e.ExpiresAt)
+	default:
+		cur := s.users[e.Username]
+		s.users[e.Username] = userCutoff{
+			before:    maxInt64(cur.before, e.RevokedAt),
+			expiresAt: maxInt64(cur.expiresAt, e.ExpiresAt),
+		}
+	}
+}
+
+func (s *memStore) revoked(jti, username string, iat int64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if jti != "" {
+		if _, ok := s.jti[jti]; ok {
+			return true
+		}
+	}
+	if username == "" {
+		return false
+	}
+	if _, ok := s.pairs[pairKey(username, iat)]; ok {
+		return true
+	}
+	if cut, ok := s.users[username]; ok && iat <= cut.before {
+		return true
+	}
+	return false
+}
+
+// cleanup removes entries expired at now (unix seconds).
+func (s *memStore) cleanup(now int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for k, exp := range s.jti {
+		if exp < now {
+			delete(s.jti, k)
+			n++
+		}
+	}
+	for k, exp := range s.pairs {
+		if exp < now {
+			delete(s.pairs, k)
+			n++
+		}
+	}
+	for k, cut := range s.users {
+		if cut.expiresAt < now {
+			delete(s.users, k)
+			n++
+		}
+	}
+	return n
+}
+
+func (s *memStore) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.jti) + len(s.pairs) + len(s.users)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,5 @@
+package revocation
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewRevocation)
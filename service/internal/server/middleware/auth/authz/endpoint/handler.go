@@ -0,0 +1,46 @@
+package endpoint
+
+import (
+	stdhttp "net/http"
+	"strings"
+
+	http_errors "service/internal/server/http/middleware/errors"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// RequireRoles protects a plain HTTP handler (srv.HandleFunc) with the same
+// token/role check as RoleMiddleware. Errors are encoded like Kratos handlers.
+// Without roles every request is denied: an admin endpoint never falls back
+// to "any authenticated caller".
+func RequireRoles(requiredRoles []string, h stdhttp.HandlerFunc) stdhttp.HandlerFunc {
+	if !HasRoles(requiredRoles) {
+		return func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+			khttp.DefaultErrorEncoder(w, r, http_errors.Forbidden(ReasonAuthz, "no roles allowed",
+				http_errors.Fields{"reason": "NO_ADMIN_ROLES"}))
+		}
+	}
+	return Require(AnyOf(requiredRoles...), h)
+}
+
+// HasRoles reports whether roles has a non-blank entry.
+func HasRoles(roles []string) bool {
+	for _, r := range roles {
+		if strings.TrimSpace(r) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Require protects a plain HTTP handler with a role requirement (see RequireMiddleware).
+func Require(require Requirement, h stdhttp.HandlerFunc) stdhttp.HandlerFunc {
+	return func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		ctx, err := authorize(r.Context(), require)
+		if err != nil {
+			khttp.DefaultErrorEncoder(w, r, err)
+			return
+		}
+		h(w, r.WithContext(ctx))
+	}
+}
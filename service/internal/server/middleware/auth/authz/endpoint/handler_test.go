@@ -0,0 +1,23 @@
+package endpoint
+
+import (
+	stdhttp "net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireRolesWithoutRolesDenies(t *testing.T) {
+	for _, roles := range [][]string{nil, {}, {"", "  "}} {
+		called := false
+		h := RequireRoles(roles, func(w stdhttp.ResponseWriter, r *stdhttp.Request) { called = true })
+
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(stdhttp.MethodPost, "/admin", nil))
+		if called {
+			t.Errorf("RequireRoles(%q) called the handler", roles)
+		}
+		if w.Code != stdhttp.StatusForbidden {
+			t.Errorf("RequireRoles(%q) status = %d, want %d", roles, w.Code, stdhttp.StatusForbidden)
+		}
+	}
+}
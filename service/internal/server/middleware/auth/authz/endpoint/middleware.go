@@ -2,10 +2,15 @@ package endpoint
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 
+	"service/internal/data/tenant"
 	http_errors "service/internal/server/http/middleware/errors"
-	"service/internal/server/middleware/auth/auth/paseto"
+	"service/internal/server/middleware/auth/auth/authn"
+	"service/internal/server/middleware/headers"
+	"service/internal/server/middleware/traffic"
 	"service/pkg/logger"
 
 	"github.com/go-kratos/kratos/v2/middleware"
@@ -13,53 +18,120 @@ import (
 )
 
 // RoleMiddleware checks token and ensures user has at least one of requiredRoles.
-// Enforced ONLY for HTTP; for gRPC it's skipped.
 func RoleMiddleware(requiredRoles []string) middleware.Middleware {
+	return RequireMiddleware(AnyOf(requiredRoles...))
+}
+
+// RequireMiddleware checks token and ensures user roles satisfy require.
+// Enforced for HTTP and gRPC (unary); Kratos errors are mapped to
+// codes.Unauthenticated / codes.PermissionDenied on gRPC.
+func RequireMiddleware(require Requirement) middleware.Middleware {
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			tr, ok := transport.FromServerContext(ctx)
-			if !ok {
+			if _, ok := transport.FromServerContext(ctx); !ok {
 				return next(ctx, req)
 			}
-			// Skip for gRPC completely
-			if tr.Kind() == transport.KindGRPC {
-				return next(ctx, req)
-			}
-
-			// HTTP: read Authorization
-			token, err := GetAccessToken(ctx)
+			ctx, err := authorize(ctx, require)
 			if err != nil {
-				return nil, http_errors.Unauthorized(ReasonAuthz, err.Error(), nil)
-			}
-			if token == "" {
-				return nil, http_errors.Unauthorized(ReasonAuthz, ErrMissingAuthorizationHeader.Error(), nil)
+				return nil, err
 			}
+			return next(ctx, req)
+		}
+	}
+}
 
-			// verify token
-			claims, err := paseto.VerifyAccessToken(token)
-			if err != nil {
-				logger.Warn("RoleMiddleware: token verification failed",
-					map[string]interface{}{"error": err})
-				return nil, http_errors.Unauthorized(ReasonAuthz, fmt.Sprintf("invalid token: %v", err), nil)
-			}
+// authorize authenticates the caller (bearer token or API key) and checks roles
+// (expanded with auth.authz.role_hierarchy) against require.
+// On success it returns ctx enriched with roles/claims.
+func authorize(ctx context.Context, require Requirement) (context.Context, error) {
+	principal, token, err := authenticate(ctx)
+	if err != nil {
+		return ctx, err
+	}
 
-			// roles are CSV in claims.Roles
-			userRoles := splitCSV(claims.Roles)
-			if !HasRequiredRole(userRoles, requiredRoles) {
-				logger.Warn("RoleMiddleware: insufficient permissions",
-					map[string]interface{}{"required": requiredRoles, "got": claims.Roles})
-				return nil, http_errors.Forbidden(
-					ReasonAuthz,
-					fmt.Sprintf("insufficient permissions: required one of %v, got %v", requiredRoles, claims.Roles),
-					nil,
-				)
-			}
+	effective := EffectiveRoles(principal.Roles)
+	if !require.Allows(effective) {
+		logger.Warn("RoleMiddleware: insufficient permissions",
+			map[string]interface{}{"required": require.String(), "got": principal.Roles})
+		return ctx, http_errors.Forbidden(
+			ReasonAuthz,
+			fmt.Sprintf("insufficient permissions: required %s, got %v", require, principal.Roles),
+			nil,
+		)
+	}
+	userRoles := effective.Slice()
 
-			// put roles/claims into ctx if needed
-			ctx = context.WithValue(ctx, ctxKeyRoles, userRoles)
-			ctx = context.WithValue(ctx, ctxKeyClaims, claims)
+	// put principal/roles/claims into ctx if needed
+	ctx = context.WithValue(ctx, ctxKeyPrincipal, principal)
+	ctx = context.WithValue(ctx, ctxKeyRoles, userRoles)
+	ctx = context.WithValue(ctx, ctxKeyClaims, claimsOf(principal))
+	if token != "" {
+		ctx = context.WithValue(ctx, ctxKeyAccessToken, token)
+	}
 
-			return next(ctx, req)
-		}
+	// company of the token scopes tenant-owned models in the data layer
+	if tenant.Active() {
+		ctx = tenant.NewContext(ctx, tenant.FromClaims(principal.CompanyID, userRoles))
+	}
+
+	return ctx, nil
+}
+
+// authenticate runs the configured authenticators (auth.authenticators) on the
+// bearer token ("authorization" header/metadata) and API key ("X-Secret-Access").
+func authenticate(ctx context.Context) (*authn.Principal, string, error) {
+	cred := credentials(ctx)
+	p, err := authn.Authenticate(ctx, cred)
+	if err == nil {
+		return p, cred.Bearer, nil
+	}
+
+	var aerr *authn.Error
+	switch {
+	case errors.Is(err, authn.ErrNoCredentials):
+		return nil, "", http_errors.Unauthorized(ReasonAuthz, ErrMissingAuthorizationHeader.Error(), nil)
+	case errors.As(err, &aerr):
+		logger.Warn("RoleMiddleware: authentication failed",
+			map[string]interface{}{"authenticator": aerr.Method, "error": aerr.Err})
+		return nil, "", http_errors.Unauthorized(ReasonAuthz, fmt.Sprintf("invalid %s credentials: %v", aerr.Method, aerr.Err),
+			http_errors.Fields{"reason": aerr.Reason})
+	default:
+		return nil, "", http_errors.Unauthorized(ReasonAuthz, "unsupported credentials",
+			http_errors.Fields{"reason": "UNSUPPORTED_CREDENTIALS"})
+	}
+}
+
+func credentials(ctx context.Context) authn.Credentials {
+	token, _ := GetAccessToken(ctx)
+	return authn.Credentials{
+		Bearer: token,
+		APIKey: headers.GetSecretFromHeader(ctx),
+	}
+}
+
+// apiKeyMethod is Principal.Method of API key callers (apikey.Name; that
+// package imports this one).
+const apiKeyMethod = "apikey"
+
+// Identity returns the verified caller for keying (rate limits, quotas):
+// subject, company and API key id; zero for anonymous or invalid credentials.
+// The returned ctx carries the verification result, so the role check later
+// in the chain does not repeat it.
+func Identity(ctx context.Context) (context.Context, traffic.Subjects) {
+	cred := credentials(ctx)
+	if cred.Bearer == "" && cred.APIKey == "" {
+		return ctx, traffic.Subjects{}
+	}
+	ctx, p, err := authn.Resolve(ctx, cred)
+	if err != nil || p == nil {
+		return ctx, traffic.Subjects{}
+	}
+	s := traffic.Subjects{User: p.Subject}
+	if p.CompanyID != 0 {
+		s.Company = strconv.FormatUint(uint64(p.CompanyID), 10)
+	}
+	if p.Method == apiKeyMethod {
+		s.APIKey = p.ID
 	}
+	return ctx, s
 }
@@ -0,0 +1,60 @@
+package endpoint
+
+import (
+	"strings"
+
+	"service/internal/conf/v1"
+)
+
+// builtinPublic are services registered by Kratos itself on every gRPC server
+// (health checks, metadata, reflection, channelz admin).
+var builtinPublic = []string{
+	"/grpc.health.v1.Health/*",
+	"/kratos.api.Metadata/*",
+	"/grpc.reflection.v1.ServerReflection/*",
+	"/grpc.reflection.v1alpha.ServerReflection/*",
+	"/grpc.channelz.v1.Channelz/*",
+}
+
+// Options controls operations without (auth.v1.rule).
+type Options struct {
+	DefaultDeny bool     // reject operations without rule
+	Validate    bool     // fail boot on mismatches (see Validate)
+	Public      []string // "/pkg.Service/Method" or "/pkg.Service/*"
+}
+
+func OptionsFromConf(c *conf.Auth) Options {
+	a := c.GetAuthz()
+	o := Options{
+		DefaultDeny: a.GetDefaultDeny(),
+		Validate:    a.GetValidate(),
+	}
+	for _, op := range a.GetPublicOperations() {
+		if op = strings.TrimSpace(op); op != "" {
+			o.Public = append(o.Public, op)
+		}
+	}
+	return o
+}
+
+// isPublic reports whether op is listed as public (config or built-in).
+func (o Options) isPublic(op string) bool {
+	return matchAny(o.Public, op) || matchAny(builtinPublic, op)
+}
+
+func matchAny(patterns []string, op string) bool {
+	for _, p := range patterns {
+		if matchOperation(p, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOperation matches "/pkg.Service/Method" exactly or "/pkg.Service/*" by service.
+func matchOperation(pattern, op string) bool {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(op, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == op
+}
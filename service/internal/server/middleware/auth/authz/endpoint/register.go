@@ -2,30 +2,21 @@ package endpoint
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
+	http_errors "service/internal/server/http/middleware/errors"
 	"service/pkg/logger"
 
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc"
 )
 
-// CreateMiddleware builds a middleware that, per-method, applies RoleMiddleware.
-// Enforced ONLY for HTTP; gRPC is not enforced.
-func CreateMiddleware(groups []ServiceGroup) middleware.Middleware {
-	// map: MethodName -> required roles
-	methodRoles := make(map[string][]string)
-
-	for _, group := range groups {
-		for _, m := range group.Methods {
-			name := GetMethodName(m.Method) // e.g. "GetCompany"
-			if name == "" {
-				continue
-			}
-			logger.Debug(fmt.Sprintf("Registering roles for method %s", name), map[string]interface{}{"roles": m.RequiredRoles})
-			methodRoles[name] = m.RequiredRoles
-		}
+// CreateMiddleware builds a middleware that, per-method, applies RequireMiddleware.
+// Enforced for HTTP and gRPC unary calls; streams are covered by StreamInterceptor.
+func CreateMiddleware(groups []ServiceGroup, opts Options) (middleware.Middleware, error) {
+	methodRules, err := LoadRules(groups)
+	if err != nil {
+		return nil, err
 	}
 
 	return func(next middleware.Handler) middleware.Handler {
@@ -34,34 +25,77 @@ func CreateMiddleware(groups []ServiceGroup) middleware.Middleware {
 			if !ok {
 				return next(ctx, req)
 			}
-			// Skip for gRPC completely
-			if tr.Kind() == transport.KindGRPC {
-				return next(ctx, req)
-			}
-
-			// HTTP: figure out method name from operation
-			op := tr.Operation() // e.g. "/pkg.Service/Method" or similar
-			parts := strings.Split(op, "/")
-			methodName := parts[len(parts)-1] // "Method"
 
-			logger.Debug("Checking method", map[string]interface{}{"operation": op, "method": methodName})
-
-			roles, exists := methodRoles[methodName]
-			if !exists {
-				// fallback: maybe someone stored full op string as key
-				roles, exists = methodRoles[op]
+			// operation is "/pkg.Service/Method" for HTTP and gRPC
+			require, protected, err := decide(methodRules, opts, tr.Operation())
+			if err != nil {
+				return nil, err
 			}
-
-			if exists {
-				logger.Debug("Found roles for method", map[string]interface{}{
-					"method": methodName,
-					"roles":  roles,
-				})
-				return RoleMiddleware(roles)(next)(ctx, req)
+			if protected {
+				return RequireMiddleware(require)(next)(ctx, req)
 			}
-
-			logger.Debug("No roles found for method", map[string]interface{}{"method": methodName})
 			return next(ctx, req)
 		}
+	}, nil
+}
+
+// StreamInterceptor applies the same role table to gRPC streaming calls.
+// Kratos stream middleware runs per message, so the check is done once here,
+// before the handler starts, and the enriched context is exposed via the stream.
+func StreamInterceptor(groups []ServiceGroup, opts Options) (grpc.StreamServerInterceptor, error) {
+	methodRules, err := LoadRules(groups)
+	if err != nil {
+		return nil, err
 	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		require, protected, err := decide(methodRules, opts, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if !protected {
+			return handler(srv, ss)
+		}
+		ctx, err := authorize(ss.Context(), require)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}, nil
 }
+
+// decide resolves an operation such as "/pkg.Service/Method":
+//   - public rule or public operation: not protected;
+//   - rule: protected with its role requirement;
+//   - no rule: rejected in default-deny mode, otherwise not protected.
+func decide(methodRules map[string]Rule, opts Options, op string) (Requirement, bool, error) {
+	logger.Debug("Checking operation", map[string]interface{}{"operation": op})
+
+	rule, exists := methodRules[op]
+	switch {
+	case exists && rule.Public, opts.isPublic(op):
+		return Requirement{}, false, nil
+	case exists:
+		logger.Debug("Found rule for operation", map[string]interface{}{
+			"operation": op,
+			"require":   rule.Require.String(),
+		})
+		return rule.Require, true, nil
+	case opts.DefaultDeny:
+		logger.Warn("RoleMiddleware: operation without auth rule denied",
+			map[string]interface{}{"operation": op})
+		return Requirement{}, false, http_errors.Forbidden(ReasonAuthz, "operation has no auth rule",
+			http_errors.Fields{"reason": "NO_AUTH_RULE"})
+	}
+
+	logger.Debug("No rule found for operation", map[string]interface{}{"operation": op})
+	return Requirement{}, false, nil
+}
+
+// authStream overrides Context() so handlers see roles/claims of the caller.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,55 @@
+package endpoint
+
+import (
+	"reflect"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestDecide(t *testing.T) {
+	rules := map[string]Rule{
+		"/api.a.v1.A/Get":    {Require: AnyOf("VIEWER")},
+		"/api.a.v1.A/Login":  {Public: true},
+		"/api.a.v1.A/Delete": {Require: Requirement{AllOf: []string{"ADMIN"}, NoneOf: []string{"SUSPENDED"}}},
+	}
+	deny := Options{DefaultDeny: true, Public: []string{"/api.b.v1.B/*"}}
+	allow := Options{Public: deny.Public}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		op        string
+		require   Requirement
+		protected bool
+		denied    bool
+	}{
+		{"rule", deny, "/api.a.v1.A/Get", AnyOf("VIEWER"), true, false},
+		{"rule with all_of/none_of", deny, "/api.a.v1.A/Delete", rules["/api.a.v1.A/Delete"].Require, true, false},
+		{"public rule", deny, "/api.a.v1.A/Login", Requirement{}, false, false},
+		{"public by config", deny, "/api.b.v1.B/List", Requirement{}, false, false},
+		{"builtin health", deny, "/grpc.health.v1.Health/Check", Requirement{}, false, false},
+		{"builtin metadata", deny, "/kratos.api.Metadata/ListServices", Requirement{}, false, false},
+		{"builtin reflection", deny, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", Requirement{}, false, false},
+		{"no rule, default deny", deny, "/api.a.v1.A/List", Requirement{}, false, true},
+		{"no rule, default allow", allow, "/api.a.v1.A/List", Requirement{}, false, false},
+		{"service prefix is not a wildcard", deny, "/api.b.v1.Bx/List", Requirement{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require, protected, err := decide(rules, tt.opts, tt.op)
+			if tt.denied {
+				if e := kerrors.FromError(err); err == nil || e.Code != 403 || e.Reason != ReasonAuthz {
+					t.Fatalf("error = %v, want 403 %s", err, ReasonAuthz)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if protected != tt.protected || !reflect.DeepEqual(require, tt.require) {
+				t.Errorf("decide() = %+v, %v; want %+v, %v", require, protected, tt.require, tt.protected)
+			}
+		})
+	}
+}
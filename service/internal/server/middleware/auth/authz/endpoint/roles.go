@@ -0,0 +1,189 @@
+package endpoint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ----- requirements -----
+
+// Requirement is a role expression: any of AnyOf AND all of AllOf AND none of NoneOf.
+// Empty lists are not checked, so the zero value allows any authenticated caller.
+// Entries may end with "*" ("EXAMPLE_*"); "*" alone matches every role.
+// Wildcards are patterns of rules only: caller roles are always literal.
+type Requirement struct {
+	AnyOf  []string
+	AllOf  []string
+	NoneOf []string
+}
+
+// AnyOf returns a requirement satisfied by at least one of roles.
+func AnyOf(roles ...string) Requirement {
+	return Requirement{AnyOf: roles}
+}
+
+// AllOf returns a requirement satisfied only by all of roles together.
+func AllOf(roles ...string) Requirement {
+	return Requirement{AllOf: roles}
+}
+
+// IsZero reports whether the requirement has no role conditions.
+func (r Requirement) IsZero() bool {
+	return len(r.AnyOf) == 0 && len(r.AllOf) == 0 && len(r.NoneOf) == 0
+}
+
+// Allows reports whether roles (already expanded by the hierarchy) satisfy r.
+func (r Requirement) Allows(roles RoleSet) bool {
+	if len(r.AnyOf) > 0 && !roles.any(r.AnyOf) {
+		return false
+	}
+	for _, need := range r.AllOf {
+		if !roles.has(need) {
+			return false
+		}
+	}
+	return len(r.NoneOf) == 0 || !roles.any(r.NoneOf)
+}
+
+func (r Requirement) String() string {
+	var parts []string
+	if len(r.AnyOf) > 0 {
+		parts = append(parts, fmt.Sprintf("any of %v", r.AnyOf))
+	}
+	if len(r.AllOf) > 0 {
+		parts = append(parts, fmt.Sprintf("all of %v", r.AllOf))
+	}
+	if len(r.NoneOf) > 0 {
+		parts = append(parts, fmt.Sprintf("none of %v", r.NoneOf))
+	}
+	if len(parts) == 0 {
+		return "any role"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// ----- role sets -----
+
+// RoleSet is a normalized set of caller roles.
+type RoleSet map[string]struct{}
+
+// NewRoleSet trims roles and drops empty ones and wildcards (a caller holding
+// "*" or "EXAMPLE_*" must not satisfy every rule).
+func NewRoleSet(roles []string) RoleSet {
+	set := make(RoleSet, len(roles))
+	for _, r := range roles {
+		if r = strings.TrimSpace(r); literalRole(r) {
+			set[r] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Slice returns the roles sorted.
+func (s RoleSet) Slice() []string {
+	out := make([]string, 0, len(s))
+	for r := range s {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s RoleSet) any(patterns []string) bool {
+	for _, p := range patterns {
+		if s.has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether a role of the set matches pattern (a rule entry).
+func (s RoleSet) has(pattern string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+	if _, ok := s[pattern]; ok {
+		return true
+	}
+	for role := range s {
+		if matchRole(pattern, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRole matches role against pattern ("ADMIN", "EXAMPLE_*" or "*").
+func matchRole(pattern, role string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(role, prefix)
+	}
+	return pattern == role
+}
+
+// literalRole reports whether r can be held by a caller (not empty, no "*").
+func literalRole(r string) bool {
+	return r != "" && !strings.Contains(r, "*")
+}
+
+// ----- hierarchy -----
+
+// Hierarchy maps a role to the roles it implies, e.g. ADMIN -> EDITOR -> VIEWER.
+// Implication is transitive; cycles are tolerated.
+type Hierarchy map[string][]string
+
+// NewHierarchy parses auth.authz.role_hierarchy (role -> "IMPLIED1,IMPLIED2").
+func NewHierarchy(m map[string]string) Hierarchy {
+	h := make(Hierarchy, len(m))
+	for role, implied := range m {
+		role = strings.TrimSpace(role)
+		if !literalRole(role) {
+			continue
+		}
+		for _, r := range strings.Split(implied, ",") {
+			if r = strings.TrimSpace(r); literalRole(r) && r != role {
+				h[role] = append(h[role], r)
+			}
+		}
+	}
+	return h
+}
+
+// Expand returns roles plus every role they imply.
+func (h Hierarchy) Expand(roles []string) RoleSet {
+	set := NewRoleSet(roles)
+	if len(h) == 0 {
+		return set
+	}
+	queue := set.Slice()
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		for _, implied := range h[role] {
+			if _, seen := set[implied]; !seen {
+				set[implied] = struct{}{}
+				queue = append(queue, implied)
+			}
+		}
+	}
+	return set
+}
+
+var hierarchy atomic.Pointer[Hierarchy]
+
+// UseHierarchy sets the role hierarchy applied by every role check.
+func UseHierarchy(h Hierarchy) {
+	hierarchy.Store(&h)
+}
+
+// EffectiveRoles expands roles with the configured hierarchy.
+func EffectiveRoles(roles []string) RoleSet {
+	if h := hierarchy.Load(); h != nil {
+		return h.Expand(roles)
+	}
+	return NewRoleSet(roles)
+}
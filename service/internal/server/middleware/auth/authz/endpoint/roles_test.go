@@ -0,0 +1,63 @@
+package endpoint
+
+import "testing"
+
+func TestRequirementAllows(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   Requirement
+		roles []string
+		want  bool
+	}{
+		{"zero requirement", Requirement{}, []string{"VIEWER"}, true},
+		{"any of exact", AnyOf("ADMIN", "EDITOR"), []string{"EDITOR"}, true},
+		{"any of missing", AnyOf("ADMIN"), []string{"EDITOR"}, false},
+		{"all of", AllOf("A", "B"), []string{"A", "B", "C"}, true},
+		{"all of partial", AllOf("A", "B"), []string{"A"}, false},
+		{"none of", Requirement{NoneOf: []string{"BANNED"}}, []string{"BANNED"}, false},
+		{"rule wildcard", AnyOf("EXAMPLE_*"), []string{"EXAMPLE_READ"}, true},
+		{"rule wildcard other prefix", AnyOf("EXAMPLE_*"), []string{"OTHER_READ"}, false},
+		{"rule star", AnyOf("*"), []string{"ANY"}, true},
+		{"rule star without roles", AnyOf("*"), nil, false},
+		{"caller star", AnyOf("ADMIN"), []string{"*"}, false},
+		{"caller prefix wildcard", AllOf("EXAMPLE_WRITE"), []string{"EXAMPLE_*"}, false},
+		{"caller wildcard vs rule wildcard", AnyOf("EXAMPLE_*"), []string{"EXAMPLE_*"}, false},
+		{"caller wildcard vs none of", Requirement{NoneOf: []string{"EXAMPLE_ADMIN"}}, []string{"EXAMPLE_*"}, true},
+		{"none of wildcard", Requirement{NoneOf: []string{"GUEST_*"}}, []string{"GUEST_X"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.Allows(NewRoleSet(tt.roles)); got != tt.want {
+				t.Errorf("%s.Allows(%v) = %v, want %v", tt.req, tt.roles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHierarchyExpand(t *testing.T) {
+	h := NewHierarchy(map[string]string{
+		"ADMIN":  "EDITOR",
+		"EDITOR": "VIEWER, EDITOR",
+		"VIEWER": "ADMIN", // cycle
+		"ROOT":   "*",     // wildcards are not roles
+		"X_*":    "ADMIN",
+	})
+
+	got := h.Expand([]string{"ADMIN"}).Slice()
+	want := []string{"ADMIN", "EDITOR", "VIEWER"}
+	if len(got) != len(want) {
+		t.Fatalf("Expand(ADMIN) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expand(ADMIN) = %v, want %v", got, want)
+		}
+	}
+
+	if set := h.Expand([]string{"ROOT"}); AnyOf("ADMIN").Allows(set) {
+		t.Errorf("ROOT implies a wildcard and must not satisfy ADMIN: %v", set.Slice())
+	}
+	if set := h.Expand([]string{"X_1"}); len(set) != 1 {
+		t.Errorf("wildcard hierarchy key must be ignored: %v", set.Slice())
+	}
+}
@@ -0,0 +1,119 @@
+package endpoint
+
+import (
+	"fmt"
+
+	authv1 "service/api/auth/v1"
+	"service/pkg/logger"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Rule is the (auth.v1.rule) option of one operation.
+type Rule struct {
+	Require Requirement // roles/all_of/none_of (zero = any authenticated caller)
+	Public  bool        // no authentication at all
+}
+
+// LoadRules reads (auth.v1.rule) options of every method of the given services
+// from the registered proto descriptors.
+// Keys are full operations, e.g. "/api.example.v1.Examplev1Service/Mock".
+// Methods without the option are not included. A group whose service is not
+// registered is an error.
+func LoadRules(groups []ServiceGroup) (map[string]Rule, error) {
+	methodRules := make(map[string]Rule)
+
+	for _, group := range groups {
+		sd, err := findService(group.Service)
+		if err != nil {
+			return nil, fmt.Errorf("auth group %q: %w", group.Name, err)
+		}
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			rule, ok := ruleOf(md)
+			if !ok {
+				continue
+			}
+			op := Operation(md)
+			req := Requirement{AnyOf: rule.GetRoles(), AllOf: rule.GetAllOf(), NoneOf: rule.GetNoneOf()}
+			logger.Debug(fmt.Sprintf("Registering rule for operation %s", op),
+				map[string]interface{}{"require": req.String(), "public": rule.GetPublic()})
+			methodRules[op] = Rule{Require: req, Public: rule.GetPublic()}
+		}
+	}
+	return methodRules, nil
+}
+
+// OpenAPIRules returns the (auth.v1.rule) options of every registered method,
+// keyed by protoc-gen-openapi operationId ("Examplev1Service_Mock"), so the
+// served OpenAPI document shows the same rules the middleware enforces.
+// The operationId drops the proto package: when two services share a name
+// the id is ambiguous, it is logged and left out.
+func OpenAPIRules() map[string]Rule {
+	return openAPIRules(protoregistry.GlobalFiles)
+}
+
+func openAPIRules(files *protoregistry.Files) map[string]Rule {
+	rules := make(map[string]Rule)
+	owner := make(map[string]string) // operationId -> operation
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			sd := services.Get(i)
+			methods := sd.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				id, op := string(sd.Name())+"_"+string(md.Name()), Operation(md)
+				if other, dup := owner[id]; dup {
+					if other != "" {
+						logger.Warn(fmt.Sprintf("OpenAPI operationId %s is ambiguous, x-auth-rule left out", id),
+							map[string]interface{}{"operations": []string{other, op}})
+					}
+					owner[id] = ""
+					delete(rules, id)
+					continue
+				}
+				owner[id] = op
+				if rule, ok := ruleOf(md); ok {
+					req := Requirement{AnyOf: rule.GetRoles(), AllOf: rule.GetAllOf(), NoneOf: rule.GetNoneOf()}
+					rules[id] = Rule{Require: req, Public: rule.GetPublic()}
+				}
+			}
+		}
+		return true
+	})
+	return rules
+}
+
+// Operation returns the Kratos/gRPC operation of a method: "/pkg.Service/Method".
+func Operation(md protoreflect.MethodDescriptor) string {
+	return "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+}
+
+func findService(name string) (protoreflect.ServiceDescriptor, error) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("service %q is not registered: %w", name, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", name)
+	}
+	return sd, nil
+}
+
+func ruleOf(md protoreflect.MethodDescriptor) (*authv1.AuthRule, bool) {
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, authv1.E_Rule) {
+		return nil, false
+	}
+	rule, ok := proto.GetExtension(opts, authv1.E_Rule).(*authv1.AuthRule)
+	if !ok || rule == nil {
+		return nil, false
+	}
+	return rule, true
+}
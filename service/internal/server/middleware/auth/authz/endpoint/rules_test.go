@@ -0,0 +1,64 @@
+package endpoint
+
+import (
+	"testing"
+
+	authv1 "service/api/auth/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// serviceFile describes package pkg with service svc whose methods carry the
+// given roles (nil = no rule).
+func serviceFile(pkg, svc string, methods map[string][]string) *descriptorpb.FileDescriptorProto {
+	sd := &descriptorpb.ServiceDescriptorProto{Name: proto.String(svc)}
+	for name, roles := range methods {
+		opts := &descriptorpb.MethodOptions{}
+		if roles != nil {
+			proto.SetExtension(opts, authv1.E_Rule, &authv1.AuthRule{Roles: roles})
+		}
+		sd.Method = append(sd.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".google.protobuf.Empty"),
+			OutputType: proto.String(".google.protobuf.Empty"),
+			Options:    opts,
+		})
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String(pkg + "/service.proto"),
+		Package:    proto.String(pkg),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		Service:    []*descriptorpb.ServiceDescriptorProto{sd},
+	}
+}
+
+func TestOpenAPIRules(t *testing.T) {
+	empty := (&emptypb.Empty{}).ProtoReflect().Descriptor().ParentFile()
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(empty),
+		serviceFile("api.a.v1", "ExampleService", map[string][]string{"Get": {"VIEWER"}, "Delete": {"ADMIN"}}),
+		serviceFile("api.b.v1", "ExampleService", map[string][]string{"Get": {"OTHER"}, "List": nil}),
+		serviceFile("api.c.v1", "ItemService", map[string][]string{"Get": {"EDITOR"}}),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := openAPIRules(files)
+	if _, ok := rules["ExampleService_Get"]; ok {
+		t.Error("ambiguous ExampleService_Get kept")
+	}
+	if r := rules["ExampleService_Delete"]; len(r.Require.AnyOf) != 1 || r.Require.AnyOf[0] != "ADMIN" {
+		t.Errorf("ExampleService_Delete = %+v, want ADMIN", r)
+	}
+	if r := rules["ItemService_Get"]; len(r.Require.AnyOf) != 1 || r.Require.AnyOf[0] != "EDITOR" {
+		t.Errorf("ItemService_Get = %+v, want EDITOR", r)
+	}
+	if len(rules) != 2 {
+		t.Errorf("rules = %v, want 2 entries", rules)
+	}
+}
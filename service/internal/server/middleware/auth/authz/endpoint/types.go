@@ -2,8 +2,7 @@ package endpoint
 
 import (
 	"context"
-	"reflect"
-	"runtime"
+	"service/internal/server/middleware/auth/auth/authn"
 	"service/internal/server/middleware/auth/auth/paseto"
 	"strings"
 
@@ -13,22 +12,16 @@ import (
 
 // ----- service descriptions -----
 
-type ServiceMethod struct {
-	Service       interface{} // service instance
-	Method        interface{} // method (func)
-	RequiredRoles []string    // required roles
-}
-
+// ServiceGroup points to a proto service whose methods carry (auth.v1.rule) options.
 type ServiceGroup struct {
-	Name    string
-	Methods []ServiceMethod
+	Name    string // group label, e.g. "examplev1"
+	Service string // proto full name, e.g. "api.example.v1.Examplev1Service"
 }
 
-func NewServiceMethod(service interface{}, method interface{}, roles ...string) ServiceMethod {
-	return ServiceMethod{
-		Service:       service,
-		Method:        method,
-		RequiredRoles: roles,
+func NewServiceGroup(name, service string) ServiceGroup {
+	return ServiceGroup{
+		Name:    name,
+		Service: service,
 	}
 }
 
@@ -40,6 +33,7 @@ const (
 	ctxKeyRoles       ctxKey = "roles"
 	ctxKeyClaims      ctxKey = "claims"
 	ctxKeyAccessToken ctxKey = "access_token"
+	ctxKeyPrincipal   ctxKey = "principal"
 )
 
 // TokenFromContext returns raw token stored by middleware (without "Bearer ").
@@ -52,7 +46,8 @@ func AccessTokenFromContext(ctx context.Context) string {
 	return ""
 }
 
-// RolesFromContext returns roles previously stored by middleware.
+// RolesFromContext returns roles previously stored by middleware,
+// including roles implied by the role hierarchy.
 func RolesFromContext(ctx context.Context) []string {
 	if v := ctx.Value(ctxKeyRoles); v != nil {
 		if roles, ok := v.([]string); ok {
@@ -62,7 +57,18 @@ func RolesFromContext(ctx context.Context) []string {
 	return nil
 }
 
+// PrincipalFromContext returns the authenticated caller (any authenticator).
+func PrincipalFromContext(ctx context.Context) *authn.Principal {
+	if v := ctx.Value(ctxKeyPrincipal); v != nil {
+		if p, ok := v.(*authn.Principal); ok {
+			return p
+		}
+	}
+	return nil
+}
+
 // ClaimsFromContext returns claims previously stored by middleware.
+// For non-PASETO callers (JWT, API key) they are built from the principal.
 func ClaimsFromContext(ctx context.Context) *paseto.Claims {
 	if v := ctx.Value(ctxKeyClaims); v != nil {
 		if claims, ok := v.(*paseto.Claims); ok {
@@ -74,57 +80,10 @@ func ClaimsFromContext(ctx context.Context) *paseto.Claims {
 
 // ----- role checks -----
 
-// HasRequiredRole returns true if user has at least one of requiredRoles.
+// HasRequiredRole returns true if user has at least one of requiredRoles,
+// honoring the role hierarchy and wildcards (see Requirement).
 func HasRequiredRole(userRoles []string, requiredRoles []string) bool {
-	if len(requiredRoles) == 0 {
-		return true
-	}
-	// normalize + fast lookup
-	set := make(map[string]struct{}, len(userRoles))
-	for _, r := range userRoles {
-		r = strings.TrimSpace(r)
-		if r != "" {
-			set[r] = struct{}{}
-		}
-	}
-	for _, need := range requiredRoles {
-		if _, ok := set[strings.TrimSpace(need)]; ok {
-			return true
-		}
-	}
-	return false
-}
-
-// splitCSV splits "ROLE_1,ROLE_2" -> []string{"ROLE_1","ROLE_2"}
-func splitCSV(s string) []string {
-	if s == "" {
-		return nil
-	}
-	parts := strings.Split(s, ",")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			out = append(out, p)
-		}
-	}
-	return out
-}
-
-// GetMethodName extracts method name (e.g. "GetCompany") from a function value.
-func GetMethodName(method interface{}) string {
-	if method == nil {
-		return ""
-	}
-	v := reflect.ValueOf(method)
-	if v.Kind() != reflect.Func {
-		return ""
-	}
-	full := runtime.FuncForPC(v.Pointer()).Name()
-	// e.g. "(*company_service.CompanyService).GetCompany-fm"
-	parts := strings.Split(full, ".")
-	name := parts[len(parts)-1]
-	return strings.TrimSuffix(name, "-fm")
+	return AnyOf(requiredRoles...).Allows(EffectiveRoles(userRoles))
 }
 
 func GetAccessToken(ctx context.Context) (string, error) {
@@ -145,3 +104,19 @@ func GetAccessToken(ctx context.Context) (string, error) {
 	}
 	return "", ErrMissingAuthorizationHeader
 }
+
+// claimsOf returns PASETO claims for p (original or built from the principal).
+func claimsOf(p *authn.Principal) *paseto.Claims {
+	if c, ok := p.Details.(*paseto.Claims); ok {
+		return c
+	}
+	return &paseto.Claims{
+		Type:      p.Method,
+		CompanyID: p.CompanyID,
+		Username:  p.Subject,
+		Roles:     strings.Join(p.Roles, ","),
+		Exp:       p.ExpiresAt,
+		Iat:       p.IssuedAt,
+		Jti:       p.ID,
+	}
+}
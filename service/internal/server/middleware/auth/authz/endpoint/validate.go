@@ -0,0 +1,146 @@
+package endpoint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Report lists mismatches between served operations and the auth rule table.
+type Report struct {
+	Unprotected []string // served operations without (auth.v1.rule) and not public
+	Orphaned    []string // rules / public_operations that match no served operation
+}
+
+func (r *Report) Empty() bool {
+	return r == nil || (len(r.Unprotected) == 0 && len(r.Orphaned) == 0)
+}
+
+func (r *Report) Error() string {
+	var b strings.Builder
+	b.WriteString("auth rules do not match served operations")
+	if len(r.Unprotected) > 0 {
+		b.WriteString("\n  operations without (auth.v1.rule) (add a rule, { public: true } or list the service in auth groups):")
+		for _, op := range r.Unprotected {
+			b.WriteString("\n    - " + op)
+		}
+	}
+	if len(r.Orphaned) > 0 {
+		b.WriteString("\n  rules without a served operation (service not registered on any server?):")
+		for _, op := range r.Orphaned {
+			b.WriteString("\n    - " + op)
+		}
+	}
+	return b.String()
+}
+
+// Validate compares served operations (gRPC and HTTP) with the rules of groups
+// and the public operations of opts. The report is nil when everything matches.
+func Validate(groups []ServiceGroup, opts Options, served []string) (*Report, error) {
+	rules, err := LoadRules(groups)
+	if err != nil {
+		return nil, err
+	}
+	servedSet := make(map[string]struct{}, len(served))
+	for _, op := range served {
+		servedSet[op] = struct{}{}
+	}
+
+	r := &Report{}
+	for op := range servedSet {
+		if _, ok := rules[op]; ok || opts.isPublic(op) {
+			continue
+		}
+		r.Unprotected = append(r.Unprotected, op)
+	}
+	for op := range rules {
+		if _, ok := servedSet[op]; !ok {
+			r.Orphaned = append(r.Orphaned, op)
+		}
+	}
+	for _, p := range opts.Public {
+		if !matchServed(p, servedSet) {
+			r.Orphaned = append(r.Orphaned, p+" (auth.authz.public_operations)")
+		}
+	}
+
+	if r.Empty() {
+		return nil, nil
+	}
+	sort.Strings(r.Unprotected)
+	sort.Strings(r.Orphaned)
+	return r, nil
+}
+
+func matchServed(pattern string, served map[string]struct{}) bool {
+	for op := range served {
+		if matchOperation(pattern, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----- HTTP bindings -----
+
+var pathParam = regexp.MustCompile(`\{([^}=:]+)[=:][^}]*\}`)
+
+// HTTPRouteKey normalizes "GET" + "/v1/items/{id=*}" to "GET /v1/items/{id}".
+func HTTPRouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// HTTPBindings maps HTTPRouteKey of every (google.api.http) binding of the
+// registered proto descriptors to its operation.
+func HTTPBindings() map[string]string {
+	out := make(map[string]string)
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				opts, ok := md.Options().(*descriptorpb.MethodOptions)
+				if !ok || opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+					continue
+				}
+				rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+				if !ok || rule == nil {
+					continue
+				}
+				op := Operation(md)
+				for _, b := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+					if method, path := httpPattern(b); path != "" {
+						out[HTTPRouteKey(method, path)] = op
+					}
+				}
+			}
+		}
+		return true
+	})
+	return out
+}
+
+func httpPattern(r *annotations.HttpRule) (string, string) {
+	switch p := r.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "GET", p.Get
+	case *annotations.HttpRule_Post:
+		return "POST", p.Post
+	case *annotations.HttpRule_Put:
+		return "PUT", p.Put
+	case *annotations.HttpRule_Delete:
+		return "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		return "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
@@ -0,0 +1,73 @@
+package endpoint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "service/api/example/v1"
+)
+
+var exampleGroup = ServiceGroup{Name: "examplev1", Service: "api.example.v1.Examplev1Service"}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules([]ServiceGroup{exampleGroup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := rules["/api.example.v1.Examplev1Service/Mock"]; !ok || !r.Public {
+		t.Errorf("Mock rule = %+v, %v; want public", r, ok)
+	}
+
+	if _, err := LoadRules([]ServiceGroup{{Name: "ghost", Service: "api.ghost.v1.GhostService"}}); err == nil {
+		t.Error("unregistered service: want an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const mock = "/api.example.v1.Examplev1Service/Mock"
+
+	tests := []struct {
+		name        string
+		opts        Options
+		served      []string
+		unprotected []string
+		orphaned    []string
+	}{
+		{"all covered", Options{}, []string{mock, "/grpc.health.v1.Health/Check"}, nil, nil},
+		{"unprotected operation", Options{}, []string{mock, "/api.other.v1.Other/Do", "/api.other.v1.Other/Do"}, []string{"/api.other.v1.Other/Do"}, nil},
+		{"public by config", Options{Public: []string{"/api.other.v1.Other/*"}}, []string{mock, "/api.other.v1.Other/Do"}, nil, nil},
+		{"rule not served", Options{}, nil, nil, []string{mock}},
+		{"public entry not served", Options{Public: []string{"/api.gone.v1.Gone/*"}}, []string{mock},
+			nil, []string{"/api.gone.v1.Gone/* (auth.authz.public_operations)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Validate([]ServiceGroup{exampleGroup}, tt.opts, tt.served)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.unprotected == nil && tt.orphaned == nil {
+				if r != nil {
+					t.Fatalf("report = %v, want nil", r)
+				}
+				return
+			}
+			if r == nil {
+				t.Fatal("report = nil")
+			}
+			if !reflect.DeepEqual(r.Unprotected, tt.unprotected) || !reflect.DeepEqual(r.Orphaned, tt.orphaned) {
+				t.Errorf("report = %+v, want unprotected %v orphaned %v", r, tt.unprotected, tt.orphaned)
+			}
+			for _, op := range append(tt.unprotected, tt.orphaned...) {
+				if !strings.Contains(r.Error(), op) {
+					t.Errorf("Error() does not list %s:\n%s", op, r.Error())
+				}
+			}
+		})
+	}
+
+	if _, err := Validate([]ServiceGroup{{Name: "ghost", Service: "api.ghost.v1.GhostService"}}, Options{}, nil); err == nil {
+		t.Error("unregistered service: want an error")
+	}
+}
@@ -1,12 +1,32 @@
 package authz
 
 import (
+	"service/internal/conf/v1"
 	"service/internal/server/middleware/auth/authz/endpoint"
 
 	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/grpc"
 )
 
-// ProviderSet creates a auth middleware for HTTP server.
-func ProviderSet(groups []endpoint.ServiceGroup) middleware.Middleware {
-	return endpoint.CreateMiddleware(groups)
+// Init applies auth.authz settings shared by every role check (role hierarchy).
+func Init(c *conf.Auth) {
+	endpoint.UseHierarchy(endpoint.NewHierarchy(c.GetAuthz().GetRoleHierarchy()))
+}
+
+// ProviderSet creates a auth middleware for HTTP and gRPC (unary) servers.
+func ProviderSet(groups []endpoint.ServiceGroup, c *conf.Auth) (middleware.Middleware, error) {
+	return endpoint.CreateMiddleware(groups, endpoint.OptionsFromConf(c))
+}
+
+// StreamProviderSet creates a auth interceptor for gRPC streaming calls.
+func StreamProviderSet(groups []endpoint.ServiceGroup, c *conf.Auth) (grpc.StreamServerInterceptor, error) {
+	return endpoint.StreamInterceptor(groups, endpoint.OptionsFromConf(c))
+}
+
+// Validate checks the rule table against the served operations (gRPC and HTTP).
+// Returns the mismatch report (nil if everything matches) and whether it must fail boot.
+func Validate(groups []endpoint.ServiceGroup, c *conf.Auth, served []string) (*endpoint.Report, bool, error) {
+	opts := endpoint.OptionsFromConf(c)
+	report, err := endpoint.Validate(groups, opts, served)
+	return report, opts.Validate, err
 }
@@ -67,12 +67,18 @@ func GetRefreshTokenFromHeader(ctx context.Context) string {
 	return ""
 }
 
+// GetSecretFromHeader returns the API key ("X-Secret-Access" header or "x-secret-access" gRPC metadata)
 func GetSecretFromHeader(ctx context.Context) string {
 	if tr, ok := transport.FromServerContext(ctx); ok {
 		if htr, ok := tr.(*khttp.Transport); ok && htr.Request() != nil {
 			return htr.Request().Header.Get("X-Secret-Access")
 		}
 	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get("x-secret-access"); len(vals) > 0 {
+			return vals[0]
+		}
+	}
 	return ""
 }
 
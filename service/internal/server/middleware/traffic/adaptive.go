@@ -0,0 +1,134 @@
+// internal/server/middleware/traffic/adaptive.go
+package traffic
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/*
+   Adaptive concurrency (gradient): the server in-flight cap follows the
+   observed latency instead of a fixed InflightMax.
+   - every window the average latency (short RTT) is compared with its long
+     term average (long RTT): gradient = tolerance * long / short, in [0.5, 1];
+   - new limit = limit * gradient + sqrt(limit), smoothed, within
+     [MinLimit, InflightMax];
+   - latency growing (slow DB, webhooks) lowers the cap even with idle CPU;
+     the sqrt(limit) headroom probes for more when latency is flat.
+   The current cap is traffic_concurrency_limit{transport}.
+*/
+
+const (
+	defaultAdaptiveMin       = 10
+	defaultAdaptiveInitial   = 50
+	defaultAdaptiveTolerance = 1.5
+	defaultAdaptiveWindow    = time.Second
+
+	adaptiveSmoothing  = 0.2  // weight of a new limit
+	adaptiveLongWeight = 0.05 // weight of a window in the long RTT
+	adaptiveMinSamples = 10   // a window with fewer calls is extended
+)
+
+// Concurrency is the strategy of the server in-flight cap.
+type Concurrency string
+
+const (
+	ConcurrencyFixed    Concurrency = "fixed"    // InflightMax
+	ConcurrencyAdaptive Concurrency = "adaptive" // latency gradient, up to InflightMax
+)
+
+// Adaptive tunes ConcurrencyAdaptive (zero values = defaults).
+type Adaptive struct {
+	MinLimit     int           // lowest cap (0 = 10)
+	InitialLimit int           // cap at start (0 = 50)
+	Tolerance    float64       // latency growth tolerated before shrinking (0 = 1.5)
+	Window       time.Duration // sampling window (0 = 1s)
+}
+
+type adaptiveLimit struct {
+	mu  sync.Mutex
+	l   *inflightLimiter
+	cfg Adaptive
+	max float64
+
+	limit   float64
+	longRTT float64 // seconds
+
+	start       time.Time
+	sum         float64
+	n           int
+	maxInflight int
+
+	gauge prometheus.Gauge
+}
+
+func newAdaptiveLimit(scope string, l *inflightLimiter, ceiling int, cfg Adaptive) *adaptiveLimit {
+	if cfg.MinLimit < 1 {
+		cfg.MinLimit = defaultAdaptiveMin
+	}
+	if cfg.InitialLimit < 1 {
+		cfg.InitialLimit = defaultAdaptiveInitial
+	}
+	if cfg.Tolerance < 1 {
+		cfg.Tolerance = defaultAdaptiveTolerance
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultAdaptiveWindow
+	}
+	cfg.MinLimit = min(cfg.MinLimit, ceiling)
+	cfg.InitialLimit = min(max(cfg.InitialLimit, cfg.MinLimit), ceiling)
+
+	a := &adaptiveLimit{
+		l:     l,
+		cfg:   cfg,
+		max:   float64(ceiling),
+		limit: float64(cfg.InitialLimit),
+		start: time.Now(),
+		gauge: concurrencyLimit.WithLabelValues(scope),
+	}
+	l.setMax(cfg.InitialLimit)
+	a.gauge.Set(a.limit)
+	return a
+}
+
+// observe adds the latency of a finished call that started with inflight
+// calls in flight; a full window updates the limit.
+func (a *adaptiveLimit) observe(rtt time.Duration, inflight int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sum += rtt.Seconds()
+	a.n++
+	a.maxInflight = max(a.maxInflight, inflight)
+	if a.n < adaptiveMinSamples || time.Since(a.start) < a.cfg.Window {
+		return
+	}
+
+	short := a.sum / float64(a.n)
+	switch {
+	case a.longRTT == 0:
+		a.longRTT = short
+	case a.longRTT/short > 2: // latency recovered: drop the old baseline faster
+		a.longRTT *= 0.95
+	default:
+		a.longRTT = a.longRTT*(1-adaptiveLongWeight) + short*adaptiveLongWeight
+	}
+
+	gradient := 1.0
+	if short > 0 {
+		gradient = math.Max(0.5, math.Min(1, a.cfg.Tolerance*a.longRTT/short))
+	}
+	next := a.limit*gradient + math.Sqrt(a.limit)
+	if float64(a.maxInflight) < a.limit/2 { // not using the cap: no reason to raise it
+		next = math.Min(next, a.limit)
+	}
+	a.limit = a.limit*(1-adaptiveSmoothing) + next*adaptiveSmoothing
+	a.limit = math.Max(float64(a.cfg.MinLimit), math.Min(a.max, a.limit))
+
+	a.l.setMax(int(a.limit))
+	a.gauge.Set(math.Floor(a.limit))
+
+	a.start, a.sum, a.n, a.maxInflight = time.Now(), 0, 0, 0
+}
@@ -0,0 +1,105 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestAdaptive(t *testing.T, ceiling int, cfg Adaptive) (*adaptiveLimit, *inflightLimiter) {
+	t.Helper()
+	l := newInflightLimiter("test adaptive", ceiling, 0, queueCfg{})
+	cfg.Window = time.Nanosecond // every adaptiveMinSamples calls close a window
+	return newAdaptiveLimit("test adaptive", l, ceiling, cfg), l
+}
+
+// window observes one full window of calls.
+func window(a *adaptiveLimit, rtt time.Duration, inflight int) {
+	for i := 0; i < adaptiveMinSamples; i++ {
+		a.observe(rtt, inflight)
+	}
+}
+
+func capOf(l *inflightLimiter) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.max
+}
+
+func TestAdaptiveBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		ceiling int
+		cfg     Adaptive
+		min     int
+		initial int
+	}{
+		{"defaults", 100, Adaptive{}, defaultAdaptiveMin, defaultAdaptiveInitial},
+		{"initial over the ceiling", 20, Adaptive{InitialLimit: 50}, 10, 20},
+		{"min over the ceiling", 5, Adaptive{MinLimit: 10}, 5, 5},
+		{"initial under the minimum", 100, Adaptive{MinLimit: 30, InitialLimit: 20}, 30, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, l := newTestAdaptive(t, tt.ceiling, tt.cfg)
+			if a.cfg.MinLimit != tt.min || int(a.limit) != tt.initial || capOf(l) != tt.initial {
+				t.Errorf("min %d limit %v cap %d, want min %d limit and cap %d", a.cfg.MinLimit, a.limit, capOf(l), tt.min, tt.initial)
+			}
+		})
+	}
+}
+
+func TestAdaptiveUpdate(t *testing.T) {
+	const fast, slow = 10 * time.Millisecond, 100 * time.Millisecond
+
+	t.Run("partial window keeps the limit", func(t *testing.T) {
+		a, l := newTestAdaptive(t, 100, Adaptive{InitialLimit: 50})
+		for i := 0; i < adaptiveMinSamples-1; i++ {
+			a.observe(fast, 50)
+		}
+		if a.limit != 50 || capOf(l) != 50 {
+			t.Errorf("limit %v cap %d, want 50", a.limit, capOf(l))
+		}
+	})
+
+	t.Run("flat latency at the cap grows to the ceiling", func(t *testing.T) {
+		a, l := newTestAdaptive(t, 100, Adaptive{InitialLimit: 50})
+		window(a, fast, 50)
+		if capOf(l) <= 50 {
+			t.Fatalf("cap %d, want raised above 50", capOf(l))
+		}
+		for i := 0; i < 100; i++ {
+			window(a, fast, int(a.limit))
+		}
+		if a.limit != 100 || capOf(l) != 100 {
+			t.Errorf("limit %v cap %d, want the ceiling 100", a.limit, capOf(l))
+		}
+	})
+
+	t.Run("flat latency under the cap does not grow", func(t *testing.T) {
+		a, l := newTestAdaptive(t, 100, Adaptive{InitialLimit: 50})
+		for i := 0; i < 10; i++ {
+			window(a, fast, 5)
+		}
+		if capOf(l) != 50 {
+			t.Errorf("cap %d, want 50", capOf(l))
+		}
+	})
+
+	t.Run("growing latency shrinks to the minimum", func(t *testing.T) {
+		a, l := newTestAdaptive(t, 100, Adaptive{MinLimit: 10, InitialLimit: 50})
+		window(a, fast, 50) // baseline
+		before := a.limit
+		window(a, slow, 50)
+		if a.limit >= before {
+			t.Fatalf("limit %v after a latency spike, want below %v", a.limit, before)
+		}
+		rtt := slow
+		for i := 0; i < 60; i++ { // latency keeps growing 10% a window
+			rtt += rtt / 10
+			window(a, rtt, 50)
+		}
+		if a.limit != 10 || capOf(l) != 10 {
+			t.Errorf("limit %v cap %d, want the minimum 10", a.limit, capOf(l))
+		}
+	})
+}
@@ -2,10 +2,13 @@
 package traffic
 
 import (
-	"sync"
-	"sync/atomic"
+	"context"
+	"time"
 
-	"golang.org/x/time/rate"
+	"service/internal/server/utils/ip"
+
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Core limiter components: InFlight and TokenBucket per key
@@ -14,89 +17,129 @@ type Builder struct {
 	cfg Config
 
 	inflight *inflightLimiter
+	adaptive *adaptiveLimit // nil = fixed InflightMax
 	rl       rateLimiter
-}
+	routes   []*routeLimiter
 
-// InFlight limiter
-type inflightLimiter struct {
-	max int64
-	cur int64
+	inflightNow prometheus.Gauge
 }
 
-func newInflightLimiter(max int) *inflightLimiter {
-	if max < 1 {
-		max = 1
-	}
-	return &inflightLimiter{max: int64(max)}
+type rateLimiter interface {
+	Take(ctx context.Context, key string) Decision
 }
 
-func (l *inflightLimiter) tryEnter() bool {
-	for {
-		c := atomic.LoadInt64(&l.cur)
-		if c >= l.max {
-			return false
-		}
-		if atomic.CompareAndSwapInt64(&l.cur, c, c+1) {
-			return true
-		}
-	}
-}
-
-func (l *inflightLimiter) leave() { atomic.AddInt64(&l.cur, -1) }
+type rateLimImpl struct {
+	tb *tbStore
+	r  float64
+	b  int
 
-// Token bucket by key
-type tbStore struct {
-	mu sync.Mutex
-	m  map[string]*rate.Limiter
+	scope  string  // shared key prefix ("http", "http /v1/upload")
+	shared *Shared // nil = local buckets only
 }
 
-func newTBStore() *tbStore { return &tbStore{m: make(map[string]*rate.Limiter)} }
-
-func (s *tbStore) get(key string, rps float64, burst int) *rate.Limiter {
-	if key == "" {
-		key = "global"
-	}
-	if burst < 1 {
-		burst = 1
-	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if lim, ok := s.m[key]; ok {
-		return lim
+// Take uses the shared bucket when configured, the local one otherwise
+// (and whenever the shared store fails).
+func (r rateLimImpl) Take(ctx context.Context, key string) Decision {
+	if r.shared != nil {
+		if d, err := r.shared.Take(ctx, r.scope+":"+key, r.r, r.b); err == nil {
+			return d
+		}
 	}
-	lim := rate.NewLimiter(rate.Limit(rps), burst)
-	s.m[key] = lim
-	return lim
+	return Take(r.tb.get(key, r.r, r.b))
 }
 
-type rateLimiter interface{ Allow(key string) bool }
-
-type rateLimImpl struct {
-	tb *tbStore
-	r  float64
-	b  int
+func newRateLimImpl(scope string, cfg Config, rps float64, burst int) rateLimImpl {
+	return rateLimImpl{
+		tb:     newTBStore(scope, cfg.MaxKeys, cfg.KeyIdleTTL),
+		r:      rps,
+		b:      burst,
+		scope:  scope,
+		shared: cfg.Shared,
+	}
 }
 
-func (r rateLimImpl) Allow(key string) bool { return r.tb.get(key, r.r, r.b).Allow() }
-
 // Builder
 func New(cfg Config) *Builder {
-	b := &Builder{cfg: cfg}
+	b := &Builder{cfg: cfg, inflightNow: inflightNow.WithLabelValues(cfg.scope())}
 	if cfg.InflightMax > 0 {
-		b.inflight = newInflightLimiter(cfg.InflightMax)
+		b.inflight = newInflightLimiter(cfg.scope(), cfg.InflightMax, cfg.CriticalHeadroom, cfg.queue())
+		if cfg.Concurrency == ConcurrencyAdaptive {
+			b.adaptive = newAdaptiveLimit(cfg.scope(), b.inflight, cfg.InflightMax, cfg.Adaptive)
+		} else {
+			concurrencyLimit.WithLabelValues(cfg.scope()).Set(float64(cfg.InflightMax))
+		}
 	}
 	if cfg.RateRPS > 0 {
-		b.rl = rateLimImpl{tb: newTBStore(), r: cfg.RateRPS, b: cfg.RateBurst}
+		b.rl = newRateLimImpl(cfg.scope(), cfg, cfg.RateRPS, cfg.RateBurst)
+	}
+	for _, r := range cfg.Routes {
+		b.routes = append(b.routes, newRouteLimiter(r, cfg))
 	}
 	return b
 }
 
-func (b *Builder) tryInflight() (leave func(), blocked bool) {
+// tryInflight takes a server in-flight slot for a call of class crit, waiting
+// in its queue when queue is set (not for a shadowed cap); leave must be
+// called when the call is done (it feeds the call latency to the adaptive
+// cap).
+func (b *Builder) tryInflight(ctx context.Context, crit Criticality, queue bool) (leave func(), blocked bool) {
 	if b.inflight == nil {
 		return func() {}, false
 	}
-	if !b.inflight.tryEnter() {
+	if !b.inflight.acquire(ctx, crit, queue) {
 		return func() {}, true
 	}
-	return func() { b.inflight.leave() }, false
+	b.inflightNow.Inc()
+	if b.adaptive == nil {
+		return func() {
+			b.inflight.leave()
+			b.inflightNow.Dec()
+		}, false
+	}
+	start, inflight := time.Now(), b.inflight.inUse()
+	return func() {
+		b.inflight.leave()
+		b.inflightNow.Dec()
+		b.adaptive.observe(time.Since(start), inflight)
+	}, false
+}
+
+// newBBR creates the adaptive CPU limiter (nil when disabled).
+func (b *Builder) newBBR() *bbr.BBR {
+	if !b.cfg.EnableCPU {
+		return nil
+	}
+	l := bbr.NewLimiter(
+		bbr.WithWindow(b.cfg.CPUWindow),
+		bbr.WithBucket(b.cfg.CPUBuckets),
+		bbr.WithCPUThreshold(b.cfg.CPUThreshold),
+		bbr.WithCPUQuota(b.cfg.CPUQuota),
+	)
+	registerBBR(b.cfg.scope(), l)
+	return l
+}
+
+// enforce records the outcome of a limiter and reports whether the call must
+// be rejected. A shadowed rejection is logged (key, route) and counted as
+// "shadow"; the call goes on. An empty key logs the client IP.
+func (b *Builder) enforce(ctx context.Context, op, route, reason, key string, passed, shadow bool) bool {
+	switch {
+	case passed:
+		Record(b.cfg.scope(), op, reason, ResultPass)
+		return false
+	case shadow:
+		Record(b.cfg.scope(), op, reason, ResultShadow)
+		if key == "" {
+			key = "ip:" + server_utils_ip.GetIP(ctx)
+		}
+		if route == "" {
+			route = "-"
+		}
+		b.cfg.LogHelper.Warnf("[TRAFFIC] [SHADOW] would reject: limiter=%s transport=%s operation=%s route=%s key=%s",
+			reason, b.cfg.scope(), op, route, key)
+		return false
+	default:
+		Record(b.cfg.scope(), op, reason, ResultReject)
+		return true
+	}
 }
@@ -0,0 +1,173 @@
+package traffic
+
+import (
+	"math"
+	"runtime"
+	"strings"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// FromConf applies a traffic.http / traffic.grpc section on top of base
+// (HTTPConfig / GRPCConfig); unset or zero values keep the base ones, except
+// inflight_max, rate_rps and queue_max, where an explicit 0 turns the limit
+// off, and critical_headroom, which defaults to 10% of inflight_max.
+func FromConf(c *conf.Traffic_Limits, base Config, logger log.Logger) Config {
+	h := log.NewHelper(logger)
+	cfg := base
+	cfg.Routes = nil
+	cfg.Shadow = nil
+	if c != nil {
+		if c.InflightMax != nil {
+			cfg.InflightMax = nonNegative(c.GetInflightMax())
+		}
+		if v := parseConcurrency(c.GetConcurrency(), h); v != "" {
+			cfg.Concurrency = v
+		}
+		if a := c.GetAdaptive(); a != nil {
+			cfg.Adaptive.MinLimit = int(a.GetMinLimit())
+			cfg.Adaptive.InitialLimit = int(a.GetInitialLimit())
+			cfg.Adaptive.Tolerance = a.GetTolerance()
+			if v := a.GetWindow(); v != nil {
+				cfg.Adaptive.Window = v.AsDuration()
+			}
+		}
+		if v := strings.TrimSpace(c.GetCriticalityHeader()); v != "" {
+			cfg.CriticalityHeader = v
+		}
+		cfg.CriticalHeadroom = defaultHeadroom(cfg.InflightMax)
+		if c.CriticalHeadroom != nil {
+			cfg.CriticalHeadroom = nonNegative(c.GetCriticalHeadroom())
+		}
+		if c.QueueMax != nil {
+			cfg.QueueMax = nonNegative(c.GetQueueMax())
+		}
+		if v := c.GetQueueTimeout(); v != nil && v.AsDuration() > 0 {
+			cfg.QueueTimeout = v.AsDuration()
+		}
+		if v := parseQueueOrder(c.GetQueueOrder(), h); v != "" {
+			cfg.QueueOrder = v
+		}
+		if c.RateRps != nil {
+			cfg.RateRPS = math.Max(c.GetRateRps(), 0)
+		}
+		if v := c.GetRateBurst(); v > 0 {
+			cfg.RateBurst = int(v)
+		}
+		if v := parseKeyBy(c.GetKeyBy(), h); v != "" {
+			cfg.KeyBy = v
+		}
+		if v := c.GetMaxKeys(); v > 0 {
+			cfg.MaxKeys = int(v)
+		}
+		if v := c.GetKeyIdleTtl(); v != nil && v.AsDuration() > 0 {
+			cfg.KeyIdleTTL = v.AsDuration()
+		}
+		if cpu := c.GetCpu(); cpu != nil {
+			cfg.EnableCPU = !cpu.GetDisabled()
+			if v := cpu.GetWindow(); v != nil && v.AsDuration() > 0 {
+				cfg.CPUWindow = v.AsDuration()
+			}
+			if v := cpu.GetBuckets(); v > 0 {
+				cfg.CPUBuckets = int(v)
+			}
+			if v := cpu.GetThreshold(); v > 0 {
+				cfg.CPUThreshold = v
+			}
+			if v := cpu.GetCriticalThreshold(); v > 0 {
+				cfg.CPUCritical = v
+			}
+			if v := cpu.GetQuota(); v > 0 {
+				cfg.CPUQuota = v
+			}
+		}
+		for _, r := range c.GetRoutes() {
+			route := Route{
+				Operation:   strings.TrimSpace(r.GetOperation()),
+				PathPrefix:  strings.TrimSpace(r.GetPathPrefix()),
+				RateRPS:     r.GetRateRps(),
+				RateBurst:   int(r.GetRateBurst()),
+				KeyBy:       parseKeyBy(r.GetKeyBy(), h),
+				InflightMax: int(r.GetInflightMax()),
+				QueueMax:    int(r.GetQueueMax()),
+				QueueOrder:  parseQueueOrder(r.GetQueueOrder(), h),
+				Shadow:      r.GetShadow(),
+				Criticality: parseCriticality(r.GetCriticality(), h),
+			}
+			if v := r.GetQueueTimeout(); v != nil {
+				route.QueueTimeout = v.AsDuration()
+			}
+			if route.Operation == "" && route.PathPrefix == "" {
+				h.Warnf("[TRAFFIC] route without operation/path_prefix ignored")
+				continue
+			}
+			cfg.Routes = append(cfg.Routes, route)
+		}
+		for _, s := range c.GetShadow() {
+			switch r := strings.ToLower(strings.TrimSpace(s)); r {
+			case ReasonInflight, ReasonRate, ReasonBBR, ReasonIQ:
+				cfg.Shadow = append(cfg.Shadow, r)
+			default:
+				h.Warnf("[TRAFFIC] unknown shadow limiter %q ignored", s)
+			}
+		}
+	}
+	if cfg.CPUQuota <= 0 {
+		cfg.CPUQuota = float64(runtime.GOMAXPROCS(0))
+	}
+	return DefaultConfigWithLog(cfg, logger)
+}
+
+// defaultHeadroom is the critical headroom of a cap: 10% of it, at least 1
+// (none without a cap).
+func defaultHeadroom(inflightMax int) int {
+	if inflightMax <= 0 {
+		return 0
+	}
+	return max(inflightMax/10, 1)
+}
+
+func nonNegative(v int32) int {
+	if v < 0 {
+		return 0
+	}
+	return int(v)
+}
+
+// parseConcurrency returns "" (keep the base) for empty or unknown values.
+func parseConcurrency(s string, h *log.Helper) Concurrency {
+	switch c := Concurrency(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+	case ConcurrencyFixed, ConcurrencyAdaptive:
+		return c
+	default:
+		h.Warnf("[TRAFFIC] unknown concurrency %q ignored", s)
+	}
+	return ""
+}
+
+// parseQueueOrder returns "" (inherit) for empty or unknown values.
+func parseQueueOrder(s string, h *log.Helper) QueueOrder {
+	switch o := QueueOrder(strings.ToLower(strings.TrimSpace(s))); o {
+	case "":
+	case QueueFIFO, QueueLIFO:
+		return o
+	default:
+		h.Warnf("[TRAFFIC] unknown queue_order %q ignored", s)
+	}
+	return ""
+}
+
+// parseKeyBy returns "" (inherit) for empty or unknown values.
+func parseKeyBy(s string, h *log.Helper) KeyBy {
+	switch k := KeyBy(strings.ToLower(strings.TrimSpace(s))); k {
+	case "":
+	case KeyGlobal, KeyIP, KeyUser:
+		return k
+	default:
+		h.Warnf("[TRAFFIC] unknown key_by %q ignored", s)
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestFromConf(t *testing.T) {
+	base := HTTPConfig(log.DefaultLogger)
+
+	tests := []struct {
+		name      string
+		json      string // traffic.http section as loaded from the config file
+		inflight  int
+		queue     int
+		rps       float64
+		burst     int
+		keyBy     KeyBy
+		cpu       bool
+		hasRoutes bool
+	}{
+		{"nil section", "", 400, 0, 150, 300, KeyIP, true, false},
+		{"empty section", `{}`, 400, 0, 150, 300, KeyIP, true, false},
+		{"overrides", `{"inflight_max": 20, "queue_max": 5, "rate_rps": 10, "rate_burst": 20, "key_by": "user"}`, 20, 5, 10, 20, KeyUser, true, false},
+		{"explicit zero disables", `{"inflight_max": 0, "rate_rps": 0}`, 0, 0, 0, 300, KeyIP, true, false},
+		{"zero burst keeps base", `{"rate_burst": 0}`, 400, 0, 150, 300, KeyIP, true, false},
+		{"negative is off", `{"inflight_max": -1}`, 0, 0, 150, 300, KeyIP, true, false},
+		{"unknown key_by ignored", `{"key_by": "tenant"}`, 400, 0, 150, 300, KeyIP, true, false},
+		{"cpu disabled", `{"cpu": {"disabled": true}}`, 400, 0, 150, 300, KeyIP, false, false},
+		{"route without match ignored", `{"routes": [{"rate_rps": 5}]}`, 400, 0, 150, 300, KeyIP, true, false},
+		{"route", `{"routes": [{"path_prefix": "/v1/upload", "inflight_max": 2}]}`, 400, 0, 150, 300, KeyIP, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *conf.Traffic_Limits
+			if tt.json != "" {
+				c = &conf.Traffic_Limits{}
+				if err := protojson.Unmarshal([]byte(tt.json), c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := FromConf(c, base, log.DefaultLogger)
+			if cfg.InflightMax != tt.inflight || cfg.QueueMax != tt.queue || cfg.RateRPS != tt.rps || cfg.RateBurst != tt.burst {
+				t.Errorf("inflight/queue/rps/burst = %d/%d/%v/%d, want %d/%d/%v/%d",
+					cfg.InflightMax, cfg.QueueMax, cfg.RateRPS, cfg.RateBurst, tt.inflight, tt.queue, tt.rps, tt.burst)
+			}
+			if cfg.KeyBy != tt.keyBy || cfg.EnableCPU != tt.cpu || (len(cfg.Routes) > 0) != tt.hasRoutes {
+				t.Errorf("key_by/cpu/routes = %s/%t/%d, want %s/%t/%t", cfg.KeyBy, cfg.EnableCPU, len(cfg.Routes), tt.keyBy, tt.cpu, tt.hasRoutes)
+			}
+			if cfg.Name != "http" || cfg.LogHelper == nil {
+				t.Errorf("name = %q, log helper set = %t", cfg.Name, cfg.LogHelper != nil)
+			}
+		})
+	}
+}
+
+func TestFromConfDisabledLimits(t *testing.T) {
+	c := &conf.Traffic_Limits{}
+	if err := protojson.Unmarshal([]byte(`{"inflight_max": 0, "rate_rps": 0, "queue_timeout": "2s"}`), c); err != nil {
+		t.Fatal(err)
+	}
+	cfg := FromConf(c, HTTPConfig(log.DefaultLogger), log.DefaultLogger)
+	if cfg.QueueTimeout != 2*time.Second {
+		t.Fatalf("queue_timeout = %v, want 2s", cfg.QueueTimeout)
+	}
+
+	b := New(cfg)
+	if b.inflight != nil || b.rl != nil {
+		t.Fatalf("limits built with inflight_max: 0 and rate_rps: 0 (inflight %v, rate %v)", b.inflight != nil, b.rl != nil)
+	}
+}
+
+func TestFromConfCriticalHeadroom(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want int
+	}{
+		{"nil section", "", 40},
+		{"default is 10% of the cap", `{"inflight_max": 200}`, 20},
+		{"at least one slot", `{"inflight_max": 5}`, 1},
+		{"none without a cap", `{"inflight_max": 0}`, 0},
+		{"explicit", `{"inflight_max": 200, "critical_headroom": 7}`, 7},
+		{"explicit zero disables", `{"critical_headroom": 0}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *conf.Traffic_Limits
+			if tt.json != "" {
+				c = &conf.Traffic_Limits{}
+				if err := protojson.Unmarshal([]byte(tt.json), c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := FromConf(c, HTTPConfig(log.DefaultLogger), log.DefaultLogger).CriticalHeadroom; got != tt.want {
+				t.Errorf("critical headroom = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,23 +12,93 @@ type KeyBy string
 const (
 	KeyGlobal KeyBy = "global"
 	KeyIP     KeyBy = "ip"
-	KeyUser   KeyBy = "user"
+	KeyUser   KeyBy = "user" // verified caller (see UseIdentity), anonymous by IP
+)
+
+// QueueOrder picks which queued call gets a freed in-flight slot.
+type QueueOrder string
+
+const (
+	QueueFIFO QueueOrder = "fifo" // oldest first
+	QueueLIFO QueueOrder = "lifo" // newest first, oldest dropped when full
 )
 
 type Config struct {
-	// maximum concurrent requests
+	// limiter name for logs/metrics ("http", "grpc")
+	Name string
+	// shedding class set by trusted proxies ("" = route classes only)
+	CriticalityHeader string
+	// maximum concurrent requests (ceiling of the adaptive cap)
 	InflightMax int
+	Concurrency Concurrency // "" = fixed
+	Adaptive    Adaptive
+	// in-flight slots above the cap only critical calls may take (0 = none)
+	CriticalHeadroom int
+	// wait queue at the in-flight cap (QueueMax 0 = 429 at once)
+	QueueMax     int
+	QueueTimeout time.Duration // 0 = 1s; the request deadline also applies
+	QueueOrder   QueueOrder
 	// token bucket (RPS/Burst)
 	RateRPS   float64
 	RateBurst int
 	// grouping key (global/ip/user)
 	KeyBy KeyBy
+	// bounded bucket store: max keys and idle TTL (0 = 100000 keys / 10m)
+	MaxKeys    int
+	KeyIdleTTL time.Duration
 	// Adaptive protection by CPU (BBR)
 	EnableCPU    bool
 	CPUWindow    time.Duration
 	CPUBuckets   int
-	CPUThreshold int64 // 800 = 80%
+	CPUThreshold int64 // 800 = 80%; sheddable calls are shed above it
+	CPUCritical  int64 // critical calls are shed only above it (0 = 950)
 	CPUQuota     float64
+	// per-route overrides (first match wins)
+	Routes []Route
+	// limiters in dry-run ("inflight", "rate", "bbr", "iq"): would-be
+	// rejections are logged and counted, the call goes on
+	Shadow []string
+	// buckets shared by all replicas (nil = per replica)
+	Shared *Shared
 
 	LogHelper *log.Helper
 }
+
+// Route overrides limits of matching operations; zero values are inherited.
+// A route with RateRPS (or KeyBy/RateBurst) gets its own token buckets instead
+// of the server ones; InflightMax caps the route on top of the server cap.
+type Route struct {
+	Operation   string // "/pkg.Service/Method" or "/pkg.Service/*"
+	PathPrefix  string // HTTP path prefix (gRPC: operation prefix)
+	RateRPS     float64
+	RateBurst   int
+	KeyBy       KeyBy
+	InflightMax int
+	// wait queue of the route cap (zero values = the server ones)
+	QueueMax     int
+	QueueTimeout time.Duration
+	QueueOrder   QueueOrder
+	Shadow       bool        // dry-run the route's own limits
+	Criticality  Criticality // shedding class of its calls ("" = default)
+}
+
+// Shadowed reports whether the limiter (Reason*) is in dry-run.
+func (c Config) Shadowed(reason string) bool {
+	for _, s := range c.Shadow {
+		if s == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Config) queue() queueCfg {
+	return queueCfg{Max: c.QueueMax, Timeout: c.QueueTimeout, LIFO: c.QueueOrder == QueueLIFO}
+}
+
+func (c Config) scope() string {
+	if c.Name == "" {
+		return "default"
+	}
+	return c.Name
+}
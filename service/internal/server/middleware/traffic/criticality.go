@@ -0,0 +1,93 @@
+// internal/server/middleware/traffic/criticality.go
+package traffic
+
+import (
+	"context"
+	"strings"
+
+	"service/internal/server/utils/ip"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+/*
+   Criticality: how readily a call is shed under load.
+   - from the matching Route (per operation / path prefix);
+   - overridden by Config.CriticalityHeader (off by default), only when the
+     peer is a trusted proxy (server.trusted_proxies). A trusted peer only
+     proves the hop, not who set the header: the proxy must strip it from
+     client requests (and set it itself), or any client behind it picks
+     "critical";
+   - sheddable: never waits in an in-flight queue and is shed by BBR as soon
+     as CPU passes the threshold;
+   - default: BBR as is;
+   - critical: let through a BBR rejection until CPU reaches CPUCritical;
+     at the in-flight cap it may take the CriticalHeadroom slots above the
+     server cap, and it waits in a queue served before the others, taking
+     the place of a default waiter when the queue is full (see inflight.go).
+   Rate limits and individual quotas do not depend on it.
+*/
+
+const defaultCPUCritical = 950 // 95%
+
+// Criticality is the shedding class of a call ("" = default).
+type Criticality string
+
+const (
+	CriticalityCritical  Criticality = "critical"
+	CriticalityDefault   Criticality = "default"
+	CriticalitySheddable Criticality = "sheddable"
+)
+
+// criticality returns the class of a call: the header of a trusted peer, else
+// the route one (r may be nil).
+func (b *Builder) criticality(ctx context.Context, r *routeLimiter, header func(string) string) Criticality {
+	if b.cfg.CriticalityHeader != "" && server_utils_ip.TrustedPeer(ctx) {
+		if c := parseCriticality(header(b.cfg.CriticalityHeader), nil); c != "" {
+			return c
+		}
+	}
+	if r != nil && r.Criticality != "" {
+		return r.Criticality
+	}
+	return CriticalityDefault
+}
+
+// shed asks BBR (tail) for a call of class crit; done is nil when no BBR slot
+// was taken.
+func (b *Builder) shed(tail *bbr.BBR, crit Criticality) (done func(ratelimit.DoneInfo), passed bool) {
+	if crit == CriticalitySheddable && tail.Stat().CPU >= b.cfg.CPUThreshold {
+		return nil, false
+	}
+	done, err := tail.Allow()
+	if err == nil {
+		return done, true
+	}
+	if crit == CriticalityCritical && tail.Stat().CPU < b.cpuCritical() {
+		return nil, true
+	}
+	return nil, false
+}
+
+func (b *Builder) cpuCritical() int64 {
+	if b.cfg.CPUCritical > 0 {
+		return b.cfg.CPUCritical
+	}
+	return defaultCPUCritical
+}
+
+// parseCriticality returns "" for empty or unknown values (warned when h is set).
+func parseCriticality(s string, h *log.Helper) Criticality {
+	switch c := Criticality(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+	case CriticalityCritical, CriticalityDefault, CriticalitySheddable:
+		return c
+	default:
+		if h != nil {
+			h.Warnf("[TRAFFIC] unknown criticality %q ignored", s)
+		}
+	}
+	return ""
+}
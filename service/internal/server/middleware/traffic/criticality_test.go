@@ -0,0 +1,55 @@
+package traffic
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"service/internal/conf/v1"
+	"service/internal/server/utils/ip"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestCriticality(t *testing.T) {
+	server_utils_ip.Init(&conf.Server{TrustedProxies: []string{"10.0.0.0/8"}})
+	t.Cleanup(func() { server_utils_ip.Init(nil) })
+
+	from := func(addr string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 1234}})
+	}
+	header := func(v string) func(string) string {
+		return func(key string) string {
+			if key == "X-Criticality" {
+				return v
+			}
+			return ""
+		}
+	}
+	sheddable := &routeLimiter{Route: Route{Criticality: CriticalitySheddable}}
+
+	tests := []struct {
+		name   string
+		header string // Config.CriticalityHeader
+		ctx    context.Context
+		r      *routeLimiter
+		value  string
+		want   Criticality
+	}{
+		{"no route", "", from("10.0.0.1"), nil, "", CriticalityDefault},
+		{"route class", "", from("10.0.0.1"), sheddable, "", CriticalitySheddable},
+		{"header off by default", "", from("10.0.0.1"), sheddable, "critical", CriticalitySheddable},
+		{"trusted proxy", "X-Criticality", from("10.0.0.1"), sheddable, "Critical", CriticalityCritical},
+		{"untrusted peer", "X-Criticality", from("203.0.113.9"), sheddable, "critical", CriticalitySheddable},
+		{"unknown value", "X-Criticality", from("10.0.0.1"), sheddable, "urgent", CriticalitySheddable},
+		{"no peer", "X-Criticality", context.Background(), nil, "critical", CriticalityDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Builder{cfg: Config{CriticalityHeader: tt.header}}
+			if got := b.criticality(tt.ctx, tt.r, header(tt.value)); got != tt.want {
+				t.Errorf("criticality() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
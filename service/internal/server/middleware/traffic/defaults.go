@@ -12,11 +12,17 @@ var DefaultConfig = Config{
 	RateRPS:      150,                            // Valor objetivo de requests por segundo (token bucket) // Целевой лимит запросов в секунду (token bucket)
 	RateBurst:    300,                            // Valor maximo de tokens (capacidad del bucket) // Максимальное количество токенов (емкость bucket)
 	KeyBy:        KeyIP,                          // Como agrupar el limite: por IP (ip)/usuario (user)/global (global) // Как группировать лимит: по IP (ip)/пользователю (user)/глобально (global)
+	MaxKeys:      defaultMaxKeys,                 // Maximo de claves (IP/usuario) en memoria // Максимум ключей (IP/пользователь) в памяти
+	KeyIdleTTL:   defaultKeyIdleTTL,              // Clave sin peticiones se elimina tras este tiempo // Ключ без запросов удаляется после этого времени
 	EnableCPU:    true,                           // Activar proteccion adaptativa por CPU (BBR)
 	CPUWindow:    10 * time.Second,               // Ventana de observacion BBR para calcular metricas // Окно наблюдения BBR для расчета метрик
 	CPUBuckets:   100,                            // Numero de buckets dentro de la ventana (precision de mediciones) // Число бакетов внутри окна (точность измерений)
 	CPUThreshold: 800,                            // Umbral de carga de CPU en milesimas (800 = 80%) // Порог загрузки CPU в тысячных (800 = 80%)
+	CPUCritical:  950,                            // Umbral para descartar también las llamadas críticas (950 = 95%) // Порог, выше которого отбрасываются и критичные вызовы (950 = 95%)
 	CPUQuota:     float64(runtime.GOMAXPROCS(0)), // Quota efectiva de CPU para BBR (cantidad de CPU disponibles) // Эффективная квота CPU для BBR (количество доступных CPU)
+
+	CriticalHeadroom:  40, // Plazas extra sobre InflightMax solo para llamadas críticas (10%) // Дополнительные слоты сверх InflightMax только для критичных вызовов (10%)
+	CriticalityHeader: "", // Sin cabecera de criticidad: solo la de la ruta // Без заголовка критичности: только класс маршрута
 }
 
 var DefaultConfigTest = Config{
@@ -29,14 +35,21 @@ var DefaultConfigTest = Config{
 	CPUBuckets:   10,                             // Menos buckets — más rápido cálculo // Меньше бакетов — быстрее расчет
 	CPUThreshold: 800,                            // 80%
 	CPUQuota:     float64(runtime.GOMAXPROCS(0)), // Usar CPU efectivas // Использовать эффективные CPU
+
+	CriticalHeadroom: 1, // Una plaza extra para llamadas críticas // Один дополнительный слот для критичных вызовов
 }
 
-// backward-compatible helpers used by server constructors
-func HTTPConfig(logger log.Logger) Config     { return DefaultConfigWithLog(DefaultConfig, logger) }
-func HTTPConfigTest(logger log.Logger) Config { return DefaultConfigWithLog(DefaultConfigTest, logger) } // tiny defaults for fast tests
+// backward-compatible helpers; server constructors use FromConf (traffic section)
+func HTTPConfig(logger log.Logger) Config     { return named("http", DefaultConfig, logger) }
+func HTTPConfigTest(logger log.Logger) Config { return named("http", DefaultConfigTest, logger) } // tiny defaults for fast tests
 
-func GRPCConfig(logger log.Logger) Config     { return DefaultConfigWithLog(DefaultConfig, logger) }
-func GRPCConfigTest(logger log.Logger) Config { return DefaultConfigWithLog(DefaultConfigTest, logger) } // tiny defaults for fast tests
+func GRPCConfig(logger log.Logger) Config     { return named("grpc", DefaultConfig, logger) }
+func GRPCConfigTest(logger log.Logger) Config { return named("grpc", DefaultConfigTest, logger) } // tiny defaults for fast tests
+
+func named(name string, cfg Config, logger log.Logger) Config {
+	cfg.Name = name
+	return DefaultConfigWithLog(cfg, logger)
+}
 
 func DefaultConfigWithLog(cfg Config, logger log.Logger) Config {
 	cfg.LogHelper = log.NewHelper(logger)
@@ -0,0 +1,57 @@
+package traffic
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Subjects identify the verified caller of a request (empty = unknown).
+type Subjects struct {
+	User    string // principal subject (user name; "apikey:<id>" for API keys)
+	Company string // tenant id
+	APIKey  string // key id, callers authenticated by API key only
+}
+
+// Key is the KeyUser bucket key: "u:<user>", "c:<company>" or "" (anonymous).
+func (s Subjects) Key() string {
+	switch {
+	case s.User != "":
+		return "u:" + s.User
+	case s.Company != "":
+		return "c:" + s.Company
+	}
+	return ""
+}
+
+// Identity resolves the verified caller of ctx (zero Subjects = anonymous).
+// The returned ctx may carry the verification result for later middlewares.
+type Identity func(ctx context.Context) (context.Context, Subjects)
+
+var identity atomic.Pointer[Identity]
+
+// UseIdentity sets how KeyUser and per-client quotas find the caller
+// (e.g. endpoint.Identity). Without it every caller is anonymous.
+func UseIdentity(fn Identity) {
+	if fn == nil {
+		identity.Store(nil)
+		return
+	}
+	identity.Store(&fn)
+}
+
+// Caller returns the verified caller of ctx (see UseIdentity).
+func Caller(ctx context.Context) (context.Context, Subjects) {
+	if fn := identity.Load(); fn != nil {
+		return (*fn)(ctx)
+	}
+	return ctx, Subjects{}
+}
+
+// userKey returns "u:..."/"c:..." for an authenticated caller, else "ip:<ip>".
+func userKey(ctx context.Context, ip func() string) (context.Context, string) {
+	ctx, s := Caller(ctx)
+	if key := s.Key(); key != "" {
+		return ctx, key
+	}
+	return ctx, "ip:" + ip()
+}
@@ -2,10 +2,13 @@ package individual_quotas
 
 import "time"
 
-// Configuración por defecto.
+// Configuración por defecto (valores cero de traffic.quotas).
 const (
-	defaultServiceURL   = "http://10.70.20.80:10000"
 	defaultRefreshEvery = 24 * time.Hour
+	defaultFetchTimeout = 10 * time.Second
 	defaultBurstFactor  = 2.0
-	defaultStrictMatch  = true
+	defaultRetryMin     = 5 * time.Second
+	defaultRetryMax     = 5 * time.Minute
+	defaultClientKeys   = 100000
+	defaultClientTTL    = time.Hour
 )
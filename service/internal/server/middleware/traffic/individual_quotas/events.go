@@ -0,0 +1,93 @@
+package individual_quotas
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+   Cliente SSE (text/event-stream) de traffic.quotas.events_url: cada evento
+   ("data:" de una o más líneas) va a Hub.HandleMessage. Si la conexión cae se
+   reconecta con backoff y, ya conectado, se refresca todo por si se perdieron
+   avisos.
+*/
+
+const maxEventSize = 1 << 20
+
+func (h *Hub) listen(ctx context.Context) {
+	failures := 0
+	reconnect := false
+	for {
+		connected, err := h.stream(ctx, reconnect)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			failures = 0
+			reconnect = true
+		}
+		failures++
+		wait := backoff(h.retryMin, h.retryMax, failures)
+		h.log.Warnf("[IQ] quota events disconnected: %v (retry in %s)", err, wait)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// stream lee eventos hasta que la conexión termina; connected indica si
+// llegó a abrirse.
+func (h *Hub) stream(ctx context.Context, reconnect bool) (connected bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.eventsURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("bad HTTP status: %s", resp.Status)
+	}
+
+	h.log.Infof("[IQ] quota events connected: %s", h.eventsURL)
+	if reconnect {
+		h.notifyAll()
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			// fin del evento
+			if len(data) > 0 {
+				h.HandleMessage([]byte(strings.Join(data, "\n")))
+				data = data[:0]
+			}
+		case strings.HasPrefix(line, ":"):
+			// comentario / keep-alive
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return true, err
+	}
+	return true, fmt.Errorf("stream closed")
+}
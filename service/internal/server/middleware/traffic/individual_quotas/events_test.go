@@ -0,0 +1,122 @@
+package individual_quotas
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// eventsServer streams events on the first connection and closes it; later
+// connections stay open without events.
+func eventsServer(t *testing.T, events string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var conns atomic.Int32
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		if conns.Add(1) == 1 {
+			_, _ = fmt.Fprint(w, events)
+			return
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+	return srv, &conns
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHubEvents(t *testing.T) {
+	events := ": keep-alive\n\n" +
+		"event: quotas\n" +
+		"data: {\"project\":\"p\",\n" +
+		"data:  \"items\":[{\"project\":\"p\",\"route\":\"/v1/a\",\"quota\":1},{\"project\":\"p\",\"route\":\"/v1/b\",\"quota\":2,\"key_by\":\"user\"}]}\n\n" +
+		"data: {\"project\":\"q\"}\n\n"
+	srv, conns := eventsServer(t, events)
+
+	qc := &conf.Traffic_Quotas{
+		ServiceUrl: "http://127.0.0.1:1",
+		EventsUrl:  srv.URL,
+		RetryMin:   durationpb.New(10 * time.Millisecond),
+		RetryMax:   durationpb.New(10 * time.Millisecond),
+	}
+	iq := New("p", HTTP, qc, log.DefaultLogger)
+	hub, cleanup, err := NewHub(&conf.Traffic{Quotas: qc}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cleanup)
+	hub.Attach(iq)
+
+	// The multi-line event is the full table; the hint for "q" is not ours.
+	eventually(t, "the pushed table", func() bool { return iq.QuotasLen() == 2 })
+	rq := iq.quotas.Load()["/v1/b"]
+	if rq.route != nil || rq.clients[dimUser].def == nil || rq.clients[dimUser].def.Quota != 2 {
+		t.Fatalf("/v1/b = %+v, want a default user quota of 2", rq)
+	}
+	if iq.refreshedAt.Load() == 0 {
+		t.Fatal("pushed table not recorded as a refresh")
+	}
+
+	// The stream closed: after reconnecting every IQ refetches (events may
+	// have been lost meanwhile).
+	eventually(t, "the reconnection", func() bool { return conns.Load() >= 2 })
+	eventually(t, "the refetch request", func() bool { return len(iq.kick) == 1 })
+}
+
+func TestHubEventsBadStatus(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	qc := &conf.Traffic_Quotas{
+		ServiceUrl: "http://127.0.0.1:1",
+		EventsUrl:  srv.URL,
+		RetryMin:   durationpb.New(10 * time.Millisecond),
+		RetryMax:   durationpb.New(20 * time.Millisecond),
+	}
+	iq := New("p", HTTP, qc, log.DefaultLogger)
+	hub, cleanup, err := NewHub(&conf.Traffic{Quotas: qc}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub.Attach(iq)
+
+	eventually(t, "retries", func() bool { return hits.Load() >= 3 })
+	cleanup()
+	if len(iq.kick) != 0 {
+		t.Fatal("refetch requested without a successful connection")
+	}
+}
@@ -0,0 +1,161 @@
+package individual_quotas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+/*
+   Hub: une los IQ del proceso (HTTP y gRPC) con
+   - avisos push del servicio de cuotas, por MQTT (traffic.quotas.mqtt_topic,
+     lo entrega el broker) o server-sent events (traffic.quotas.events_url);
+   - la API de administración /qt (estado y refresco manual).
+
+   Un aviso con "items" es la tabla completa y se aplica tal cual; sin items
+   es una pista y cada IQ vuelve a pedir su tabla al servicio.
+*/
+
+// quotaEvent es el mensaje push; Project vacío = para todos los proyectos.
+type quotaEvent struct {
+	Project string       `json:"project,omitempty"`
+	Items   *[]quotaItem `json:"items,omitempty"`
+}
+
+type Hub struct {
+	topic      string
+	eventsURL  string
+	adminRoles []string
+	retryMin   time.Duration
+	retryMax   time.Duration
+
+	mu  sync.RWMutex
+	iqs []*IQ
+
+	log *log.Helper
+}
+
+// NewHub crea el hub de traffic.quotas y empieza a escuchar events_url (si hay).
+func NewHub(c *conf.Traffic, logger log.Logger) (*Hub, func(), error) {
+	qc := c.GetQuotas()
+	h := &Hub{
+		topic:      strings.TrimSpace(qc.GetMqttTopic()),
+		eventsURL:  strings.TrimSpace(qc.GetEventsUrl()),
+		adminRoles: qc.GetAdminRoles(),
+		retryMin:   durationOr(qc.GetRetryMin(), defaultRetryMin),
+		retryMax:   durationOr(qc.GetRetryMax(), defaultRetryMax),
+		log:        log.NewHelper(logger),
+	}
+	if h.retryMax < h.retryMin {
+		h.retryMax = h.retryMin
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if h.eventsURL != "" {
+		go h.listen(ctx)
+	}
+	if h.topic != "" || h.eventsURL != "" {
+		h.log.Infof("[IQ] push updates (mqtt topic: %q, events: %q)", h.topic, h.eventsURL)
+	}
+	return h, cancel, nil
+}
+
+// Attach añade un IQ a los avisos push y a /qt.
+func (h *Hub) Attach(iq *IQ) {
+	if h == nil || iq == nil {
+		return
+	}
+	h.mu.Lock()
+	h.iqs = append(h.iqs, iq)
+	h.mu.Unlock()
+}
+
+func (h *Hub) list() []*IQ {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]*IQ(nil), h.iqs...)
+}
+
+// Topic devuelve el topic MQTT de avisos ("" = ninguno).
+func (h *Hub) Topic() string {
+	if h == nil {
+		return ""
+	}
+	return h.topic
+}
+
+// AdminRoles devuelve los roles que pueden usar /qt.
+func (h *Hub) AdminRoles() []string { return h.adminRoles }
+
+// HandleMessage aplica un aviso recibido (topic MQTT o evento SSE). Una tabla
+// vacía no se aplica (un aviso no borra todas las cuotas): se pide al servicio.
+func (h *Hub) HandleMessage(payload []byte) {
+	var ev quotaEvent
+	if len(bytes.TrimSpace(payload)) > 0 {
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			h.log.Warnf("[IQ] bad quota event: %v", err)
+			return
+		}
+	}
+	for _, iq := range h.list() {
+		if !iq.enabled() || (ev.Project != "" && ev.Project != iq.project) {
+			continue
+		}
+		switch {
+		case ev.Items != nil && len(*ev.Items) > 0:
+			iq.applyPush(*ev.Items)
+		case ev.Items != nil:
+			h.log.Warnf("[IQ] empty quota table pushed for %q: refetching instead", iq.project)
+			iq.Notify()
+		default:
+			iq.Notify()
+		}
+	}
+}
+
+// notifyAll pide a todos los IQ que refresquen (p.ej. tras perder avisos).
+func (h *Hub) notifyAll() {
+	for _, iq := range h.list() {
+		if iq.enabled() {
+			iq.Notify()
+		}
+	}
+}
+
+// RefreshResult es el resultado de un refresco manual por transporte.
+type RefreshResult struct {
+	Transport    string `json:"transport"`
+	OK           bool   `json:"ok"`
+	RoutesLoaded int    `json:"routes_loaded"`
+	Error        string `json:"error,omitempty"` // la tabla anterior sigue activa
+}
+
+// Refresh pide las cuotas al servicio para cada IQ (POST /qt).
+func (h *Hub) Refresh(ctx context.Context) []RefreshResult {
+	out := []RefreshResult{}
+	for _, iq := range h.list() {
+		r := RefreshResult{Transport: iq.transport(), OK: true}
+		if err := iq.RefreshOnce(ctx); err != nil {
+			r.OK, r.Error = false, err.Error()
+		}
+		r.RoutesLoaded = iq.QuotasLen()
+		out = append(out, r)
+	}
+	return out
+}
+
+// State devuelve el estado de cada IQ (GET /qt).
+func (h *Hub) State() []State {
+	out := []State{}
+	for _, iq := range h.list() {
+		out = append(out, iq.State())
+	}
+	return out
+}
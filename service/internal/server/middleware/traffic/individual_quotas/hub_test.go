@@ -0,0 +1,57 @@
+package individual_quotas
+
+import (
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func newTestIQ(t *testing.T, items ...quotaItem) (*IQ, *Hub) {
+	t.Helper()
+	qc := &conf.Traffic_Quotas{ServiceUrl: "http://127.0.0.1:1"}
+	hub, cleanup, err := NewHub(&conf.Traffic{Quotas: qc}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cleanup)
+
+	iq := New("p", HTTP, qc, log.DefaultLogger)
+	hub.Attach(iq)
+	if len(items) > 0 {
+		iq.apply(items, time.Now())
+	}
+	return iq, hub
+}
+
+func TestHandleMessage(t *testing.T) {
+	table := []quotaItem{{Project: "p", Route: "/v1/a", Quota: 5}}
+
+	tests := []struct {
+		name    string
+		payload string
+		routes  int  // routes after the message
+		notify  bool // a refetch was requested
+	}{
+		{"table", `{"project":"p","items":[{"project":"p","route":"/v1/a","quota":1},{"project":"p","route":"/v1/b","quota":1}]}`, 2, false},
+		{"empty table refetches", `{"project":"p","items":[]}`, 1, true},
+		{"hint", `{"project":"p"}`, 1, true},
+		{"empty payload", ``, 1, true},
+		{"other project", `{"project":"q","items":[]}`, 1, false},
+		{"bad json", `{`, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iq, hub := newTestIQ(t, table...)
+			hub.HandleMessage([]byte(tt.payload))
+			if got := iq.QuotasLen(); got != tt.routes {
+				t.Errorf("routes = %d, want %d", got, tt.routes)
+			}
+			if got := len(iq.kick) == 1; got != tt.notify {
+				t.Errorf("refetch requested = %v, want %v", got, tt.notify)
+			}
+		})
+	}
+}
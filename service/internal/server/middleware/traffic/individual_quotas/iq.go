@@ -2,19 +2,27 @@ package individual_quotas
 
 import (
 	"context"
+	"math/rand"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"service/internal/conf/v1"
+	"service/internal/server/middleware/traffic"
+	"service/internal/server/utils/ip"
+
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-resty/resty/v2"
 	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 /*
-   Núcleo de IQ: tabla atómica de cuotas (quota + interval),
-   y almacén de token-buckets por ruta.
+   Núcleo de IQ: tabla atómica de cuotas por ruta (quota + interval),
+   token-buckets por ruta (todos los clientes) y por cliente: cada dimensión
+   (usuario, empresa, API key, IP) con sus límites por sujeto y uno por
+   defecto. Los buckets por cliente viven en un almacén acotado (LRU + TTL).
 
    El refresco periódico de cuotas está implementado en refresh.go
 */
@@ -33,18 +41,39 @@ type IQ struct {
 	refreshEvery time.Duration
 	burstFactor  float64
 	strictMatch  bool
+	snapshotPath string        // "" = sin snapshot en disco
+	retryMin     time.Duration // backoff tras un fallo (con jitter)
+	retryMax     time.Duration
+	maxStale     time.Duration // 0 = la última tabla válida no caduca
+
+	// unix nanos de la tabla publicada (descarga, push o snapshot); 0 = ninguna
+	loadedAt atomic.Int64
+	// unix nanos del último refresco correcto (descarga o push); 0 = ninguno
+	refreshedAt atomic.Int64
+	// serializa la publicación de tablas (refresco, push)
+	applyMu sync.Mutex
 
 	// HTTP client
 	rest *resty.Client
 
-	// quotas {ruta → (quota, interval)} (lectura atómica, escritura reemplazando)
-	quotas atomicMap[string, quotaCfg]
+	// buckets compartidos entre réplicas (nil = locales)
+	shared *traffic.Shared
+
+	// dry-run: los rechazos solo se registran (log + métrica)
+	shadow bool
+
+	// quotas {ruta → cuotas de ruta y por cliente} (lectura atómica, escritura reemplazando)
+	quotas atomicMap[string, routeQuota]
 
 	// almacén de limiters por ruta (con mutex simple)
 	mu       sync.Mutex
 	limiters map[string]*rate.Limiter
 
+	// buckets por cliente (ruta + dimensión + sujeto)
+	clients *traffic.Store
+
 	stopCh chan struct{}
+	kick   chan struct{} // refresco inmediato (aviso push), capacidad 1
 
 	serverType ServerType
 	logHelper  *log.Helper
@@ -56,61 +85,175 @@ type quotaCfg struct {
 	Interval int // ventana en segundos (>0)
 }
 
-// New crea IQ con valores por defecto; `project` es obligatorio.
-func New(project string, serverType ServerType, logger log.Logger) *IQ {
+// dimension agrupa los buckets por cliente (quotaItem.KeyBy).
+type dimension string
+
+const (
+	dimRoute   dimension = ""        // un bucket para toda la ruta
+	dimUser    dimension = "user"    // sujeto del principal verificado
+	dimCompany dimension = "company" // tenant del principal
+	dimAPIKey  dimension = "api_key" // id de la API key
+	dimIP      dimension = "ip"      // IP del cliente
+)
+
+// dimensions en orden de evaluación (la más específica primero).
+var dimensions = []dimension{dimAPIKey, dimUser, dimCompany, dimIP}
+
+func parseDimension(s string) (dimension, bool) {
+	switch d := dimension(strings.ToLower(strings.TrimSpace(s))); d {
+	case dimRoute, dimUser, dimCompany, dimAPIKey, dimIP:
+		return d, true
+	}
+	return "", false
+}
+
+// routeQuota son las cuotas de una ruta: la de ruta (nil = sin ella) y las
+// por cliente de cada dimensión.
+type routeQuota struct {
+	route   *quotaCfg
+	clients map[dimension]clientQuota
+}
+
+// clientQuota: cuotas explícitas por sujeto y la de por defecto (nil = los
+// sujetos sin entrada no tienen límite en esta dimensión).
+type clientQuota struct {
+	def      *quotaCfg
+	subjects map[string]quotaCfg
+}
+
+// forSubject devuelve la cuota de un sujeto (explícita o la de por defecto).
+func (c clientQuota) forSubject(s string) (quotaCfg, bool) {
+	if q, ok := c.subjects[s]; ok {
+		return q, true
+	}
+	if c.def != nil {
+		return *c.def, true
+	}
+	return quotaCfg{}, false
+}
+
+// New crea IQ desde traffic.quotas (valores cero = defaults); `project` es
+// obligatorio. Sin service_url las cuotas quedan desactivadas (no-op).
+func New(project string, serverType ServerType, c *conf.Traffic_Quotas, logger log.Logger) *IQ {
 	if project == "" {
 		panic("[INDIVIDUAL_QUOTAS] project is required")
 	}
 
-	url := defaultServiceURL
-	refresh := defaultRefreshEvery
-	burst := defaultBurstFactor
-	strict := defaultStrictMatch
+	timeout := durationOr(c.GetTimeout(), defaultFetchTimeout)
+	burst := c.GetBurstFactor()
+	if burst <= 0 {
+		burst = defaultBurstFactor
+	}
+
+	clientKeys := int(c.GetMaxKeys())
+	if clientKeys <= 0 {
+		clientKeys = defaultClientKeys
+	}
 
 	cli := resty.New()
 	cli.SetRetryCount(1)
+	cli.SetTimeout(timeout)
 
 	iq := &IQ{
-		serviceURL:   strings.TrimRight(url, "/"),
+		serviceURL:   strings.TrimRight(strings.TrimSpace(c.GetServiceUrl()), "/"),
 		project:      project,
 		serverType:   serverType,
-		refreshEvery: refresh,
+		refreshEvery: durationOr(c.GetRefresh(), defaultRefreshEvery),
 		burstFactor:  burst,
-		strictMatch:  strict,
+		strictMatch:  !c.GetPrefixMatch(),
+		snapshotPath: strings.TrimSpace(c.GetSnapshot()),
+		retryMin:     durationOr(c.GetRetryMin(), defaultRetryMin),
+		retryMax:     durationOr(c.GetRetryMax(), defaultRetryMax),
+		maxStale:     durationOr(c.GetMaxStale(), 0),
+		clients:      traffic.NewStore(strings.ToLower(string(serverType))+" iq", clientKeys, durationOr(c.GetKeyIdleTtl(), defaultClientTTL)),
 		rest:         cli,
 		limiters:     make(map[string]*rate.Limiter),
 		stopCh:       make(chan struct{}),
+		kick:         make(chan struct{}, 1),
+	}
+	if iq.retryMax < iq.retryMin {
+		iq.retryMax = iq.retryMin
 	}
 	iq.logHelper = log.NewHelper(logger)
-	iq.quotas.Store(make(map[string]quotaCfg)) // mapa vacío inicial
+	iq.quotas.Store(make(map[string]routeQuota)) // mapa vacío inicial
 	return iq
 }
 
-// Start arranca el refresco (inmediato + cada refreshEvery).
-// La lógica de refresco está en refresh.go (método refreshOnce).
-func (iq *IQ) Start(ctx context.Context) {
-	go func() {
-		iq.RefreshOnce(ctx) // primer intento al arrancar
+// enabled: hay proyecto y servicio de cuotas configurado.
+func (iq *IQ) enabled() bool { return iq.project != "" && iq.serviceURL != "" }
 
-		t := time.NewTicker(iq.refreshEvery)
-		defer t.Stop()
+// Start restaura el snapshot (si hay) y arranca el refresco: inmediato y luego
+// cada refreshEvery o al recibir un aviso (Notify); tras un fallo se
+// reintenta con backoff exponencial y jitter manteniendo la última tabla válida.
+// La lógica de refresco está en refresh.go (método RefreshOnce).
+func (iq *IQ) Start(ctx context.Context) {
+	if !iq.enabled() {
+		iq.logHelper.Infof("[%s] [IQ] [SKIPPED] traffic.quotas.service_url is empty", iq.serverType)
+		return
+	}
+	iq.loadSnapshot()
 
+	go func() {
+		failures := 0
 		for {
+			var wait time.Duration
+			err := iq.RefreshOnce(ctx)
+			failures, wait = iq.nextRefresh(failures, err)
+			if err != nil {
+				iq.logHelper.Warnf("[%s] [IQ] retry %d in %s", iq.serverType, failures, wait)
+			}
+
+			t := time.NewTimer(wait)
 			select {
 			case <-ctx.Done():
+				t.Stop()
 				return
 			case <-iq.stopCh:
+				t.Stop()
 				return
+			case <-iq.kick:
+				t.Stop()
 			case <-t.C:
-				iq.RefreshOnce(ctx)
 			}
 		}
 	}()
 }
 
+// Notify pide un refresco inmediato (avisos coalescidos: como mucho uno pendiente).
+func (iq *IQ) Notify() {
+	select {
+	case iq.kick <- struct{}{}:
+	default:
+	}
+}
+
+// nextRefresh devuelve los fallos seguidos tras un refresco y la espera
+// hasta el siguiente: backoff creciente mientras falla, refreshEvery (y
+// contador a cero) tras un refresco correcto.
+func (iq *IQ) nextRefresh(failures int, err error) (int, time.Duration) {
+	if err != nil {
+		failures++
+		return failures, iq.retryAfter(failures)
+	}
+	return 0, iq.refreshEvery
+}
+
+// retryAfter devuelve la espera tras n fallos seguidos (ver backoff).
+func (iq *IQ) retryAfter(n int) time.Duration {
+	return backoff(iq.retryMin, iq.retryMax, n)
+}
+
 // Stop detiene el bucle de refresco.
 func (iq *IQ) Stop() { close(iq.stopCh) }
 
+// UseShared comparte los buckets de cuotas entre réplicas (Redis); si el
+// almacén falla se usan los limiters locales. nil = solo locales.
+func (iq *IQ) UseShared(s *traffic.Shared) { iq.shared = s }
+
+// UseShadow activa el modo shadow: una petición que excede la cuota se
+// registra (log + traffic_decisions_total{result="shadow"}) y sigue.
+func (iq *IQ) UseShadow(on bool) { iq.shadow = on }
+
 // QuotasLen devuelve el número de rutas con cuota cargada actualmente.
 func (iq *IQ) QuotasLen() int {
 	qm := iq.quotas.Load()
@@ -120,12 +263,9 @@ func (iq *IQ) QuotasLen() int {
 // ensureLimiter crea/actualiza el limiter para la ruta dada.
 // Interpreta la cuota como "Quota peticiones por Interval segundos".
 func (iq *IQ) ensureLimiter(route string, quota, intervalSec int) {
-	// velocidad (eventos/segundo) para el token-bucket
-	ratePerSec := float64(quota) / float64(intervalSec)
-
-	// burst: capacidad del bucket. Usamos 'quota * BurstFactor' para permitir picos cortos.
+	ratePerSec, burst := iq.bucket(quotaCfg{Quota: quota, Interval: intervalSec})
 	desiredR := rate.Limit(ratePerSec)
-	desiredB := int(maxInt(1, int(float64(quota)*iq.burstFactor)))
+	desiredB := burst
 
 	iq.mu.Lock()
 	defer iq.mu.Unlock()
@@ -139,12 +279,19 @@ func (iq *IQ) ensureLimiter(route string, quota, intervalSec int) {
 	iq.limiters[route] = rate.NewLimiter(desiredR, desiredB)
 }
 
-// gcLimitersKeys elimina limiters de rutas que ya no están en la tabla.
-func (iq *IQ) gcLimitersKeys(next map[string]quotaCfg) {
+// bucket traduce una cuota a token-bucket: velocidad (eventos/segundo) y
+// capacidad ('quota * BurstFactor' para permitir picos cortos).
+func (iq *IQ) bucket(q quotaCfg) (float64, int) {
+	return float64(q.Quota) / float64(normIntervalSec(q.Interval)), maxInt(1, int(float64(q.Quota)*iq.burstFactor))
+}
+
+// gcLimitersKeys elimina limiters de rutas que ya no tienen cuota de ruta.
+// Los buckets por cliente caducan solos (TTL del almacén).
+func (iq *IQ) gcLimitersKeys(next map[string]routeQuota) {
 	iq.mu.Lock()
 	defer iq.mu.Unlock()
 	for r := range iq.limiters {
-		if _, keep := next[r]; !keep {
+		if rq, keep := next[r]; !keep || rq.route == nil {
 			delete(iq.limiters, r)
 		}
 	}
@@ -157,68 +304,168 @@ func (iq *IQ) getLimiter(route string) *rate.Limiter {
 	return iq.limiters[route]
 }
 
-// allow chequea si un path/método puede pasar según la cuota/limiter.
-func (iq *IQ) allow(route string) bool {
-	route = normRoute(route)
-	if route == "" {
-		return true
-	}
+// verdict es el resultado de las cuotas de una petición.
+type verdict struct {
+	traffic.Decision
+	match string // ruta de cuota ("" = sin cuota)
+	key   string // bucket que decide (el que rechaza o el más restrictivo)
+}
+
+// take chequea si una llamada puede pasar según sus cuotas: primero las
+// por cliente (un cliente rechazado no gasta la cuota de la ruta), luego la
+// de ruta. Los buckets no se pueden consultar sin consumir (GCRA en Redis),
+// así que el orden tiene un coste: una llamada que rechaza la ruta (o una
+// dimensión posterior) ya gastó sus tokens de cliente. Se acepta porque ese
+// cliente solo pierde su propia cuota; al revés, un cliente ruidoso agotaría
+// la ruta de todos. Sin cuota devuelve traffic.Allow() (sin cabeceras
+// RateLimit-*).
+// El ctx devuelto lleva la identidad verificada del cliente (si se resolvió).
+func (iq *IQ) take(ctx context.Context, c call) (context.Context, verdict) {
+	v := verdict{Decision: traffic.Allow()}
 
 	qm := iq.quotas.Load()
 	if len(qm) == 0 {
-		return true
+		return ctx, v
 	}
 
-	// match exacto o por prefijo (mejor coincidencia)
-	if iq.strictMatch {
-		if _, ok := qm[route]; !ok {
-			return true
+	match := iq.match(qm, c)
+	if match == "" {
+		return ctx, v
+	}
+	v.match = match
+	rq := qm[match]
+
+	// por cliente: sujeto de cada dimensión (anónimos no cuentan en user/company/api_key)
+	var who traffic.Subjects
+	resolved := false
+	for _, dim := range rq.dimensions() {
+		var subject string
+		if dim == dimIP {
+			if subject = server_utils_ip.GetIP(ctx); subject == server_utils_ip.Unknown {
+				subject = ""
+			}
+		} else {
+			if !resolved {
+				ctx, who = traffic.Caller(ctx)
+				resolved = true
+			}
+			subject = subjectOf(who, dim)
 		}
-		lim := iq.getLimiter(route)
-		if lim == nil {
-			return true
+		q, ok := rq.clients[dim].forSubject(subject)
+		if subject == "" || !ok {
+			continue
+		}
+		key := iq.key(match) + ":" + string(dim) + ":" + subject
+		if !v.merge(iq.takeClient(ctx, key, q), key) {
+			return ctx, v
 		}
-		return lim.Allow()
 	}
 
-	best := ""
-	for k := range qm {
-		if strings.HasPrefix(route, k) && len(k) > len(best) {
-			best = k
+	// cuota de ruta: bucket compartido; si falla, el limiter local
+	if rq.route == nil {
+		return ctx, v
+	}
+	key := iq.key(match)
+	if iq.shared != nil {
+		rps, burst := iq.bucket(*rq.route)
+		if d, err := iq.shared.Take(ctx, key, rps, burst); err == nil {
+			v.merge(d, key)
+			return ctx, v
 		}
 	}
-	if best == "" {
-		return true
+	if lim := iq.getLimiter(match); lim != nil {
+		v.merge(traffic.Take(lim), key)
 	}
-	lim := iq.getLimiter(best)
-	if lim == nil {
-		return true
+	return ctx, v
+}
+
+// takeClient consume un token del bucket de un cliente (compartido entre
+// réplicas si hay; si falla, el local).
+func (iq *IQ) takeClient(ctx context.Context, key string, q quotaCfg) traffic.Decision {
+	rps, burst := iq.bucket(q)
+	if iq.shared != nil {
+		if d, err := iq.shared.Take(ctx, key, rps, burst); err == nil {
+			return d
+		}
 	}
-	return lim.Allow()
+	return iq.clients.Take(key, rps, burst)
 }
 
-// helpers locales (pueden vivir también en util.go)
+// merge incorpora la decisión de un bucket: un rechazo decide; si pasa, se
+// queda la más restrictiva (cabeceras RateLimit-*). Devuelve d.Allowed.
+func (v *verdict) merge(d traffic.Decision, key string) bool {
+	switch {
+	case !d.Allowed:
+		v.Decision, v.key = d, key
+	case d.Limit > 0 && (v.Limit == 0 || d.Remaining < v.Remaining):
+		v.Decision, v.key = d, key
+	}
+	return d.Allowed
+}
 
-func normIntervalSec(v int) int {
-	if v <= 0 {
-		return 1
+// subjectOf devuelve el sujeto verificado del cliente en una dimensión.
+func subjectOf(who traffic.Subjects, dim dimension) string {
+	switch dim {
+	case dimUser:
+		return who.User
+	case dimCompany:
+		return who.Company
+	case dimAPIKey:
+		return who.APIKey
 	}
-	return v
+	return ""
 }
 
-// normRoute normaliza: sin query, sin slashes extra, con "/" inicial, sin trailing "/".
-func normRoute(p string) string {
-	p = strings.TrimSpace(p)
-	if p == "" {
-		return ""
+// dimensions devuelve las dimensiones con cuota por cliente, en orden.
+func (rq routeQuota) dimensions() []dimension {
+	if len(rq.clients) == 0 {
+		return nil
+	}
+	out := make([]dimension, 0, len(rq.clients))
+	for _, d := range dimensions {
+		if _, ok := rq.clients[d]; ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// key es la clave del bucket de una ruta de cuota (compartida entre réplicas).
+func (iq *IQ) key(match string) string {
+	return "iq:" + string(iq.serverType) + ":" + match
+}
+
+// helpers locales (pueden vivir también en util.go)
+
+// backoff devuelve la espera tras n fallos seguidos: min * 2^(n-1) hasta max,
+// con jitter en [d/2, d] para no sincronizar réplicas.
+func backoff(min, max time.Duration, n int) time.Duration {
+	d := min
+	for i := 1; i < n && d < max; i++ {
+		d *= 2
 	}
-	if !strings.HasPrefix(p, "/") {
-		p = "/" + p
+	if d > max {
+		d = max
 	}
-	for len(p) > 1 && strings.HasSuffix(p, "/") {
-		p = strings.TrimSuffix(p, "/")
+	if half := d / 2; half > 0 {
+		d = half + time.Duration(rand.Int63n(int64(half)+1))
 	}
-	return p
+	return d
+}
+
+// durationOr devuelve d, o def si no está configurada (o no es positiva).
+func durationOr(d *durationpb.Duration, def time.Duration) time.Duration {
+	if d == nil || d.AsDuration() <= 0 {
+		return def
+	}
+	return d.AsDuration()
+}
+
+func normIntervalSec(v int) int {
+	if v <= 0 {
+		return 1
+	}
+	return v
 }
 
 func maxInt(a, b int) int {
@@ -0,0 +1,173 @@
+package individual_quotas
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service/internal/server/middleware/traffic"
+	"service/internal/server/utils/ip"
+)
+
+type whoKey struct{}
+
+// useCallers resolves the caller from the Subjects stored under whoKey.
+func useCallers(t *testing.T) {
+	t.Helper()
+	traffic.UseIdentity(func(ctx context.Context) (context.Context, traffic.Subjects) {
+		s, _ := ctx.Value(whoKey{}).(traffic.Subjects)
+		return ctx, s
+	})
+	t.Cleanup(func() { traffic.UseIdentity(nil) })
+}
+
+// caller returns a request context of a verified client (ip is the client address).
+func caller(ip string, who traffic.Subjects) context.Context {
+	ctx := context.Background()
+	if ip != "" {
+		ctx = server_utils_ip.NewContext(ctx, ip)
+	}
+	return context.WithValue(ctx, whoKey{}, who)
+}
+
+func TestTakeClientBeforeRoute(t *testing.T) {
+	iq, _ := newTestIQ(t)
+	iq.burstFactor = 1
+	iq.apply([]quotaItem{
+		{Project: "p", Route: "/v1/a", Quota: 3, Interval: 60},
+		{Project: "p", Route: "/v1/a", Quota: 1, Interval: 60, KeyBy: "ip"},
+	}, time.Now())
+
+	c := call{method: "GET", template: "/v1/a", path: "/v1/a"}
+	steps := []struct {
+		ip      string
+		allowed bool
+		key     string // bucket that rejects
+	}{
+		{"198.51.100.1", true, ""},
+		{"198.51.100.1", false, "iq:HTTP:/v1/a:ip:198.51.100.1"}, // does not spend the route quota
+		{"198.51.100.2", true, ""},
+		{"198.51.100.3", true, ""},
+		{"198.51.100.4", false, "iq:HTTP:/v1/a"},
+	}
+	for i, s := range steps {
+		ctx := server_utils_ip.NewContext(context.Background(), s.ip)
+		_, v := iq.take(ctx, c)
+		if v.Allowed != s.allowed || (!s.allowed && v.key != s.key) {
+			t.Errorf("call %d from %s = allowed %v by %q, want %v by %q", i+1, s.ip, v.Allowed, v.key, s.allowed, s.key)
+		}
+	}
+}
+
+func TestTakeClientDefaults(t *testing.T) {
+	useCallers(t)
+	c := call{method: "GET", template: "/v1/a", path: "/v1/a"}
+
+	tests := []struct {
+		keyBy   string
+		a, b    context.Context // two clients of the dimension
+		subject string          // subject of a
+		anon    context.Context // a caller without subject in the dimension
+	}{
+		{"user", caller("", traffic.Subjects{User: "alice"}), caller("", traffic.Subjects{User: "bob"}), "alice", caller("198.51.100.1", traffic.Subjects{})},
+		{"company", caller("", traffic.Subjects{Company: "7"}), caller("", traffic.Subjects{Company: "8"}), "7", caller("", traffic.Subjects{User: "alice"})},
+		{"api_key", caller("", traffic.Subjects{APIKey: "sk_a"}), caller("", traffic.Subjects{APIKey: "sk_b"}), "sk_a", caller("", traffic.Subjects{User: "alice"})},
+		{"ip", caller("198.51.100.1", traffic.Subjects{}), caller("198.51.100.2", traffic.Subjects{}), "198.51.100.1", caller("", traffic.Subjects{User: "alice"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyBy, func(t *testing.T) {
+			iq, _ := newTestIQ(t)
+			iq.burstFactor = 1
+			iq.apply([]quotaItem{{Project: "p", Route: "/v1/a", Quota: 2, Interval: 60, KeyBy: tt.keyBy}}, time.Now())
+
+			for i := 0; i < 2; i++ {
+				if _, v := iq.take(tt.a, c); !v.Allowed {
+					t.Fatalf("call %d of a rejected within the default quota", i+1)
+				}
+			}
+			_, v := iq.take(tt.a, c)
+			if want := "iq:HTTP:/v1/a:" + tt.keyBy + ":" + tt.subject; v.Allowed || v.key != want {
+				t.Fatalf("third call of a = allowed %v by %q, want rejected by %q", v.Allowed, v.key, want)
+			}
+			if _, v := iq.take(tt.b, c); !v.Allowed {
+				t.Fatal("another client shares the bucket of a")
+			}
+			for i := 0; i < 5; i++ {
+				if _, v := iq.take(tt.anon, c); !v.Allowed {
+					t.Fatalf("call %d without a %s subject limited", i+1, tt.keyBy)
+				}
+			}
+		})
+	}
+}
+
+func TestTakeClientWithoutEntry(t *testing.T) {
+	useCallers(t)
+	iq, _ := newTestIQ(t)
+	iq.burstFactor = 1
+	iq.apply([]quotaItem{
+		{Project: "p", Route: "/v1/a", Quota: 3, Interval: 60, KeyBy: "user", Subject: "alice"},
+		{Project: "p", Route: "/v1/b", Quota: 3, Interval: 60, KeyBy: "user", Subject: "alice"},
+		{Project: "p", Route: "/v1/b", Quota: 1, Interval: 60, KeyBy: "user"},
+	}, time.Now())
+
+	count := func(ctx context.Context, route string) int {
+		c := call{method: "GET", template: route, path: route}
+		n := 0
+		for i := 0; i < 10; i++ {
+			if _, v := iq.take(ctx, c); v.Allowed {
+				n++
+			}
+		}
+		return n
+	}
+	alice := caller("", traffic.Subjects{User: "alice"})
+	bob := caller("", traffic.Subjects{User: "bob"})
+
+	if n := count(alice, "/v1/a"); n != 3 {
+		t.Errorf("alice on /v1/a: %d allowed, want 3 (own entry)", n)
+	}
+	if n := count(bob, "/v1/a"); n != 10 {
+		t.Errorf("bob on /v1/a: %d allowed, want 10 (no entry and no default)", n)
+	}
+	if n := count(alice, "/v1/b"); n != 3 {
+		t.Errorf("alice on /v1/b: %d allowed, want 3 (own entry over the default)", n)
+	}
+	if n := count(bob, "/v1/b"); n != 1 {
+		t.Errorf("bob on /v1/b: %d allowed, want 1 (default)", n)
+	}
+}
+
+func TestTakeClientKeysDoNotCollide(t *testing.T) {
+	useCallers(t)
+	iq, _ := newTestIQ(t)
+	iq.burstFactor = 1
+	iq.apply([]quotaItem{
+		{Project: "p", Route: "/v1/a", Quota: 1, Interval: 60, KeyBy: "user"},
+		{Project: "p", Route: "/v1/a", Quota: 1, Interval: 60, KeyBy: "company"},
+		{Project: "p", Route: "/v1/a", Quota: 1, Interval: 60, KeyBy: "api_key"},
+		{Project: "p", Route: "/v1/b", Quota: 1, Interval: 60, KeyBy: "user"},
+	}, time.Now())
+	a := call{method: "GET", template: "/v1/a", path: "/v1/a"}
+	b := call{method: "GET", template: "/v1/b", path: "/v1/b"}
+
+	// The same subject in different dimensions and routes has its own bucket.
+	steps := []struct {
+		ctx     context.Context
+		c       call
+		allowed bool
+	}{
+		{caller("", traffic.Subjects{User: "42"}), a, true},
+		{caller("", traffic.Subjects{Company: "42"}), a, true},
+		{caller("", traffic.Subjects{APIKey: "42"}), a, true},
+		{caller("", traffic.Subjects{User: "42"}), b, true},
+		{caller("", traffic.Subjects{User: "42"}), a, false},
+		{caller("", traffic.Subjects{Company: "42"}), a, false},
+		{caller("", traffic.Subjects{User: "43"}), a, true},
+	}
+	for i, s := range steps {
+		if _, v := iq.take(s.ctx, s.c); v.Allowed != s.allowed {
+			t.Errorf("step %d: allowed = %v, want %v (key %q)", i+1, v.Allowed, s.allowed, v.key)
+		}
+	}
+}
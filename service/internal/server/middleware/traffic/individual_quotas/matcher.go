@@ -0,0 +1,130 @@
+package individual_quotas
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+/*
+   Búsqueda de la cuota de una llamada. Las rutas de la tabla se normalizan
+   al publicarla (normRoute), así cada búsqueda son unas pocas consultas al
+   mapa, sin recorrer la tabla. Por orden:
+   1. operación Kratos exacta: "/pkg.Service/Method" (HTTP y gRPC);
+   2. servicio completo: "/pkg.Service/*";
+   3. plantilla HTTP con método: "GET /v1/example/{id}", y sin él: "/v1/example/{id}";
+   4. path real, con o sin método: "GET /v1/example/42", "/v1/example/42";
+   5. con prefix_match: prefijos del path real por segmentos ("/v1/example").
+*/
+
+// call identifica una llamada para buscar su cuota.
+type call struct {
+	operation string // "/pkg.Service/Method"
+	method    string // HTTP: "GET", "POST"...
+	template  string // HTTP: plantilla de la ruta, "/v1/example/{id}"
+	path      string // HTTP: path real
+}
+
+// callFromContext toma la llamada del transporte Kratos del contexto.
+func callFromContext(ctx context.Context) call {
+	var c call
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return c
+	}
+	c.operation = tr.Operation()
+	if ht, ok := tr.(khttp.Transporter); ok {
+		c.template = normPath(ht.PathTemplate())
+		if r := ht.Request(); r != nil {
+			c.method = r.Method
+			c.path = normPath(r.URL.Path)
+		}
+	}
+	return c
+}
+
+// match devuelve la ruta de la tabla que aplica a c ("" = ninguna).
+func (iq *IQ) match(qm map[string]routeQuota, c call) string {
+	has := func(k string) bool {
+		if k == "" {
+			return false
+		}
+		_, ok := qm[k]
+		return ok
+	}
+	withMethod := func(p string) string {
+		if c.method == "" || p == "" {
+			return ""
+		}
+		return c.method + " " + p
+	}
+
+	if has(c.operation) {
+		return c.operation
+	}
+	if i := strings.LastIndexByte(c.operation, '/'); i > 0 {
+		if svc := c.operation[:i+1] + "*"; has(svc) {
+			return svc
+		}
+	}
+	for _, k := range []string{withMethod(c.template), c.template, withMethod(c.path), c.path} {
+		if has(k) {
+			return k
+		}
+	}
+	if !iq.strictMatch {
+		for p := parentPath(c.path); p != ""; p = parentPath(p) {
+			if k := withMethod(p); has(k) {
+				return k
+			}
+			if has(p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// normRoute normaliza una ruta de cuota: "MÉTODO /path" (método en
+// mayúsculas) o "/path"; las operaciones gRPC ya tienen esa forma.
+func normRoute(r string) string {
+	r = strings.TrimSpace(r)
+	method := ""
+	if i := strings.IndexAny(r, " \t"); i > 0 {
+		method, r = strings.ToUpper(r[:i]), strings.TrimSpace(r[i+1:])
+	}
+	p := normPath(r)
+	if method == "" || p == "" {
+		return p
+	}
+	return method + " " + p
+}
+
+// normPath normaliza: sin query, con "/" inicial, sin trailing "/".
+func normPath(p string) string {
+	p = strings.TrimSpace(p)
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		p = p[:i]
+	}
+	if p == "" {
+		return ""
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	for len(p) > 1 && strings.HasSuffix(p, "/") {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+// parentPath quita el último segmento ("/v1/example/42" → "/v1/example"; "/v1" → "").
+func parentPath(p string) string {
+	i := strings.LastIndexByte(p, '/')
+	if i <= 0 {
+		return ""
+	}
+	return p[:i]
+}
@@ -0,0 +1,72 @@
+package individual_quotas
+
+import "testing"
+
+func TestMatchPrecedence(t *testing.T) {
+	all := []string{
+		"/api.example.v1.Examplev1Service/Get",
+		"/api.example.v1.Examplev1Service/*",
+		"GET /v1/example/{id}",
+		"/v1/example/{id}",
+		"GET /v1/example/42",
+		"/v1/example/42",
+		"GET /v1/example",
+		"/v1",
+	}
+	c := call{
+		operation: "/api.example.v1.Examplev1Service/Get",
+		method:    "GET",
+		template:  "/v1/example/{id}",
+		path:      "/v1/example/42",
+	}
+
+	// removing the winner each time walks the whole precedence order
+	iq := &IQ{}
+	qm := make(map[string]routeQuota, len(all))
+	for _, r := range all {
+		qm[normRoute(r)] = routeQuota{}
+	}
+	for _, want := range all {
+		if got := iq.match(qm, c); got != want {
+			t.Fatalf("match() = %q, want %q", got, want)
+		}
+		delete(qm, want)
+	}
+	if got := iq.match(qm, c); got != "" {
+		t.Errorf("match() on an empty table = %q", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []string
+		c      call
+		strict bool
+		want   string
+	}{
+		{"grpc operation", []string{"/pkg.S/M"}, call{operation: "/pkg.S/M"}, false, "/pkg.S/M"},
+		{"grpc service", []string{"/pkg.S/*"}, call{operation: "/pkg.S/M"}, false, "/pkg.S/*"},
+		{"other service", []string{"/pkg.T/*"}, call{operation: "/pkg.S/M"}, false, ""},
+		{"method normalized", []string{"post /v1/items/"}, call{method: "POST", path: "/v1/items"}, false, "POST /v1/items"},
+		{"other method", []string{"POST /v1/items"}, call{method: "GET", path: "/v1/items"}, false, ""},
+		{"query ignored", []string{"/v1/items?x=1"}, call{method: "GET", path: "/v1/items"}, false, "/v1/items"},
+		{"prefix by segment", []string{"/v1/items"}, call{method: "GET", path: "/v1/items/7/parts"}, false, "/v1/items"},
+		{"prefix needs a whole segment", []string{"/v1/item"}, call{method: "GET", path: "/v1/items/7"}, false, ""},
+		{"longest prefix first", []string{"/v1", "GET /v1/items"}, call{method: "GET", path: "/v1/items/7"}, false, "GET /v1/items"},
+		{"strict match", []string{"/v1/items"}, call{method: "GET", path: "/v1/items/7"}, true, ""},
+		{"strict exact path", []string{"/v1/items/7"}, call{method: "GET", path: "/v1/items/7"}, true, "/v1/items/7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qm := make(map[string]routeQuota, len(tt.routes))
+			for _, r := range tt.routes {
+				qm[normRoute(r)] = routeQuota{}
+			}
+			iq := &IQ{strictMatch: tt.strict}
+			if got := iq.match(qm, tt.c); got != tt.want {
+				t.Errorf("match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package individual_quotas
+
+import (
+	"context"
+	"strings"
+
+	"service/internal/server/middleware/traffic"
+	"service/internal/server/utils/ip"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de IQ; las decisiones van a traffic_decisions_total{reason="iq"}.
+var quotaRoutes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "traffic_iq_routes",
+	Help: "Routes with an individual quota currently loaded.",
+}, []string{"transport"})
+
+// transport es la etiqueta de métricas ("http", "grpc"), como traffic.Builder.
+func (iq *IQ) transport() string { return strings.ToLower(string(iq.serverType)) }
+
+// enforce cuenta la decisión (si la ruta tenía cuota) y dice si hay que
+// rechazar. En modo shadow el rechazo solo se registra y la petición sigue.
+func (iq *IQ) enforce(ctx context.Context, op string, v verdict) bool {
+	switch {
+	case v.Allowed:
+		if v.Limit > 0 {
+			traffic.Record(iq.transport(), op, traffic.ReasonIQ, traffic.ResultPass)
+		}
+		return false
+	case iq.shadow:
+		traffic.Record(iq.transport(), op, traffic.ReasonIQ, traffic.ResultShadow)
+		iq.logHelper.Warnf("[INDIVIDUAL_QUOTAS] [SHADOW] would reject: transport=%s operation=%s route=%s key=%s ip=%s",
+			iq.transport(), op, v.match, v.key, server_utils_ip.GetIP(ctx))
+		return false
+	default:
+		traffic.Record(iq.transport(), op, traffic.ReasonIQ, traffic.ResultReject)
+		return true
+	}
+}
@@ -3,9 +3,13 @@ package individual_quotas
 // Modelos de red (JSON) usados al descargar las cuotas.
 type quotaItem struct {
 	Project  string `json:"project"`
-	Route    string `json:"route"`
+	Route    string `json:"route"`    // "/pkg.Service/Method", "/pkg.Service/*", "GET /v1/example/{id}" or a path
 	Quota    int    `json:"quota"`    // RPS
 	Interval int    `json:"interval"` // Interval in seconds (0 = 1 second)
+	// Per-client quota: dimension ("user", "company", "api_key", "ip"; "" =
+	// one bucket for the route) and subject ("" = default of the dimension).
+	KeyBy   string `json:"key_by,omitempty"`
+	Subject string `json:"subject,omitempty"`
 }
 
 type quotaResponse struct {
@@ -2,32 +2,31 @@ package individual_quotas
 
 import (
 	"context"
-	"net/http"
 
-	kratosErr "github.com/go-kratos/kratos/v2/errors"
+	"service/internal/server/middleware/traffic"
+
 	"github.com/go-kratos/kratos/v2/middleware"
 	khttp "github.com/go-kratos/kratos/v2/transport/http"
-	"google.golang.org/grpc/metadata"
 )
 
 /*
-   Middleware HTTP: aplica cuota por endpoint (RPS).
-   Si no hay proyecto configurado — no-op.
+   Middleware HTTP: aplica cuota por endpoint (RPS), de ruta y por cliente,
+   buscada por operación, plantilla de ruta (con método) o path (ver matcher.go).
+   Sin proyecto o servicio de cuotas configurado — no-op.
 */
 
 func (iq *IQ) HTTP() middleware.Middleware {
-	if iq.project == "" {
+	if !iq.enabled() {
 		return passthrough()
 	}
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			hreq, ok := khttp.RequestFromServerContext(ctx)
-			if !ok {
+			if _, ok := khttp.RequestFromServerContext(ctx); !ok {
 				return next(ctx, req)
 			}
-			path := hreq.URL.Path
-			if !iq.allow(path) {
-				return nil, kratosErr.New(http.StatusTooManyRequests, "IQ_RATE_LIMITED", "too many requests for this endpoint")
+			ctx, err := iq.check(ctx, callFromContext(ctx))
+			if err != nil {
+				return nil, err
 			}
 			return next(ctx, req)
 		}
@@ -35,33 +34,39 @@ func (iq *IQ) HTTP() middleware.Middleware {
 }
 
 /*
-   Middleware gRPC: limita por método full path (ej.: "/pkg.Service/Method").
-   Si no hay proyecto configurado — no-op.
+   Middleware gRPC: limita por operación Kratos (ej.: "/pkg.Service/Method"
+   o "/pkg.Service/*").
+   Sin proyecto o servicio de cuotas configurado — no-op.
 */
 
 func (iq *IQ) GRPC() middleware.Middleware {
-	if iq.project == "" {
+	if !iq.enabled() {
 		return passthrough()
 	}
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			method := grpcMethod(ctx)
-			if method != "" && !iq.allow(method) {
-				return nil, kratosErr.New(http.StatusTooManyRequests, "IQ_RATE_LIMITED", "too many requests for this endpoint")
+			if c := callFromContext(ctx); c.operation != "" {
+				var err error
+				if ctx, err = iq.check(ctx, c); err != nil {
+					return nil, err
+				}
 			}
 			return next(ctx, req)
 		}
 	}
 }
 
-// grpcMethod intenta extraer ":path" del metadata entrante (p.ej. "/pkg.Svc/Method").
-func grpcMethod(ctx context.Context) string {
-	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if v := md.Get(":path"); len(v) > 0 && v[0] != "" {
-			return v[0]
-		}
+// check aplica las cuotas de c: 429 si se excede (salvo en shadow), si no
+// añade las cabeceras RateLimit-*.
+func (iq *IQ) check(ctx context.Context, c call) (context.Context, error) {
+	ctx, v := iq.take(ctx, c)
+	if iq.enforce(ctx, c.operation, v) {
+		return ctx, traffic.Reject(ctx, "IQ_RATE_LIMITED", "too many requests for this endpoint", v.Decision)
+	}
+	if v.Allowed {
+		traffic.SetHeaders(ctx, v.Decision)
 	}
-	return ""
+	return ctx, nil
 }
 
 func passthrough() middleware.Middleware {
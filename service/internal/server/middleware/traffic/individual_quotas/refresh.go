@@ -2,23 +2,54 @@ package individual_quotas
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
 /*
-   Refresco de cuotas: pedir al servicio externo, validar respuesta,
-   reconstruir la tabla y limiters, o vaciar todo en caso de error.
+   Refresco de cuotas: pedir al servicio externo, validar respuesta y
+   reconstruir la tabla y limiters. Si falla se mantiene la última tabla
+   válida (last known good) hasta max_stale; luego se vacía (fail-open).
+   Además del periodo, los avisos push (hub.go) refrescan al momento.
 */
 
-// refreshOnce baja cuotas y actualiza limiters.
-// Si falla, se deja la tabla vacía (y se limpian limiters).
-func (iq *IQ) RefreshOnce(ctx context.Context) {
-	if iq.project == "" {
-		// nada que hacer si no hay proyecto
-		return
+// RefreshOnce baja cuotas y actualiza limiters (y el snapshot en disco).
+// Si falla, se mantiene la tabla actual y se devuelve el error.
+func (iq *IQ) RefreshOnce(ctx context.Context) error {
+	if !iq.enabled() {
+		// nada que hacer sin proyecto o servicio de cuotas
+		return nil
+	}
+
+	items, err := iq.fetch(ctx)
+	if err != nil {
+		iq.logHelper.Errorf("[%s] [IQ] fetch error: %v (keeping last known good: %d routes)", iq.serverType, err, iq.QuotasLen())
+		iq.expireStale()
+		return err
 	}
 
+	now := time.Now()
+	n := iq.apply(items, now)
+	iq.refreshedAt.Store(now.UnixNano())
+	iq.saveSnapshot(items, now)
+	iq.logHelper.Infof("[%s] [IQ] quotas applied: %d routes", iq.serverType, n)
+	return nil
+}
+
+// applyPush publica una tabla completa recibida por push (MQTT / SSE).
+func (iq *IQ) applyPush(items []quotaItem) {
+	now := time.Now()
+	n := iq.apply(items, now)
+	iq.refreshedAt.Store(now.UnixNano())
+	iq.saveSnapshot(items, now)
+	iq.logHelper.Infof("[%s] [IQ] quotas pushed: %d routes", iq.serverType, n)
+}
+
+// fetch pide las cuotas del proyecto al servicio.
+func (iq *IQ) fetch(ctx context.Context) ([]quotaItem, error) {
 	var body quotaResponse
 	resp, err := iq.rest.R().
 		SetContext(ctx).
@@ -26,32 +57,59 @@ func (iq *IQ) RefreshOnce(ctx context.Context) {
 		SetResult(&body).
 		Get(iq.serviceURL + "/qt")
 
-	// Error de red / contexto / decode o HTTP no-2xx: vaciamos cuotas
+	// Error de red / contexto / decode o HTTP no-2xx
 	if err != nil {
-		iq.logHelper.Errorf("[%s] [IQ] fetch error: %v", iq.serverType, err)
-		iq.setEmptyQuotas()
-		return
+		return nil, err
 	}
 	if resp == nil || resp.IsError() {
 		status := ""
 		if resp != nil {
 			status = resp.Status()
 		}
-		iq.logHelper.Errorf("[%s] [IQ] bad HTTP status: %s", iq.serverType, status)
-		iq.setEmptyQuotas()
-		return
+		return nil, fmt.Errorf("bad HTTP status: %s", status)
 	}
+	return body.Items, nil
+}
+
+// apply construye y publica la tabla de items (descargada en `at`);
+// devuelve el número de rutas.
+func (iq *IQ) apply(items []quotaItem, at time.Time) int {
+	iq.applyMu.Lock()
+	defer iq.applyMu.Unlock()
 
 	// Construimos la nueva tabla
-	next := make(map[string]quotaCfg, len(body.Items))
-	for _, it := range body.Items {
+	next := make(map[string]routeQuota, len(items))
+	for _, it := range items {
 		route := normRoute(it.Route)
 		if route == "" || it.Quota <= 0 {
 			continue
 		}
-		intSec := normIntervalSec(it.Interval)
-		next[route] = quotaCfg{Quota: it.Quota, Interval: intSec}
-		iq.ensureLimiter(route, it.Quota, intSec)
+		dim, ok := parseDimension(it.KeyBy)
+		if !ok {
+			iq.logHelper.Warnf("[%s] [IQ] quota for %s with unknown key_by %q ignored", iq.serverType, route, it.KeyBy)
+			continue
+		}
+		q := quotaCfg{Quota: it.Quota, Interval: normIntervalSec(it.Interval)}
+		rq := next[route]
+		if dim == dimRoute {
+			rq.route = &q
+			iq.ensureLimiter(route, q.Quota, q.Interval)
+		} else {
+			if rq.clients == nil {
+				rq.clients = make(map[dimension]clientQuota)
+			}
+			cq := rq.clients[dim]
+			if subject := strings.TrimSpace(it.Subject); subject == "" {
+				cq.def = &q
+			} else {
+				if cq.subjects == nil {
+					cq.subjects = make(map[string]quotaCfg)
+				}
+				cq.subjects[subject] = q
+			}
+			rq.clients[dim] = cq
+		}
+		next[route] = rq
 	}
 
 	// Limpiar limiters que ya no existen
@@ -59,14 +117,34 @@ func (iq *IQ) RefreshOnce(ctx context.Context) {
 
 	// Publicar la nueva tabla
 	iq.quotas.Store(next)
-	iq.logHelper.Infof("[%s] [IQ] quotas applied: %d routes", iq.serverType, len(next))
+	iq.loadedAt.Store(at.UnixNano())
+	quotaRoutes.WithLabelValues(iq.transport()).Set(float64(len(next)))
+	return len(next)
+}
+
+// stale: la tabla publicada es más antigua que max_stale.
+func (iq *IQ) stale(at time.Time) bool {
+	return iq.maxStale > 0 && time.Since(at) > iq.maxStale
+}
+
+// expireStale vacía la tabla si lleva más de max_stale sin refrescarse.
+func (iq *IQ) expireStale() {
+	at := iq.loadedAt.Load()
+	if at == 0 || iq.QuotasLen() == 0 || !iq.stale(time.Unix(0, at)) {
+		return
+	}
+	iq.setEmptyQuotas()
 }
 
 // setEmptyQuotas borra la tabla de cuotas y limpia todos los limiters.
 func (iq *IQ) setEmptyQuotas() {
-	iq.quotas.Store(make(map[string]quotaCfg))
+	iq.applyMu.Lock()
+	defer iq.applyMu.Unlock()
+
+	iq.quotas.Store(make(map[string]routeQuota))
+	quotaRoutes.WithLabelValues(iq.transport()).Set(0)
 	iq.mu.Lock()
 	iq.limiters = make(map[string]*rate.Limiter)
 	iq.mu.Unlock()
-	iq.logHelper.Warnf("[%s] [IQ] quotas cleared: using empty set (fail-open)", iq.serverType)
+	iq.logHelper.Warnf("[%s] [IQ] quotas cleared: last table older than %s (fail-open)", iq.serverType, iq.maxStale)
 }
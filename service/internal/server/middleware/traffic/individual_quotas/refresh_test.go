@@ -0,0 +1,162 @@
+package individual_quotas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const testTable = `{"items":[{"project":"p","route":"/v1/a","quota":5},{"project":"p","route":"/v1/b","quota":1}]}`
+
+// quotaService serves testTable, or 503 while failing is set.
+func quotaService(t *testing.T, failing *atomic.Bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path != "/qt" || r.URL.Query().Get("project") != "p" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testTable))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestRefreshOnceKeepsLastKnownGood(t *testing.T) {
+	var failing atomic.Bool
+	srv, _ := quotaService(t, &failing)
+	iq := New("p", HTTP, &conf.Traffic_Quotas{ServiceUrl: srv.URL}, log.DefaultLogger)
+	iq.rest.SetRetryCount(0)
+
+	if err := iq.RefreshOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if iq.QuotasLen() != 2 || iq.getLimiter("/v1/a") == nil {
+		t.Fatalf("routes = %d, want 2 with limiters", iq.QuotasLen())
+	}
+	refreshedAt := iq.refreshedAt.Load()
+
+	failing.Store(true)
+	if err := iq.RefreshOnce(context.Background()); err == nil {
+		t.Fatal("RefreshOnce() succeeded against a failing service")
+	}
+	if iq.QuotasLen() != 2 || iq.getLimiter("/v1/a") == nil {
+		t.Fatalf("routes = %d after a failed fetch, want the last known good 2", iq.QuotasLen())
+	}
+	if iq.refreshedAt.Load() != refreshedAt {
+		t.Fatal("a failed fetch moved refreshed_at")
+	}
+}
+
+func TestRefreshOnceExpiresStaleTable(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	srv, _ := quotaService(t, &failing)
+	iq := New("p", HTTP, &conf.Traffic_Quotas{ServiceUrl: srv.URL, MaxStale: durationpb.New(time.Hour)}, log.DefaultLogger)
+	iq.rest.SetRetryCount(0)
+
+	iq.apply([]quotaItem{{Project: "p", Route: "/v1/a", Quota: 5}}, time.Now().Add(-30*time.Minute))
+	_ = iq.RefreshOnce(context.Background())
+	if iq.QuotasLen() != 1 {
+		t.Fatalf("routes = %d, want the table kept within max_stale", iq.QuotasLen())
+	}
+
+	iq.apply([]quotaItem{{Project: "p", Route: "/v1/a", Quota: 5}}, time.Now().Add(-2*time.Hour))
+	_ = iq.RefreshOnce(context.Background())
+	if iq.QuotasLen() != 0 || iq.getLimiter("/v1/a") != nil {
+		t.Fatalf("routes = %d, want the table dropped after max_stale (fail-open)", iq.QuotasLen())
+	}
+}
+
+func TestNextRefresh(t *testing.T) {
+	iq := New("p", HTTP, &conf.Traffic_Quotas{
+		ServiceUrl: "http://127.0.0.1:1",
+		Refresh:    durationpb.New(time.Hour),
+		RetryMin:   durationpb.New(time.Second),
+		RetryMax:   durationpb.New(8 * time.Second),
+	}, log.DefaultLogger)
+	fail := errors.New("down")
+
+	steps := []struct {
+		err      error
+		failures int
+		max      time.Duration // wait is jittered in [max/2, max]
+	}{
+		{fail, 1, time.Second},
+		{fail, 2, 2 * time.Second},
+		{fail, 3, 4 * time.Second},
+		{fail, 4, 8 * time.Second},
+		{fail, 5, 8 * time.Second}, // capped at retry_max
+		{nil, 0, time.Hour},        // reset after a success
+		{fail, 1, time.Second},
+	}
+	failures := 0
+	for i, s := range steps {
+		var wait time.Duration
+		failures, wait = iq.nextRefresh(failures, s.err)
+		if failures != s.failures {
+			t.Fatalf("step %d: failures = %d, want %d", i+1, failures, s.failures)
+		}
+		min := s.max / 2
+		if s.err == nil {
+			min = s.max
+		}
+		if wait < min || wait > s.max {
+			t.Fatalf("step %d: wait = %s, want in [%s, %s]", i+1, wait, min, s.max)
+		}
+	}
+}
+
+func TestStartRetriesUntilLoaded(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	srv, hits := quotaService(t, &failing)
+	iq := New("p", HTTP, &conf.Traffic_Quotas{
+		ServiceUrl: srv.URL,
+		RetryMin:   durationpb.New(5 * time.Millisecond),
+		RetryMax:   durationpb.New(10 * time.Millisecond),
+	}, log.DefaultLogger)
+	iq.rest.SetRetryCount(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	iq.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hits.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fetches = %d, want retries after failures", hits.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	failing.Store(false)
+	for iq.QuotasLen() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("table not loaded once the service recovered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// After a success the next fetch waits refresh (24h), not the backoff.
+	n := hits.Load()
+	time.Sleep(50 * time.Millisecond)
+	if hits.Load() != n {
+		t.Fatalf("fetches = %d after a success, want %d (backoff reset)", hits.Load(), n)
+	}
+}
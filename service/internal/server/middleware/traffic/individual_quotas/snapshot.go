@@ -0,0 +1,92 @@
+package individual_quotas
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*
+   Snapshot en disco de la última tabla válida (traffic.quotas.snapshot):
+   se escribe tras cada descarga correcta y se carga al arrancar, así un
+   reinicio con el servicio de cuotas caído mantiene la protección.
+   HTTP y gRPC comparten el fichero (misma tabla del proyecto); la escritura
+   es atómica (temporal + rename).
+*/
+
+type snapshot struct {
+	Project   string      `json:"project"`
+	FetchedAt time.Time   `json:"fetched_at"`
+	Items     []quotaItem `json:"items"`
+}
+
+// loadSnapshot publica la tabla del snapshot si es del proyecto y no ha
+// caducado (max_stale).
+func (iq *IQ) loadSnapshot() {
+	if iq.snapshotPath == "" {
+		return
+	}
+	raw, err := os.ReadFile(iq.snapshotPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		iq.logHelper.Warnf("[%s] [IQ] snapshot read error: %v", iq.serverType, err)
+		return
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(raw, &s); err != nil {
+		iq.logHelper.Warnf("[%s] [IQ] snapshot %s ignored: %v", iq.serverType, iq.snapshotPath, err)
+		return
+	}
+	if s.Project != iq.project {
+		iq.logHelper.Warnf("[%s] [IQ] snapshot %s ignored: project %q", iq.serverType, iq.snapshotPath, s.Project)
+		return
+	}
+	if iq.stale(s.FetchedAt) {
+		iq.logHelper.Warnf("[%s] [IQ] snapshot %s ignored: fetched at %s", iq.serverType, iq.snapshotPath, s.FetchedAt.Format(time.RFC3339))
+		return
+	}
+
+	n := iq.apply(s.Items, s.FetchedAt)
+	iq.logHelper.Infof("[%s] [IQ] quotas restored from snapshot: %d routes (fetched at %s)", iq.serverType, n, s.FetchedAt.Format(time.RFC3339))
+}
+
+// saveSnapshot guarda los items descargados en `at`.
+func (iq *IQ) saveSnapshot(items []quotaItem, at time.Time) {
+	if iq.snapshotPath == "" {
+		return
+	}
+	if err := writeSnapshot(iq.snapshotPath, snapshot{Project: iq.project, FetchedAt: at.UTC(), Items: items}); err != nil {
+		iq.logHelper.Warnf("[%s] [IQ] snapshot write error: %v", iq.serverType, err)
+	}
+}
+
+func writeSnapshot(path string, s snapshot) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op tras el rename
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
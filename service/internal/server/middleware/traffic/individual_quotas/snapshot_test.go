@@ -0,0 +1,93 @@
+package individual_quotas
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	var failing atomic.Bool
+	srv, _ := quotaService(t, &failing)
+	path := filepath.Join(t.TempDir(), "state", "quotas.json")
+	qc := &conf.Traffic_Quotas{ServiceUrl: srv.URL, Snapshot: path}
+
+	iq := New("p", HTTP, qc, log.DefaultLogger)
+	if err := iq.RefreshOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+
+	// A restart with the quota service down restores the table.
+	restarted := New("p", GRPC, qc, log.DefaultLogger)
+	restarted.loadSnapshot()
+	if restarted.QuotasLen() != 2 || restarted.getLimiter("/v1/a") == nil {
+		t.Fatalf("routes = %d, want 2 restored from the snapshot", restarted.QuotasLen())
+	}
+	if restarted.loadedAt.Load() != iq.loadedAt.Load() {
+		t.Fatal("restored table not dated by its fetch time")
+	}
+	if restarted.refreshedAt.Load() != 0 {
+		t.Fatal("a snapshot is not a refresh")
+	}
+}
+
+func TestLoadSnapshotIgnored(t *testing.T) {
+	items := []quotaItem{{Project: "p", Route: "/v1/a", Quota: 5}}
+	tests := []struct {
+		name  string
+		write func(path string) error
+	}{
+		{"missing", func(string) error { return nil }},
+		{"corrupt", func(path string) error { return os.WriteFile(path, []byte("{"), 0o644) }},
+		{"other project", func(path string) error {
+			return writeSnapshot(path, snapshot{Project: "q", FetchedAt: time.Now(), Items: items})
+		}},
+		{"older than max_stale", func(path string) error {
+			return writeSnapshot(path, snapshot{Project: "p", FetchedAt: time.Now().Add(-2 * time.Hour), Items: items})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "quotas.json")
+			if err := tt.write(path); err != nil {
+				t.Fatal(err)
+			}
+			iq := New("p", HTTP, &conf.Traffic_Quotas{
+				ServiceUrl: "http://127.0.0.1:1",
+				Snapshot:   path,
+				MaxStale:   durationpb.New(time.Hour),
+			}, log.DefaultLogger)
+			iq.loadSnapshot()
+			if iq.QuotasLen() != 0 || iq.loadedAt.Load() != 0 {
+				t.Fatalf("routes = %d, want the snapshot ignored", iq.QuotasLen())
+			}
+		})
+	}
+
+	t.Run("within max_stale", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "quotas.json")
+		if err := writeSnapshot(path, snapshot{Project: "p", FetchedAt: time.Now().Add(-30 * time.Minute), Items: items}); err != nil {
+			t.Fatal(err)
+		}
+		iq := New("p", HTTP, &conf.Traffic_Quotas{
+			ServiceUrl: "http://127.0.0.1:1",
+			Snapshot:   path,
+			MaxStale:   durationpb.New(time.Hour),
+		}, log.DefaultLogger)
+		iq.loadSnapshot()
+		if iq.QuotasLen() != 1 {
+			t.Fatalf("routes = %d, want 1", iq.QuotasLen())
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package individual_quotas
+
+import (
+	"sort"
+	"time"
+)
+
+// State es la foto de un IQ para GET /qt.
+type State struct {
+	Transport   string       `json:"transport"`
+	Enabled     bool         `json:"enabled"`
+	Shadow      bool         `json:"shadow"`
+	Shared      bool         `json:"shared"`                 // buckets en Redis: los tokens locales son el respaldo
+	RefreshedAt *time.Time   `json:"refreshed_at,omitempty"` // último refresco correcto (descarga o push)
+	LoadedAt    *time.Time   `json:"loaded_at,omitempty"`    // tabla publicada (incluye snapshot)
+	Routes      []RouteState `json:"routes"`
+}
+
+// RouteState es la cuota de una ruta y el estado de su limiter.
+type RouteState struct {
+	Route   string        `json:"route"`
+	Quota   *LimitState   `json:"quota,omitempty"` // toda la ruta
+	Clients []ClientState `json:"clients,omitempty"`
+}
+
+// LimitState: cuota configurada y su token-bucket.
+type LimitState struct {
+	Quota    int      `json:"quota"`
+	Interval int      `json:"interval"`
+	Rate     float64  `json:"rate"`             // tokens por segundo
+	Burst    int      `json:"burst"`            // capacidad
+	Tokens   *float64 `json:"tokens,omitempty"` // disponibles ahora (limiter local)
+}
+
+// ClientState: cuota por cliente de una dimensión ("" = por defecto).
+type ClientState struct {
+	KeyBy   string `json:"key_by"`
+	Subject string `json:"subject,omitempty"`
+	LimitState
+}
+
+// State devuelve la tabla activa con el estado de los limiters de ruta.
+func (iq *IQ) State() State {
+	s := State{
+		Transport: iq.transport(),
+		Enabled:   iq.enabled(),
+		Shadow:    iq.shadow,
+		Shared:    iq.shared != nil,
+		Routes:    []RouteState{},
+	}
+	s.RefreshedAt = unixTime(iq.refreshedAt.Load())
+	s.LoadedAt = unixTime(iq.loadedAt.Load())
+
+	qm := iq.quotas.Load()
+	routes := make([]string, 0, len(qm))
+	for r := range qm {
+		routes = append(routes, r)
+	}
+	sort.Strings(routes)
+
+	now := time.Now()
+	for _, r := range routes {
+		rq := qm[r]
+		rs := RouteState{Route: r}
+		if rq.route != nil {
+			ls := iq.limitState(*rq.route)
+			if lim := iq.getLimiter(r); lim != nil {
+				tokens := lim.TokensAt(now)
+				ls.Tokens = &tokens
+			}
+			rs.Quota = &ls
+		}
+		for _, dim := range rq.dimensions() {
+			cq := rq.clients[dim]
+			if cq.def != nil {
+				rs.Clients = append(rs.Clients, ClientState{KeyBy: string(dim), LimitState: iq.limitState(*cq.def)})
+			}
+			subjects := make([]string, 0, len(cq.subjects))
+			for sub := range cq.subjects {
+				subjects = append(subjects, sub)
+			}
+			sort.Strings(subjects)
+			for _, sub := range subjects {
+				rs.Clients = append(rs.Clients, ClientState{KeyBy: string(dim), Subject: sub, LimitState: iq.limitState(cq.subjects[sub])})
+			}
+		}
+		s.Routes = append(s.Routes, rs)
+	}
+	return s
+}
+
+func (iq *IQ) limitState(q quotaCfg) LimitState {
+	rps, burst := iq.bucket(q)
+	return LimitState{Quota: q.Quota, Interval: q.Interval, Rate: rps, Burst: burst}
+}
+
+func unixTime(nanos int64) *time.Time {
+	if nanos == 0 {
+		return nil
+	}
+	t := time.Unix(0, nanos).UTC()
+	return &t
+}
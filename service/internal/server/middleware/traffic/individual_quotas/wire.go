@@ -0,0 +1,6 @@
+package individual_quotas
+
+import "github.com/google/wire"
+
+// ProviderSet is individual quotas providers (push updates + /qt hub).
+var ProviderSet = wire.NewSet(NewHub)
@@ -0,0 +1,218 @@
+// internal/server/middleware/traffic/inflight.go
+package traffic
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/*
+   In-flight cap with an optional bounded wait queue:
+   - below the cap a call enters at once;
+   - at the cap it waits for a slot (up to QueueMax waiters) until
+     QueueTimeout or the end of its context (deadline, cancel);
+   - a leaving call hands its slot straight to a waiter: FIFO (oldest first)
+     or LIFO (newest first; when the queue is full the oldest waiter is
+     dropped for the newcomer, it has the least time left);
+   - critical calls (see criticality.go) may also take the headroom slots
+     above the cap, wait in a queue of their own served before the others
+     and, when the queue is full, take the place of the non-critical waiter
+     that would be served last.
+*/
+
+const defaultQueueTimeout = time.Second
+
+// queueCfg is the wait queue of an in-flight limiter (Max 0 = no queue).
+type queueCfg struct {
+	Max     int
+	Timeout time.Duration
+	LIFO    bool
+}
+
+type inflightLimiter struct {
+	mu       sync.Mutex
+	max      int
+	headroom int // slots above max for critical calls
+	cur      int
+	queue    queueCfg
+	wait     *list.List // of *waiter, front = oldest
+	urgent   *list.List // critical waiters, served first
+
+	length   prometheus.Gauge
+	admitted prometheus.Observer
+	rejected prometheus.Observer
+}
+
+type waiter struct {
+	ready   chan struct{} // closed when granted or dropped
+	granted bool
+	queue   *list.List // wait or urgent
+}
+
+func newInflightLimiter(scope string, max, headroom int, q queueCfg) *inflightLimiter {
+	if max < 1 {
+		max = 1
+	}
+	if headroom < 0 {
+		headroom = 0
+	}
+	if q.Max > 0 && q.Timeout <= 0 {
+		q.Timeout = defaultQueueTimeout
+	}
+	return &inflightLimiter{
+		max:      max,
+		headroom: headroom,
+		queue:    q,
+		wait:     list.New(),
+		urgent:   list.New(),
+		length:   queueLength.WithLabelValues(scope),
+		admitted: queueWait.WithLabelValues(scope, "admitted"),
+		rejected: queueWait.WithLabelValues(scope, "rejected"),
+	}
+}
+
+// acquire takes a slot for a call of class crit; with queue it may wait for
+// one (see package notes). It reports false when no slot was taken.
+func (l *inflightLimiter) acquire(ctx context.Context, crit Criticality, queue bool) bool {
+	critical := crit == CriticalityCritical
+	l.mu.Lock()
+	if l.cur < l.limit(critical) {
+		l.cur++
+		l.mu.Unlock()
+		return true
+	}
+	if !queue || l.queue.Max <= 0 {
+		l.mu.Unlock()
+		return false
+	}
+	if l.wait.Len()+l.urgent.Len() >= l.queue.Max {
+		victim := l.victim(critical)
+		if victim == nil {
+			l.mu.Unlock()
+			return false
+		}
+		l.drop(victim)
+	}
+	w := &waiter{ready: make(chan struct{}), queue: l.wait}
+	if critical {
+		w.queue = l.urgent
+	}
+	el := w.queue.PushBack(w)
+	l.length.Inc()
+	l.mu.Unlock()
+
+	start := time.Now()
+	t := time.NewTimer(l.queue.Timeout)
+	defer t.Stop()
+	select {
+	case <-w.ready:
+	case <-t.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !w.granted {
+		select {
+		case <-w.ready: // dropped for a newer or critical waiter
+		default:
+			w.queue.Remove(el)
+			l.length.Dec()
+		}
+		l.rejected.Observe(time.Since(start).Seconds())
+		return false
+	}
+	l.admitted.Observe(time.Since(start).Seconds())
+	return true
+}
+
+// leave frees a slot, handing it to the next waiter if any (and the cap was
+// not lowered below the calls in flight).
+func (l *inflightLimiter) leave() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cur--
+	l.grant()
+}
+
+// setMax changes the cap; a raised cap admits queued calls at once.
+func (l *inflightLimiter) setMax(max int) {
+	if max < 1 {
+		max = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+	l.grant()
+}
+
+// inUse returns the slots taken.
+func (l *inflightLimiter) inUse() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cur
+}
+
+// limit returns the slots a call may enter under: the cap, plus the headroom
+// for critical calls.
+func (l *inflightLimiter) limit(critical bool) int {
+	if critical {
+		return l.max + l.headroom
+	}
+	return l.max
+}
+
+// grant hands free slots to waiters, critical ones first (caller holds the
+// lock).
+func (l *inflightLimiter) grant() {
+	for {
+		q := l.urgent
+		if q.Len() == 0 || l.cur >= l.limit(true) {
+			q = l.wait
+			if q.Len() == 0 || l.cur >= l.limit(false) {
+				return
+			}
+		}
+		next := q.Front()
+		if l.queue.LIFO {
+			next = q.Back()
+		}
+		w := q.Remove(next).(*waiter)
+		l.length.Dec()
+		l.cur++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// victim picks the waiter dropped for a newcomer when the queue is full (nil =
+// reject the newcomer): for a critical call the non-critical waiter served
+// last, else with LIFO the oldest waiter of its own class (caller holds the
+// lock).
+func (l *inflightLimiter) victim(critical bool) *list.Element {
+	if critical && l.wait.Len() > 0 {
+		if l.queue.LIFO {
+			return l.wait.Front()
+		}
+		return l.wait.Back()
+	}
+	if !l.queue.LIFO {
+		return nil
+	}
+	if critical {
+		return l.urgent.Front()
+	}
+	return l.wait.Front()
+}
+
+// drop rejects a queued waiter (caller holds the lock).
+func (l *inflightLimiter) drop(el *list.Element) {
+	w := el.Value.(*waiter)
+	w.queue.Remove(el)
+	l.length.Dec()
+	close(w.ready)
+}
@@ -0,0 +1,269 @@
+package traffic
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// queued waits until n calls (of any class) wait in l.
+func queued(t *testing.T, l *inflightLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		got := l.wait.Len() + l.urgent.Len()
+		l.mu.Unlock()
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// enqueue starts waiters one after another; results[i] receives waiter i's
+// outcome.
+func enqueue(t *testing.T, l *inflightLimiter, ctx context.Context, n int) []chan bool {
+	t.Helper()
+	return enqueueAs(t, l, ctx, CriticalityDefault, n)
+}
+
+// enqueueAs is enqueue for calls of class crit, on top of the waiters already
+// queued.
+func enqueueAs(t *testing.T, l *inflightLimiter, ctx context.Context, crit Criticality, n int) []chan bool {
+	t.Helper()
+	l.mu.Lock()
+	waiting := l.wait.Len() + l.urgent.Len()
+	l.mu.Unlock()
+	results := make([]chan bool, n)
+	for i := range results {
+		results[i] = make(chan bool, 1)
+		go func(ch chan bool) { ch <- l.acquire(ctx, crit, true) }(results[i])
+		queued(t, l, min(waiting+i+1, l.queue.Max))
+	}
+	return results
+}
+
+// blocked checks that a waiter has no outcome yet.
+func blocked(t *testing.T, ch chan bool) {
+	t.Helper()
+	select {
+	case ok := <-ch:
+		t.Fatalf("waiter done (admitted %t), want it still queued", ok)
+	default:
+	}
+}
+
+func result(t *testing.T, ch chan bool) bool {
+	t.Helper()
+	select {
+	case ok := <-ch:
+		return ok
+	case <-time.After(time.Second):
+		t.Fatal("waiter still blocked")
+		return false
+	}
+}
+
+func TestInflightWithoutQueue(t *testing.T) {
+	l := newInflightLimiter("test noqueue", 1, 0, queueCfg{})
+	if !l.acquire(context.Background(), CriticalityDefault, true) {
+		t.Fatal("first call rejected")
+	}
+	if l.acquire(context.Background(), CriticalityDefault, true) {
+		t.Fatal("call over the cap admitted without a queue")
+	}
+	l.leave()
+	if l.inUse() != 0 {
+		t.Errorf("in use = %d, want 0", l.inUse())
+	}
+}
+
+func TestInflightQueueOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		lifo  bool
+		order []int // waiters in the order they get a slot
+	}{
+		{"fifo", false, []int{0, 1, 2}},
+		{"lifo", true, []int{2, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newInflightLimiter("test order "+tt.name, 1, 0, queueCfg{Max: 3, Timeout: time.Minute, LIFO: tt.lifo})
+			l.acquire(context.Background(), CriticalityDefault, true)
+			results := enqueue(t, l, context.Background(), 3)
+
+			for _, i := range tt.order {
+				l.leave() // hands the slot straight to the next waiter
+				if !result(t, results[i]) {
+					t.Fatalf("waiter %d rejected", i)
+				}
+				if l.inUse() != 1 {
+					t.Fatalf("in use = %d, want the slot handed over", l.inUse())
+				}
+			}
+		})
+	}
+}
+
+func TestInflightQueueFull(t *testing.T) {
+	t.Run("fifo rejects the newcomer", func(t *testing.T) {
+		l := newInflightLimiter("test full fifo", 1, 0, queueCfg{Max: 2, Timeout: time.Minute})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		results := enqueue(t, l, context.Background(), 2)
+		if l.acquire(context.Background(), CriticalityDefault, true) {
+			t.Fatal("newcomer admitted to a full FIFO queue")
+		}
+		l.leave()
+		if !result(t, results[0]) {
+			t.Error("oldest waiter rejected")
+		}
+	})
+
+	t.Run("lifo drops the oldest", func(t *testing.T) {
+		l := newInflightLimiter("test full lifo", 1, 0, queueCfg{Max: 2, Timeout: time.Minute, LIFO: true})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		results := enqueue(t, l, context.Background(), 3)
+		if result(t, results[0]) {
+			t.Fatal("oldest waiter admitted")
+		}
+		queued(t, l, 2)
+		l.leave()
+		if !result(t, results[2]) {
+			t.Error("newest waiter rejected")
+		}
+	})
+}
+
+func TestInflightQueueGivesUp(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		l := newInflightLimiter("test timeout", 1, 0, queueCfg{Max: 1, Timeout: 20 * time.Millisecond})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		if l.acquire(context.Background(), CriticalityDefault, true) {
+			t.Fatal("waiter admitted after the queue timeout")
+		}
+		queued(t, l, 0)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		l := newInflightLimiter("test ctx", 1, 0, queueCfg{Max: 1, Timeout: time.Minute})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		ctx, cancel := context.WithCancel(context.Background())
+		results := enqueue(t, l, ctx, 1)
+		cancel()
+		if result(t, results[0]) {
+			t.Fatal("canceled waiter admitted")
+		}
+		queued(t, l, 0)
+		l.leave()
+		if l.inUse() != 0 {
+			t.Errorf("in use = %d, want the slot free", l.inUse())
+		}
+	})
+
+	t.Run("not queueable", func(t *testing.T) {
+		l := newInflightLimiter("test sheddable", 1, 0, queueCfg{Max: 1, Timeout: time.Minute})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		if l.acquire(context.Background(), CriticalitySheddable, false) {
+			t.Fatal("call admitted over the cap")
+		}
+		queued(t, l, 0)
+	})
+}
+
+func TestInflightSetMaxAdmitsWaiters(t *testing.T) {
+	l := newInflightLimiter("test setmax", 1, 0, queueCfg{Max: 2, Timeout: time.Minute})
+	l.acquire(context.Background(), CriticalityDefault, true)
+	results := enqueue(t, l, context.Background(), 2)
+
+	l.setMax(3)
+	for i, ch := range results {
+		if !result(t, ch) {
+			t.Errorf("waiter %d rejected after the cap was raised", i)
+		}
+	}
+	if l.inUse() != 3 {
+		t.Errorf("in use = %d, want 3", l.inUse())
+	}
+}
+
+// Saturated limiter: the cap and the queue are full of default calls.
+func TestInflightCriticalUnderSaturation(t *testing.T) {
+	saturated := func(t *testing.T, name string, headroom int, lifo bool) (*inflightLimiter, []chan bool) {
+		l := newInflightLimiter(name, 2, headroom, queueCfg{Max: 2, Timeout: time.Minute, LIFO: lifo})
+		for range 2 {
+			if !l.acquire(context.Background(), CriticalityDefault, true) {
+				t.Fatal("call under the cap rejected")
+			}
+		}
+		return l, enqueue(t, l, context.Background(), 2)
+	}
+
+	t.Run("headroom admits critical calls", func(t *testing.T) {
+		l, results := saturated(t, "test critical headroom", 1, false)
+		if l.acquire(context.Background(), CriticalityDefault, true) {
+			t.Fatal("default call admitted to a saturated limiter")
+		}
+		if !l.acquire(context.Background(), CriticalityCritical, true) {
+			t.Fatal("critical call rejected with headroom left")
+		}
+		if l.inUse() != 3 {
+			t.Fatalf("in use = %d, want 3 (cap + headroom)", l.inUse())
+		}
+		// The headroom is not for default waiters: a leaving call gives them
+		// nothing while the calls in flight are over the cap.
+		l.leave()
+		blocked(t, results[0])
+		l.leave()
+		if !result(t, results[0]) {
+			t.Fatal("oldest default waiter rejected once under the cap")
+		}
+	})
+
+	for _, lifo := range []bool{false, true} {
+		order := map[bool]string{false: "fifo", true: "lifo"}[lifo]
+		t.Run("critical waiter served first "+order, func(t *testing.T) {
+			l, defaults := saturated(t, "test critical first "+order, 0, lifo)
+			// The queue is full: the critical call takes the place of the
+			// default waiter that would be served last.
+			critical := enqueueAs(t, l, context.Background(), CriticalityCritical, 1)
+			dropped, kept := 1, 0 // fifo: newest default dropped
+			if lifo {
+				dropped, kept = 0, 1 // lifo: oldest default dropped
+			}
+			if result(t, defaults[dropped]) {
+				t.Fatalf("default waiter %d admitted, want it dropped for the critical call", dropped)
+			}
+
+			l.leave()
+			if !result(t, critical[0]) {
+				t.Fatal("critical waiter rejected")
+			}
+			blocked(t, defaults[kept])
+			l.leave()
+			if !result(t, defaults[kept]) {
+				t.Fatalf("default waiter %d rejected", kept)
+			}
+		})
+	}
+
+	t.Run("queue full of critical calls", func(t *testing.T) {
+		l := newInflightLimiter("test critical full", 1, 0, queueCfg{Max: 1, Timeout: time.Minute})
+		l.acquire(context.Background(), CriticalityDefault, true)
+		critical := enqueueAs(t, l, context.Background(), CriticalityCritical, 1)
+		if l.acquire(context.Background(), CriticalityCritical, true) {
+			t.Fatal("critical call admitted to a queue full of critical calls (fifo)")
+		}
+		if l.acquire(context.Background(), CriticalityDefault, true) {
+			t.Fatal("default call admitted to a queue full of critical calls")
+		}
+		l.leave()
+		if !result(t, critical[0]) {
+			t.Fatal("critical waiter rejected")
+		}
+	})
+}
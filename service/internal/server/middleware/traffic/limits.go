@@ -0,0 +1,149 @@
+// internal/server/middleware/traffic/limits.go
+package traffic
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	kratosErr "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+/*
+   Hints for clients of limited calls:
+   - HTTP: RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset (+ Retry-After on 429).
+   - gRPC: the same keys as reply metadata; on rejection as trailers plus a
+     google.rpc.RetryInfo error detail (code RESOURCE_EXHAUSTED).
+*/
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Decision is the outcome of a token bucket for one call.
+type Decision struct {
+	Allowed    bool
+	Limit      int           // bucket capacity (0 = no bucket, no RateLimit-* headers)
+	Remaining  int           // whole tokens left
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until a token is available (rejected calls)
+}
+
+// Allow returns an allowed decision without a bucket.
+func Allow() Decision { return Decision{Allowed: true} }
+
+// Take reserves one token of lim and describes the bucket afterwards.
+// A reservation that would have to wait is cancelled and the call is rejected.
+func Take(lim *rate.Limiter) Decision {
+	now := time.Now()
+	d := Decision{Limit: lim.Burst()}
+
+	r := lim.ReserveN(now, 1)
+	if !r.OK() {
+		// burst 0 or limit 0: nothing will ever pass
+		d.RetryAfter = time.Second
+		return d
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		d.RetryAfter = delay
+	} else {
+		d.Allowed = true
+	}
+
+	tokens := lim.TokensAt(now)
+	d.Remaining = int(math.Max(0, math.Floor(tokens)))
+	if l := float64(lim.Limit()); l > 0 && !math.IsInf(l, 1) {
+		d.Reset = time.Duration((float64(d.Limit) - math.Max(0, tokens)) / l * float64(time.Second))
+	}
+	return d
+}
+
+// SetHeaders adds the RateLimit-* reply headers of d. When several limiters
+// apply to a call, the most restrictive one (lowest remaining) is kept.
+func SetHeaders(ctx context.Context, d Decision) {
+	if d.Limit <= 0 {
+		return
+	}
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return
+	}
+	h := tr.ReplyHeader()
+	if prev, err := strconv.Atoi(h.Get(HeaderRemaining)); err == nil && prev <= d.Remaining {
+		return
+	}
+	for k, v := range d.headers() {
+		h.Set(k, v)
+	}
+}
+
+// Reject returns the 429 error for d and sets its hints (headers, gRPC trailers).
+func Reject(ctx context.Context, reason, msg string, d Decision) error {
+	if d.RetryAfter <= 0 {
+		d.RetryAfter = time.Second
+	}
+	SetHeaders(ctx, d)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		switch tr.Kind() {
+		case transport.KindHTTP:
+			tr.ReplyHeader().Set(HeaderRetryAfter, seconds(d.RetryAfter))
+		case transport.KindGRPC:
+			md := metadata.New(d.headers())
+			md.Set(HeaderRetryAfter, seconds(d.RetryAfter))
+			_ = grpc.SetTrailer(ctx, md)
+		}
+	}
+	return &limitedError{
+		err:        kratosErr.New(http.StatusTooManyRequests, reason, msg),
+		retryAfter: d.RetryAfter,
+	}
+}
+
+func (d Decision) headers() map[string]string {
+	if d.Limit <= 0 {
+		return map[string]string{}
+	}
+	return map[string]string{
+		HeaderLimit:     strconv.Itoa(d.Limit),
+		HeaderRemaining: strconv.Itoa(d.Remaining),
+		HeaderReset:     seconds(d.Reset),
+	}
+}
+
+// seconds rounds up to whole seconds (headers carry delta-seconds).
+func seconds(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// limitedError is a Kratos 429 whose gRPC status also carries RetryInfo.
+type limitedError struct {
+	err        *kratosErr.Error
+	retryAfter time.Duration
+}
+
+func (e *limitedError) Error() string { return e.err.Error() }
+func (e *limitedError) Unwrap() error { return e.err }
+
+func (e *limitedError) GRPCStatus() *status.Status {
+	st := e.err.GRPCStatus()
+	if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(e.retryAfter)}); err == nil {
+		return withRetry
+	}
+	return st
+}
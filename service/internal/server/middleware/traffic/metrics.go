@@ -0,0 +1,74 @@
+// internal/server/middleware/traffic/metrics.go
+package traffic
+
+import (
+	"errors"
+
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of a limiter (the "result" label of traffic_decisions_total).
+const (
+	ResultPass   = "pass"
+	ResultReject = "reject"
+	ResultShadow = "shadow" // would have been rejected, let through (dry-run)
+)
+
+// Limiters (the "reason" label of traffic_decisions_total).
+const (
+	ReasonInflight = "inflight" // server or route in-flight cap
+	ReasonRate     = "rate"     // token bucket
+	ReasonBBR      = "bbr"      // adaptive CPU shedding
+	ReasonIQ       = "iq"       // individual quotas
+)
+
+var (
+	decisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "traffic_decisions_total",
+		Help: "Traffic-control decisions by transport, operation, limiter (reason) and result (pass, reject, shadow).",
+	}, []string{"transport", "operation", "reason", "result"})
+
+	inflightNow = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "traffic_inflight",
+		Help: "Requests currently holding a server in-flight slot.",
+	}, []string{"transport"})
+
+	concurrencyLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "traffic_concurrency_limit",
+		Help: "Current server in-flight cap (fixed InflightMax or adaptive).",
+	}, []string{"transport"})
+
+	queueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "traffic_queue_length",
+		Help: "Calls waiting for an in-flight slot per limiter scope.",
+	}, []string{"scope"})
+
+	queueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "traffic_queue_wait_seconds",
+		Help:    "Time queued calls waited for an in-flight slot, by scope and result (admitted, rejected).",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"scope", "result"})
+)
+
+// Record counts one decision of a limiter (Result*); used by Builder and
+// individual quotas.
+func Record(transport, operation, reason, result string) {
+	decisions.WithLabelValues(transport, operation, reason, result).Inc()
+}
+
+// registerBBR exposes the CPU estimate of l as traffic_bbr_cpu{transport}.
+// The first limiter of a transport wins (they share the process CPU sampler).
+func registerBBR(transport string, l *bbr.BBR) {
+	g := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "traffic_bbr_cpu",
+		Help:        "CPU estimate used by BBR shedding, in thousandths (800 = 80%).",
+		ConstLabels: prometheus.Labels{"transport": transport},
+	}, func() float64 { return float64(l.Stat().CPU) })
+
+	var already prometheus.AlreadyRegisteredError
+	if err := prometheus.Register(g); err != nil && !errors.As(err, &already) {
+		panic(err)
+	}
+}
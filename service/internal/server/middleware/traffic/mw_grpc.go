@@ -3,75 +3,70 @@ package traffic
 
 import (
 	"context"
+	"strings"
+
+	"service/internal/server/utils/ip"
 
 	"github.com/go-kratos/aegis/ratelimit"
-	"github.com/go-kratos/aegis/ratelimit/bbr"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/peer"
 )
 
 /*
    Middleware gRPC:
-   Misma lógica que HTTP, pero claves derivadas de metadata/peer.
+   Misma lógica que HTTP; IP del cliente resuelta por server_utils_ip.
 */
 
 func (b *Builder) GRPC() middleware.Middleware {
-	var tail ratelimit.Limiter
-	if b.cfg.EnableCPU {
-		tail = bbr.NewLimiter(
-			bbr.WithWindow(b.cfg.CPUWindow),
-			bbr.WithBucket(b.cfg.CPUBuckets),
-			bbr.WithCPUThreshold(b.cfg.CPUThreshold),
-			bbr.WithCPUQuota(b.cfg.CPUQuota),
-		)
-	}
+	tail := b.newBBR()
 
-	b.cfg.LogHelper.Infof("[gRPC] [TRAFFIC RATE LIMIT] middleware initialized")
+	b.cfg.LogHelper.Infof("[gRPC] [TRAFFIC RATE LIMIT] middleware initialized (%d route overrides)", len(b.routes))
 
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			op := ""
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				op = tr.Operation()
+			}
+
+			r := b.route(op, op)
+			crit := b.criticality(ctx, r, grpcHeader(ctx))
+
 			// 1) InFlight
-			leave, blocked := b.tryInflight()
-			if blocked {
-				return nil, tooMany()
+			leave, blocked := b.tryInflight(ctx, crit, !b.cfg.Shadowed(ReasonInflight) && crit != CriticalitySheddable)
+			if b.inflight != nil && b.enforce(ctx, op, "", ReasonInflight, "", !blocked, b.cfg.Shadowed(ReasonInflight)) {
+				return nil, tooMany(ctx)
 			}
 			defer leave()
 
-			// 2) Clave (global/ip/user)
-			md, _ := metadata.FromIncomingContext(ctx)
-			var p *peer.Peer
-			if pp, ok := peer.FromContext(ctx); ok {
-				p = pp
+			// 2) Route overrides (operation)
+			cl, leaveRoute, blocked := b.limits(ctx, r, crit)
+			if blocked && b.enforce(ctx, op, cl.route, ReasonInflight, "", false, cl.shadowInflight) {
+				return nil, tooMany(ctx)
 			}
-			key := "global"
-			switch b.cfg.KeyBy {
-			case KeyIP:
-				key = "ip:" + ipFromGRPC(md, p)
-			case KeyUser:
-				uid := ""
-				if vals := md.Get("x-user-id"); len(vals) > 0 {
-					uid = vals[0]
+			defer leaveRoute()
+
+			// 3) Clave (global/ip/user) + Rate
+			if cl.rl != nil {
+				var key string
+				ctx, key = grpcKey(ctx, cl.keyBy)
+				d := cl.rl.Take(ctx, key)
+				if b.enforce(ctx, op, cl.route, ReasonRate, key, d.Allowed, cl.shadowRate) {
+					return nil, Reject(ctx, "RATE_LIMITED", "too many requests", d)
 				}
-				if uid != "" {
-					key = "u:" + uid
-				} else {
-					key = "ip:" + ipFromGRPC(md, p)
+				if d.Allowed {
+					SetHeaders(ctx, d)
 				}
-			default:
-				key = "global"
-			}
-
-			// 3) Rate
-			if b.rl != nil && !b.rl.Allow(key) {
-				return nil, tooMany()
 			}
 
 			// 4) BBR
 			if tail != nil {
-				if done, err := tail.Allow(); err != nil {
-					return nil, tooMany()
-				} else {
+				done, passed := b.shed(tail, crit)
+				if b.enforce(ctx, op, cl.route, ReasonBBR, "", passed, b.cfg.Shadowed(ReasonBBR)) {
+					return nil, tooMany(ctx)
+				}
+				if done != nil {
 					defer done(ratelimit.DoneInfo{})
 				}
 			}
@@ -80,3 +75,26 @@ func (b *Builder) GRPC() middleware.Middleware {
 		}
 	}
 }
+
+// grpcHeader reads incoming metadata (first value).
+func grpcHeader(ctx context.Context) func(string) string {
+	return func(key string) string {
+		if v := metadata.ValueFromIncomingContext(ctx, strings.ToLower(key)); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+}
+
+// grpcKey groups calls by keyBy (global/ip/user). For KeyUser the caller comes
+// from verified credentials (see UseIdentity), anonymous ones by IP.
+func grpcKey(ctx context.Context, keyBy KeyBy) (context.Context, string) {
+	switch keyBy {
+	case KeyIP:
+		return ctx, "ip:" + server_utils_ip.GetIP(ctx)
+	case KeyUser:
+		return userKey(ctx, func() string { return server_utils_ip.GetIP(ctx) })
+	default:
+		return ctx, "global"
+	}
+}
@@ -4,34 +4,32 @@ package traffic
 import (
 	"context"
 	"net/http"
+	"time"
+
+	"service/internal/server/utils/ip"
 
 	"github.com/go-kratos/aegis/ratelimit"
-	"github.com/go-kratos/aegis/ratelimit/bbr"
-	kratosErr "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 	khttp "github.com/go-kratos/kratos/v2/transport/http"
 )
 
 /*
    Middleware HTTP:
-   - InFlight -> 429 si no hay slots.
+   - InFlight (fijo o adaptativo por latencia) -> espera en cola (si hay) o 429 si no hay slots.
    - TokenBucket (RPS/Burst) por clave -> 429 si excede.
    - Quota por ventana -> 429 + Retry-After.
-   - Cola adaptativa BBR (CPU) -> 429 cuando sistema está saturado.
+   - Respuestas limitadas llevan RateLimit-* (ver limits.go).
+   - Cola adaptativa BBR (CPU) -> 429 cuando sistema está saturado, según
+     criticidad (ver criticality.go): sheddable primero, critical al final.
+   - Limiters en shadow (Config.Shadow, Route.Shadow): el rechazo solo se
+     registra (log + métrica result="shadow") y la petición sigue.
 */
 
 func (b *Builder) HTTP() middleware.Middleware {
-	var tail ratelimit.Limiter
-	if b.cfg.EnableCPU {
-		tail = bbr.NewLimiter(
-			bbr.WithWindow(b.cfg.CPUWindow),
-			bbr.WithBucket(b.cfg.CPUBuckets),
-			bbr.WithCPUThreshold(b.cfg.CPUThreshold),
-			bbr.WithCPUQuota(b.cfg.CPUQuota),
-		)
-	}
+	tail := b.newBBR()
 
-	b.cfg.LogHelper.Infof("[HTTP] [TRAFFIC RATE LIMIT] middleware initialized")
+	b.cfg.LogHelper.Infof("[HTTP] [TRAFFIC RATE LIMIT] middleware initialized (%d route overrides)", len(b.routes))
 
 	return func(next middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
@@ -39,39 +37,48 @@ func (b *Builder) HTTP() middleware.Middleware {
 			if !ok {
 				return next(ctx, req)
 			}
+			op := ""
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				op = tr.Operation()
+			}
+
+			r := b.route(op, hreq.URL.Path)
+			crit := b.criticality(ctx, r, hreq.Header.Get)
 
 			// 1) InFlight
-			leave, blocked := b.tryInflight()
-			if blocked {
-				return nil, tooMany()
+			leave, blocked := b.tryInflight(ctx, crit, !b.cfg.Shadowed(ReasonInflight) && crit != CriticalitySheddable)
+			if b.inflight != nil && b.enforce(ctx, op, "", ReasonInflight, "", !blocked, b.cfg.Shadowed(ReasonInflight)) {
+				return nil, tooMany(ctx)
 			}
 			defer leave()
 
-			// 2) Clave (global/ip/user)
-			key := "global"
-			switch b.cfg.KeyBy {
-			case KeyIP:
-				key = "ip:" + ipFromRequest(hreq)
-			case KeyUser:
-				if u := userFromRequest(hreq); u != "" {
-					key = "u:" + u
-				} else {
-					key = "ip:" + ipFromRequest(hreq)
-				}
-			default:
-				key = "global"
+			// 2) Route overrides (operation / path prefix)
+			cl, leaveRoute, blocked := b.limits(ctx, r, crit)
+			if blocked && b.enforce(ctx, op, cl.route, ReasonInflight, "", false, cl.shadowInflight) {
+				return nil, tooMany(ctx)
 			}
+			defer leaveRoute()
 
-			// 3) Rate
-			if b.rl != nil && !b.rl.Allow(key) {
-				return nil, tooMany()
+			// 3) Clave (global/ip/user) + Rate
+			if cl.rl != nil {
+				var key string
+				ctx, key = httpKey(ctx, cl.keyBy, hreq)
+				d := cl.rl.Take(ctx, key)
+				if b.enforce(ctx, op, cl.route, ReasonRate, key, d.Allowed, cl.shadowRate) {
+					return nil, Reject(ctx, "RATE_LIMITED", "too many requests", d)
+				}
+				if d.Allowed {
+					SetHeaders(ctx, d)
+				}
 			}
 
 			// 4) Cola BBR (CPU)
 			if tail != nil {
-				if done, err := tail.Allow(); err != nil {
-					return nil, tooMany()
-				} else {
+				done, passed := b.shed(tail, crit)
+				if b.enforce(ctx, op, cl.route, ReasonBBR, "", passed, b.cfg.Shadowed(ReasonBBR)) {
+					return nil, tooMany(ctx)
+				}
+				if done != nil {
 					defer done(ratelimit.DoneInfo{})
 				}
 			}
@@ -81,6 +88,20 @@ func (b *Builder) HTTP() middleware.Middleware {
 	}
 }
 
-func tooMany() error {
-	return kratosErr.New(http.StatusTooManyRequests, "RATE_LIMITED", "too many requests")
+// httpKey groups requests by keyBy (global/ip/user). For KeyUser the caller
+// comes from verified credentials (see UseIdentity), anonymous ones by IP.
+func httpKey(ctx context.Context, keyBy KeyBy, hreq *http.Request) (context.Context, string) {
+	switch keyBy {
+	case KeyIP:
+		return ctx, "ip:" + server_utils_ip.FromRequest(hreq)
+	case KeyUser:
+		return userKey(ctx, func() string { return server_utils_ip.FromRequest(hreq) })
+	default:
+		return ctx, "global"
+	}
+}
+
+// tooMany rejects a call without a bucket (in-flight caps, BBR): retry in a second.
+func tooMany(ctx context.Context) error {
+	return Reject(ctx, "RATE_LIMITED", "too many requests", Decision{RetryAfter: time.Second})
 }
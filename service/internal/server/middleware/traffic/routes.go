@@ -0,0 +1,117 @@
+// internal/server/middleware/traffic/routes.go
+package traffic
+
+import (
+	"context"
+	"strings"
+)
+
+// routeLimiter holds the limiters of one Route override.
+type routeLimiter struct {
+	Route
+	inflight *inflightLimiter // nil = only the server cap
+	rl       rateLimiter      // nil = server buckets
+	keyBy    KeyBy
+}
+
+func newRouteLimiter(r Route, cfg Config) *routeLimiter {
+	rl := &routeLimiter{Route: r, keyBy: cfg.KeyBy}
+	if r.KeyBy != "" {
+		rl.keyBy = r.KeyBy
+	}
+	if r.InflightMax > 0 {
+		q := cfg.queue()
+		if r.QueueMax > 0 {
+			q.Max = r.QueueMax
+		}
+		if r.QueueTimeout > 0 {
+			q.Timeout = r.QueueTimeout
+		}
+		if r.QueueOrder != "" {
+			q.LIFO = r.QueueOrder == QueueLIFO
+		}
+		rl.inflight = newInflightLimiter(cfg.scope()+" "+r.name(), r.InflightMax, 0, q)
+	}
+	if r.RateRPS > 0 || r.RateBurst > 0 || r.KeyBy != "" {
+		rps, burst := cfg.RateRPS, cfg.RateBurst
+		if r.RateRPS > 0 {
+			rps = r.RateRPS
+		}
+		if r.RateBurst > 0 {
+			burst = r.RateBurst
+		}
+		if rps > 0 {
+			rl.rl = newRateLimImpl(cfg.scope()+" "+r.name(), cfg, rps, burst)
+		}
+	}
+	return rl
+}
+
+// name identifies the route in logs/metrics.
+func (r Route) name() string {
+	if r.Operation != "" {
+		return r.Operation
+	}
+	return r.PathPrefix
+}
+
+func (r *routeLimiter) matches(op, path string) bool {
+	if r.Operation != "" && matchOperation(r.Operation, op) {
+		return true
+	}
+	return r.PathPrefix != "" && path != "" && strings.HasPrefix(path, r.PathPrefix)
+}
+
+// matchOperation matches "/pkg.Service/Method" exactly or "/pkg.Service/*" by service.
+func matchOperation(pattern, op string) bool {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(op, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == op
+}
+
+// route returns the first override matching the operation or path (nil = none).
+func (b *Builder) route(op, path string) *routeLimiter {
+	for _, r := range b.routes {
+		if r.matches(op, path) {
+			return r
+		}
+	}
+	return nil
+}
+
+// callLimits are the limiters that apply to one call.
+type callLimits struct {
+	rl    rateLimiter // nil = no token bucket
+	keyBy KeyBy
+	route string // matching override ("" = none)
+
+	// dry-run of the route in-flight cap and of the bucket in use
+	shadowInflight bool
+	shadowRate     bool
+}
+
+// limits resolves the limiters of a call on route r (nil = none) and takes
+// the route in-flight slot (queueing unless shadowed or sheddable); leave must
+// be called when the call is done. blocked reports a full route cap (no slot
+// taken).
+func (b *Builder) limits(ctx context.Context, r *routeLimiter, crit Criticality) (cl callLimits, leave func(), blocked bool) {
+	cl = callLimits{rl: b.rl, keyBy: b.cfg.KeyBy, shadowRate: b.cfg.Shadowed(ReasonRate)}
+	leave = func() {}
+	if r == nil {
+		return cl, leave, false
+	}
+	cl.route, cl.keyBy = r.name(), r.keyBy
+	cl.shadowInflight = r.Shadow || b.cfg.Shadowed(ReasonInflight)
+	if r.rl != nil {
+		cl.rl = r.rl
+		cl.shadowRate = cl.shadowRate || r.Shadow
+	}
+	if r.inflight != nil {
+		if !r.inflight.acquire(ctx, crit, !cl.shadowInflight && crit != CriticalitySheddable) {
+			return cl, leave, true
+		}
+		leave = r.inflight.leave
+	}
+	return cl, leave, false
+}
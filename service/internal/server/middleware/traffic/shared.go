@@ -0,0 +1,140 @@
+// internal/server/middleware/traffic/shared.go
+package traffic
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+   Shared buckets: the same limits for every replica behind a load balancer.
+   GCRA (generic cell rate algorithm) in one Lua script per call, with the
+   Redis clock. When Redis fails or is slow, callers fall back to their local
+   buckets and Redis is skipped for the backoff period.
+*/
+
+const (
+	defaultSharedTimeout = 50 * time.Millisecond
+	defaultSharedBackoff = 5 * time.Second
+)
+
+// ErrSharedUnavailable is returned while the store is backing off after a failure.
+var ErrSharedUnavailable = errors.New("shared rate limit store unavailable")
+
+// KEYS[1] = bucket; ARGV[1] = emission interval (µs), ARGV[2] = burst.
+// Returns {allowed, remaining, retry_after_us, reset_us}.
+var gcra = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local tolerance = interval * tonumber(ARGV[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then tat = now end
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+if now < allow_at then
+  return {0, 0, allow_at - now, tat - now}
+end
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, math.floor((tolerance - (new_tat - now)) / interval), 0, new_tat - now}
+`)
+
+// Shared keeps token buckets in Redis.
+type Shared struct {
+	rdb     redis.Scripter
+	prefix  string
+	timeout time.Duration
+	backoff time.Duration
+
+	downUntil atomic.Int64 // unix nanos; local buckets only until then
+	down      atomic.Bool
+
+	log *log.Helper
+}
+
+// NewShared returns the shared store for traffic.shared, nil when disabled
+// (or when data.redis is not active).
+func NewShared(c *conf.Traffic, app *conf.App, rdb *redis.Client, logger log.Logger) *Shared {
+	h := log.NewHelper(logger)
+	sc := c.GetShared()
+	if !sc.GetActive() {
+		return nil
+	}
+	if rdb == nil {
+		h.Warnf("[TRAFFIC] traffic.shared is active but data.redis is not: using local buckets")
+		return nil
+	}
+
+	s := &Shared{
+		rdb:     rdb,
+		prefix:  sc.GetPrefix(),
+		timeout: defaultSharedTimeout,
+		backoff: defaultSharedBackoff,
+		log:     h,
+	}
+	if s.prefix == "" {
+		s.prefix = "traffic:" + app.GetName() + ":"
+	}
+	if d := sc.GetTimeout(); d != nil && d.AsDuration() > 0 {
+		s.timeout = d.AsDuration()
+	}
+	if d := sc.GetBackoff(); d != nil && d.AsDuration() > 0 {
+		s.backoff = d.AsDuration()
+	}
+	h.Infof("[TRAFFIC] shared buckets in redis (prefix %q)", s.prefix)
+	return s
+}
+
+// Take takes one token of the shared bucket key (rps refill, burst capacity).
+// On error the caller must use its local bucket.
+func (s *Shared) Take(ctx context.Context, key string, rps float64, burst int) (Decision, error) {
+	if time.Now().UnixNano() < s.downUntil.Load() {
+		return Decision{}, ErrSharedUnavailable
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	interval := int64(float64(time.Second/time.Microsecond) / rps)
+	if interval < 1 {
+		interval = 1
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	res, err := gcra.Run(callCtx, s.rdb, []string{s.prefix + key}, interval, burst).Int64Slice()
+	if err == nil && len(res) != 4 {
+		err = errors.New("unexpected gcra reply")
+	}
+	if err != nil {
+		if ctx.Err() == nil { // the caller giving up is not a store failure
+			s.fail(err)
+		}
+		return Decision{}, err
+	}
+	if s.down.CompareAndSwap(true, false) {
+		s.log.Infof("[TRAFFIC] shared store recovered")
+	}
+
+	return Decision{
+		Allowed:    res[0] == 1,
+		Limit:      burst,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		Reset:      time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
+
+func (s *Shared) fail(err error) {
+	s.downUntil.Store(time.Now().Add(s.backoff).UnixNano())
+	if s.down.CompareAndSwap(false, true) {
+		s.log.Warnf("[TRAFFIC] shared store failed, local buckets for %s: %v", s.backoff, err)
+	}
+}
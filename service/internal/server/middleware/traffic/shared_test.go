@@ -0,0 +1,111 @@
+package traffic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service/internal/conf/v1"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestShared(t *testing.T, backoff time.Duration) (*Shared, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	s := NewShared(&conf.Traffic{Shared: &conf.Traffic_Shared{
+		Active:  true,
+		Timeout: durationpb.New(time.Second),
+		Backoff: durationpb.New(backoff),
+	}}, &conf.App{Name: "test"}, rdb, log.DefaultLogger)
+	if s == nil {
+		t.Fatal("NewShared returned nil")
+	}
+	return s, m
+}
+
+func TestSharedGCRA(t *testing.T) {
+	s, m := newTestShared(t, time.Second)
+	now := time.Unix(1_700_000_000, 0)
+	m.SetTime(now)
+	ctx := context.Background()
+
+	// 1 rps, burst 3: three calls pass, the fourth waits one emission interval
+	want := []Decision{
+		{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+		{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second},
+		{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+		{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second},
+	}
+	for i, w := range want {
+		d, err := s.Take(ctx, "k", 1, 3)
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if d != w {
+			t.Errorf("call %d = %+v, want %+v", i+1, d, w)
+		}
+	}
+
+	// other keys have their own bucket
+	if d, _ := s.Take(ctx, "other", 1, 3); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("other key = %+v, want a full bucket", d)
+	}
+
+	// one interval later, one token is back
+	m.SetTime(now.Add(time.Second))
+	if d, _ := s.Take(ctx, "k", 1, 3); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("after refill = %+v, want allowed with 0 remaining", d)
+	}
+	if d, _ := s.Take(ctx, "k", 1, 3); d.Allowed {
+		t.Errorf("after refill, second call = %+v, want rejected", d)
+	}
+	if !m.Exists("traffic:test:k") {
+		t.Errorf("bucket key missing, keys = %v", m.Keys())
+	}
+}
+
+func TestSharedFallback(t *testing.T) {
+	const backoff = 200 * time.Millisecond
+	s, m := newTestShared(t, backoff)
+	ctx := context.Background()
+	rl := rateLimImpl{tb: newTBStore("test shared fallback", 10, time.Minute), r: 1, b: 2, scope: "test", shared: s}
+
+	m.Close()
+	if _, err := s.Take(ctx, "k", 1, 2); err == nil || errors.Is(err, ErrSharedUnavailable) {
+		t.Fatalf("redis down: error = %v, want the redis error", err)
+	}
+
+	// backoff window: redis is not called, even once it is back
+	if err := m.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Take(ctx, "k", 1, 2); !errors.Is(err, ErrSharedUnavailable) {
+		t.Fatalf("during backoff: error = %v, want %v", err, ErrSharedUnavailable)
+	}
+
+	// the limiter uses its local bucket meanwhile
+	for i, allowed := range []bool{true, true, false} {
+		if d := rl.Take(ctx, "k"); d.Allowed != allowed {
+			t.Errorf("local call %d allowed = %v, want %v", i+1, d.Allowed, allowed)
+		}
+	}
+	if len(m.Keys()) != 0 {
+		t.Errorf("redis used during backoff: keys = %v", m.Keys())
+	}
+
+	time.Sleep(backoff + 50*time.Millisecond)
+	if d, err := s.Take(ctx, "k", 1, 2); err != nil || !d.Allowed {
+		t.Fatalf("after backoff = %+v, %v; want redis again", d, err)
+	}
+	if s.down.Load() {
+		t.Error("store still marked down after a successful call")
+	}
+}
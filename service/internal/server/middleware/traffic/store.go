@@ -0,0 +1,160 @@
+// internal/server/middleware/traffic/store.go
+package traffic
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+/*
+   Token buckets by key (ip/user/global), bounded:
+   - sharded by key hash, one mutex per shard;
+   - each shard is an LRU: keys idle longer than the TTL are dropped, and when
+     the shard is full the least recently used key is evicted;
+   - an evicted key starts with a full bucket, so the TTL should be longer
+     than burst/rps (time to refill).
+*/
+
+const (
+	tbShards          = 64
+	defaultMaxKeys    = 100000
+	defaultKeyIdleTTL = 10 * time.Minute
+)
+
+var (
+	limiterKeys = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "traffic_limiter_keys",
+		Help: "Live token-bucket keys per limiter scope.",
+	}, []string{"scope"})
+
+	limiterEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "traffic_limiter_evictions_total",
+		Help: "Token-bucket keys evicted per limiter scope and cause (idle, size).",
+	}, []string{"scope", "cause"})
+)
+
+type tbStore struct {
+	shards [tbShards]tbShard
+	ttl    time.Duration
+
+	keys   prometheus.Gauge
+	byIdle prometheus.Counter
+	bySize prometheus.Counter
+}
+
+type tbShard struct {
+	mu  sync.Mutex
+	m   map[string]*list.Element
+	lru *list.List // front = most recently used
+	max int
+}
+
+type tbEntry struct {
+	key  string
+	lim  *rate.Limiter
+	last time.Time
+}
+
+// newTBStore creates a store for a limiter scope (metrics label), holding at
+// most maxKeys keys, each dropped after ttl without calls (0 = defaults).
+func newTBStore(scope string, maxKeys int, ttl time.Duration) *tbStore {
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxKeys
+	}
+	if ttl <= 0 {
+		ttl = defaultKeyIdleTTL
+	}
+	perShard := (maxKeys + tbShards - 1) / tbShards
+	s := &tbStore{
+		ttl:    ttl,
+		keys:   limiterKeys.WithLabelValues(scope),
+		byIdle: limiterEvictions.WithLabelValues(scope, "idle"),
+		bySize: limiterEvictions.WithLabelValues(scope, "size"),
+	}
+	for i := range s.shards {
+		s.shards[i] = tbShard{m: make(map[string]*list.Element), lru: list.New(), max: perShard}
+	}
+	return s
+}
+
+func (s *tbStore) get(key string, rps float64, burst int) *rate.Limiter {
+	if key == "" {
+		key = "global"
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	now := time.Now()
+	sh := &s.shards[fnv32(key)%tbShards]
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	s.expire(sh, now)
+	if el, ok := sh.m[key]; ok {
+		e := el.Value.(*tbEntry)
+		e.last = now
+		sh.lru.MoveToFront(el)
+		if e.lim.Limit() != rate.Limit(rps) || e.lim.Burst() != burst {
+			// limits changed (e.g. refreshed quotas): keep the tokens left
+			e.lim.SetLimitAt(now, rate.Limit(rps))
+			e.lim.SetBurstAt(now, burst)
+		}
+		return e.lim
+	}
+	for sh.lru.Len() >= sh.max {
+		s.remove(sh, sh.lru.Back())
+		s.bySize.Inc()
+	}
+	e := &tbEntry{key: key, lim: rate.NewLimiter(rate.Limit(rps), burst), last: now}
+	sh.m[key] = sh.lru.PushFront(e)
+	s.keys.Inc()
+	return e.lim
+}
+
+// Store is a bounded set of token buckets by key, for limiters outside
+// Builder (individual quotas).
+type Store struct{ tb *tbStore }
+
+// NewStore creates a store for a limiter scope (metrics label), holding at
+// most maxKeys keys, each dropped after ttl without calls (0 = defaults).
+func NewStore(scope string, maxKeys int, ttl time.Duration) *Store {
+	return &Store{tb: newTBStore(scope, maxKeys, ttl)}
+}
+
+// Take takes one token of the key bucket (rps refill, burst capacity).
+func (s *Store) Take(key string, rps float64, burst int) Decision {
+	return Take(s.tb.get(key, rps, burst))
+}
+
+// expire drops idle keys from the back of the shard (caller holds the lock).
+func (s *tbStore) expire(sh *tbShard, now time.Time) {
+	for el := sh.lru.Back(); el != nil; el = sh.lru.Back() {
+		if now.Sub(el.Value.(*tbEntry).last) < s.ttl {
+			return
+		}
+		s.remove(sh, el)
+		s.byIdle.Inc()
+	}
+}
+
+func (s *tbStore) remove(sh *tbShard, el *list.Element) {
+	sh.lru.Remove(el)
+	delete(sh.m, el.Value.(*tbEntry).key)
+	s.keys.Dec()
+}
+
+// fnv32 is FNV-1a (no allocation, unlike hash/fnv).
+func fnv32(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
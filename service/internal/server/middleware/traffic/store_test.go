@@ -0,0 +1,77 @@
+package traffic
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// sameShard returns n keys that hash to the same shard.
+func sameShard(n int) []string {
+	var keys []string
+	for i := 0; len(keys) < n; i++ {
+		if k := "k" + strconv.Itoa(i); fnv32(k)%tbShards == 0 {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func TestTBStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := newTBStore("test lru", 2*tbShards, time.Hour) // 2 keys per shard
+	k := sameShard(3)
+
+	a := s.get(k[0], 1, 1)
+	b := s.get(k[1], 1, 1)
+	if s.get(k[0], 1, 1) != a { // k[0] is now the most recently used
+		t.Fatal("existing key got a new bucket")
+	}
+	s.get(k[2], 1, 1) // shard full: evicts k[1]
+
+	sh := &s.shards[0]
+	if _, ok := sh.m[k[1]]; ok {
+		t.Errorf("least recently used key %s kept", k[1])
+	}
+	if _, ok := sh.m[k[0]]; !ok {
+		t.Errorf("recently used key %s evicted", k[0])
+	}
+	if sh.lru.Len() != 2 {
+		t.Errorf("shard size = %d, want 2", sh.lru.Len())
+	}
+	if s.get(k[1], 1, 1) == b {
+		t.Error("evicted key kept its bucket")
+	}
+}
+
+func TestTBStoreExpiresIdleKeys(t *testing.T) {
+	const ttl = 20 * time.Millisecond
+	s := newTBStore("test idle", 0, ttl)
+	k := sameShard(2)
+
+	a := s.get(k[0], 1, 1)
+	if !a.Allow() {
+		t.Fatal("new bucket is empty")
+	}
+	time.Sleep(ttl + 10*time.Millisecond)
+
+	s.get(k[1], 1, 1) // any call on the shard drops idle keys
+	if _, ok := s.shards[0].m[k[0]]; ok {
+		t.Errorf("idle key %s kept", k[0])
+	}
+	if fresh := s.get(k[0], 1, 1); fresh == a || !fresh.Allow() {
+		t.Error("expired key did not start with a full bucket")
+	}
+}
+
+func TestTBStoreUpdatesLimits(t *testing.T) {
+	s := newTBStore("test update", 0, time.Hour)
+	lim := s.get("k", 1, 2)
+	lim.Allow()
+
+	if got := s.get("k", 5, 4); got != lim || got.Burst() != 4 || got.Limit() != 5 {
+		t.Errorf("bucket = %p burst %d rate %v, want the same bucket with burst 4 rate 5", got, got.Burst(), got.Limit())
+	}
+	if got := s.get("", 1, 0); got.Burst() != 1 {
+		t.Errorf("empty key burst = %d, want 1", got.Burst())
+	}
+}
@@ -0,0 +1,6 @@
+package traffic
+
+import "github.com/google/wire"
+
+// ProviderSet is traffic providers (shared buckets).
+var ProviderSet = wire.NewSet(NewShared)
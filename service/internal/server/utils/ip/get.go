@@ -3,58 +3,150 @@ package server_utils_ip
 import (
 	"context"
 	"net/http"
-	"strings"
+	"sync/atomic"
 
+	"service/internal/conf/v1"
+	"service/pkg/logger"
+
+	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
+// Unknown is returned when no client address is available.
+const Unknown = "unknown"
+
+var resolver atomic.Pointer[Resolver]
+
+// Init sets trusted proxies from server.trusted_proxies and the header they
+// set from server.forwarded_header (call once at startup).
+// Without it no forwarding header is trusted.
+func Init(c *conf.Server) {
+	r, bad := NewResolver(c.GetTrustedProxies(), c.GetForwardedHeader())
+	if len(bad) > 0 {
+		logger.Warn("server.trusted_proxies / server.forwarded_header: invalid entries ignored", map[string]interface{}{"entries": bad})
+	}
+	resolver.Store(r)
+}
+
+func current() *Resolver {
+	if r := resolver.Load(); r != nil {
+		return r
+	}
+	return &Resolver{}
+}
+
+// ----- context -----
+
+type ctxKey struct{}
+
+// NewContext stores the resolved client IP.
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, ip)
+}
+
+// FromContext returns the client IP stored by the server filter/middleware.
+func FromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(ctxKey{}).(string)
+	return ip, ok
+}
+
+// ----- lookups -----
+
+// GetIP returns the client IP of a request (HTTP or gRPC): the stored one,
+// otherwise resolved from the transport.
 func GetIP(ctx context.Context) string {
-	ip := "unknown"
-	if tr, ok := transport.FromServerContext(ctx); ok {
-		switch tr.Kind() {
-		case transport.KindHTTP:
-			if ht, ok := tr.(*khttp.Transport); ok {
-				ip = extractIP(ht.Request())
-			}
-		case transport.KindGRPC:
-			// For gRPC requests, we get the IP from the headers
-			ip = tr.RequestHeader().Get("x-real-ip")
-			if ip == "" {
-				ip = tr.RequestHeader().Get("x-forwarded-for")
-			}
-		}
+	if ip, ok := FromContext(ctx); ok {
+		return ip
+	}
+	if r, ok := khttp.RequestFromServerContext(ctx); ok {
+		return resolveHTTP(r)
 	}
-	return ip
+	return resolveGRPC(ctx)
 }
 
-// extractIP extracts the IP address from the HTTP request with consideration for proxies
-func extractIP(r *http.Request) string {
-	// X-Real-IP
-	ip := r.Header.Get("X-Real-IP")
-	if ip != "" {
+// FromRequest returns the client IP of an HTTP request.
+func FromRequest(r *http.Request) string {
+	if ip, ok := FromContext(r.Context()); ok {
 		return ip
 	}
+	return resolveHTTP(r)
+}
+
+func resolveHTTP(r *http.Request) string {
+	return current().Resolve(r.RemoteAddr, func(key string) []string {
+		return r.Header.Values(key)
+	})
+}
+
+func resolveGRPC(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return Unknown
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return current().Resolve(p.Addr.String(), md.Get)
+}
+
+// TrustedPeer reports whether the call came straight from a trusted proxy
+// (server.trusted_proxies); only then are caller-set headers believed.
+func TrustedPeer(ctx context.Context) bool {
+	if r, ok := khttp.RequestFromServerContext(ctx); ok {
+		return current().Trusted(r.RemoteAddr)
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+	return current().Trusted(p.Addr.String())
+}
+
+// ----- once per request -----
 
-	// X-Forwarded-For
-	ip = r.Header.Get("X-Forwarded-For")
-	if ip != "" {
-		// We get the first IP from the list (the most original client IP)
-		if i := strings.Index(ip, ","); i > 0 {
-			ip = ip[:i]
+// HTTPFilter resolves the client IP once per HTTP request (every route,
+// including plain handlers); add it as the first http.Filter.
+func HTTPFilter() khttp.FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := FromContext(r.Context()); !ok {
+				r = r.WithContext(NewContext(r.Context(), resolveHTTP(r)))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Server resolves the client IP once per call for Kratos middleware
+// (gRPC unary; HTTP already has it from HTTPFilter). Put it first in the chain.
+func Server() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := FromContext(ctx); !ok {
+				if _, ok := transport.FromServerContext(ctx); ok {
+					ctx = NewContext(ctx, GetIP(ctx))
+				}
+			}
+			return next(ctx, req)
 		}
-		return ip
 	}
+}
 
-	// RemoteAddr
-	ip = r.RemoteAddr
-	if ip != "" {
-		// We remove the port if it exists
-		if i := strings.LastIndex(ip, ":"); i > 0 {
-			ip = ip[:i]
+// StreamInterceptor resolves the client IP once per gRPC stream.
+func StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := FromContext(ss.Context()); ok {
+			return handler(srv, ss)
 		}
-		return ip
+		return handler(srv, &ipStream{ServerStream: ss, ctx: NewContext(ss.Context(), resolveGRPC(ss.Context()))})
 	}
+}
 
-	return "unknown"
+type ipStream struct {
+	grpc.ServerStream
+	ctx context.Context
 }
+
+func (s *ipStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,154 @@
+package server_utils_ip
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Resolver finds the client IP behind trusted proxies.
+//
+// The peer address is the client unless it is a trusted proxy; then the
+// forwarding chain of the configured header is walked from the right,
+// skipping trusted proxies, and the first untrusted address is the client.
+// Only that header is read: a client can always add the other one, and the
+// proxy only rewrites the one it owns.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// Forwarding headers accepted by NewResolver.
+const (
+	HeaderXForwardedFor = "x-forwarded-for"
+	HeaderForwarded     = "forwarded"
+)
+
+// Header reads a request header (HTTP header or gRPC metadata).
+type Header func(key string) []string
+
+// NewResolver parses trusted proxies: CIDRs ("10.0.0.0/8") or single IPs,
+// and the forwarding header they set (empty = X-Forwarded-For). Invalid
+// entries are returned in bad and skipped; an unknown header is returned in
+// bad and no header is trusted.
+func NewResolver(proxies []string, header string) (r *Resolver, bad []string) {
+	r = &Resolver{}
+	switch h := strings.ToLower(strings.TrimSpace(header)); h {
+	case "":
+		r.header = HeaderXForwardedFor
+	case HeaderXForwardedFor, HeaderForwarded:
+		r.header = h
+	default:
+		bad = append(bad, header)
+	}
+	for _, s := range proxies {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(s); err == nil {
+			r.trusted = append(r.trusted, p.Masked())
+			continue
+		}
+		if a, err := netip.ParseAddr(s); err == nil {
+			a = a.Unmap()
+			r.trusted = append(r.trusted, netip.PrefixFrom(a, a.BitLen()))
+			continue
+		}
+		bad = append(bad, s)
+	}
+	return r, bad
+}
+
+// Resolve returns the client IP for a peer address ("ip:port" or "ip").
+func (r *Resolver) Resolve(remote string, header Header) string {
+	peer, ok := parseAddr(remote)
+	if !ok {
+		return Unknown
+	}
+	if !r.isTrusted(peer) || header == nil || r.header == "" {
+		return peer.String()
+	}
+
+	var chain []string
+	if r.header == HeaderForwarded {
+		chain = forwardedFor(header(HeaderForwarded))
+	} else {
+		chain = xForwardedFor(header(HeaderXForwardedFor))
+	}
+
+	client := peer
+	for i := len(chain) - 1; i >= 0; i-- {
+		a, ok := parseAddr(chain[i])
+		if !ok {
+			// "unknown", obfuscated or garbage: nothing left of it can be trusted
+			break
+		}
+		client = a
+		if !r.isTrusted(a) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// Trusted reports whether a peer address ("ip:port" or "ip") is a trusted proxy.
+func (r *Resolver) Trusted(remote string) bool {
+	a, ok := parseAddr(remote)
+	return ok && r.isTrusted(a)
+}
+
+func (r *Resolver) isTrusted(a netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the "for" values of RFC 7239 Forwarded headers, in order.
+func forwardedFor(values []string) (chain []string) {
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			node := ""
+			for _, pair := range strings.Split(elem, ";") {
+				k, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(k, "for") {
+					node = strings.Trim(strings.TrimSpace(val), `"`)
+				}
+			}
+			chain = append(chain, node)
+		}
+	}
+	return chain
+}
+
+// xForwardedFor returns the addresses of X-Forwarded-For headers, in order.
+func xForwardedFor(values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, a := range strings.Split(v, ",") {
+			chain = append(chain, strings.TrimSpace(a))
+		}
+	}
+	return chain
+}
+
+// parseAddr accepts "1.2.3.4", "1.2.3.4:80", "::1", "[::1]" and "[::1]:80".
+func parseAddr(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}, false
+	}
+	if a, err := netip.ParseAddr(s); err == nil {
+		return a.Unmap(), true
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if a, err := netip.ParseAddr(s); err == nil {
+		return a.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,62 @@
+package server_utils_ip
+
+import (
+	"net/http"
+	"testing"
+)
+
+func headers(kv ...string) Header {
+	h := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Add(kv[i], kv[i+1])
+	}
+	return func(key string) []string { return h.Values(key) }
+}
+
+func TestResolve(t *testing.T) {
+	xff, bad := NewResolver([]string{"10.0.0.0/8", "192.0.2.1", "nope"}, "")
+	if len(bad) != 1 || bad[0] != "nope" {
+		t.Fatalf("bad = %v, want [nope]", bad)
+	}
+	fwd, _ := NewResolver([]string{"10.0.0.0/8"}, "Forwarded")
+
+	tests := []struct {
+		name   string
+		r      *Resolver
+		remote string
+		header Header
+		want   string
+	}{
+		{"untrusted peer ignores headers", xff, "203.0.113.9:443", headers("X-Forwarded-For", "198.51.100.1"), "203.0.113.9"},
+		{"trusted peer without chain", xff, "10.0.0.1:80", nil, "10.0.0.1"},
+		{"single hop", xff, "10.0.0.1:80", headers("X-Forwarded-For", "198.51.100.1"), "198.51.100.1"},
+		{"spoofed left entry", xff, "10.0.0.1:80", headers("X-Forwarded-For", "1.1.1.1, 198.51.100.1"), "198.51.100.1"},
+		{"trusted hops skipped", xff, "10.0.0.1:80", headers("X-Forwarded-For", "198.51.100.1, 192.0.2.1, 10.1.1.1"), "198.51.100.1"},
+		{"repeated header lines", xff, "10.0.0.1:80", headers("X-Forwarded-For", "198.51.100.1", "X-Forwarded-For", "10.2.2.2"), "198.51.100.1"},
+		{"garbage stops the walk", xff, "10.0.0.1:80", headers("X-Forwarded-For", "198.51.100.1, unknown, 10.2.2.2"), "10.2.2.2"},
+		{"all trusted", xff, "10.0.0.1:80", headers("X-Forwarded-For", "10.3.3.3"), "10.3.3.3"},
+		{"mapped ipv6 peer", xff, "[::ffff:10.0.0.1]:80", headers("X-Forwarded-For", "2001:db8::1"), "2001:db8::1"},
+		{"forwarded ignored for xff", xff, "10.0.0.1:80", headers("Forwarded", "for=1.1.1.1"), "10.0.0.1"},
+		{"x-real-ip ignored", xff, "10.0.0.1:80", headers("X-Real-IP", "1.1.1.1"), "10.0.0.1"},
+		{"forwarded", fwd, "10.0.0.1:80", headers("Forwarded", `for=1.1.1.1, for="[2001:db8::1]:4711";proto=https`), "2001:db8::1"},
+		{"xff ignored for forwarded", fwd, "10.0.0.1:80", headers("X-Forwarded-For", "1.1.1.1"), "10.0.0.1"},
+		{"bad peer", xff, "nowhere", nil, Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Resolve(tt.remote, tt.header); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveUnknownHeader(t *testing.T) {
+	r, bad := NewResolver([]string{"10.0.0.0/8"}, "x-real-ip")
+	if len(bad) != 1 {
+		t.Fatalf("bad = %v, want the header", bad)
+	}
+	if got := r.Resolve("10.0.0.1:80", headers("X-Real-IP", "1.1.1.1", "X-Forwarded-For", "1.1.1.1")); got != "10.0.0.1" {
+		t.Errorf("Resolve() = %q, want the peer", got)
+	}
+}
@@ -2,49 +2,15 @@ package requestlog
 
 import (
 	"context"
-	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/peer"
 
+	"service/internal/server/utils/ip"
 	mylog "service/pkg/logger"
 )
 
-// getClientIP get client IP from different types of requests
-func getClientIP(ctx context.Context, r *http.Request) string {
-	// For HTTP requests
-	if r != nil {
-		// X-Forwarded-For: get first IP
-		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-			if i := strings.IndexByte(xff, ','); i >= 0 {
-				return strings.TrimSpace(xff[:i])
-			}
-			return strings.TrimSpace(xff)
-		}
-		// Nginx/Traefik
-		if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
-			return xrip
-		}
-		// RemoteAddr -> host
-		host, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err == nil && host != "" {
-			return host
-		}
-		return r.RemoteAddr
-	}
-
-	// For gRPC requests
-	if p, ok := peer.FromContext(ctx); ok {
-		if p.Addr != nil {
-			return p.Addr.String()
-		}
-	}
-	return "unknown"
-}
-
 // logRequest log request information
 func logRequest(method, path string, fields map[string]interface{}) {
 	mylog.Route(method, path, fields)
@@ -85,7 +51,7 @@ func HTTPLogMiddleware() func(http.Handler) http.Handler {
 				r.Method,
 				r.URL.Path,
 				map[string]interface{}{
-					"ip":      getClientIP(r.Context(), r),
+					"ip":      server_utils_ip.FromRequest(r),
 					"status":  sw.status,
 					"size":    sw.size,
 					"latency": time.Since(start).String(),
@@ -115,7 +81,7 @@ func UnaryLogInterceptor() grpc.UnaryServerInterceptor {
 			"gRPC",
 			info.FullMethod,
 			map[string]interface{}{
-				"ip":      getClientIP(ctx, nil),
+				"ip":      server_utils_ip.GetIP(ctx),
 				"status":  status,
 				"latency": time.Since(start).String(),
 			},
@@ -143,7 +109,7 @@ func StreamLogInterceptor() grpc.StreamServerInterceptor {
 			"gRPC Stream",
 			info.FullMethod,
 			map[string]interface{}{
-				"ip":      getClientIP(ss.Context(), nil),
+				"ip":      server_utils_ip.GetIP(ss.Context()),
 				"status":  status,
 				"latency": time.Since(start).String(),
 			},
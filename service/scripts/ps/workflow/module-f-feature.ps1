@@ -132,25 +132,17 @@ $rolesGo = @"
 package $pkg
 
 import (
+	api_$alias "$apiImport"
 	${pkg}_service "$svcImport"
 	"service/internal/server/middleware/auth/authz/endpoint"
 )
 
-const (
-	RoleExample = "TEST1"
-)
-
-// Endpoints with required roles (versioned)
-func Get${pascal}v${apiV}Endpoints(svc *${pkg}_service.${pascal}Service) endpoint.ServiceGroup {
-	return endpoint.ServiceGroup{
-		Name: "$groupName",
-		Methods: []endpoint.ServiceMethod{
-			// Examples (uncomment and replace with real service methods):
-			// endpoint.NewServiceMethod(svc, svc.List${pascal}s),
-			// endpoint.NewServiceMethod(svc, svc.UpsertExcel, RoleExample),
-			// other methods...
-		},
-	}
+// Endpoints with required roles (versioned).
+// Roles are declared per RPC in api/$base/v$apiV/$base.proto:
+//
+//	option (auth.v1.rule) = { roles: ["ADMIN"] };
+func Get${pascal}v${apiV}Endpoints(_ *${pkg}_service.${pascal}Service) endpoint.ServiceGroup {
+	return endpoint.NewServiceGroup("$groupName", api_$alias.${pascal}${versionPrefix}${apiV}Service_ServiceDesc.ServiceName)
 }
 
 // Backward-compatible alias (without version): calls versioned function
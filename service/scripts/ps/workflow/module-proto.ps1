@@ -113,6 +113,7 @@ $importLines += 'import "google/protobuf/timestamp.proto";'
 if ($AnyOps -or $GenerateMock) {
   $importLines += 'import "google/api/annotations.proto";'
   $importLines += 'import "google/api/field_behavior.proto";'
+  $importLines += 'import "api/auth/v1/auth.proto";'
 }
 $importsBlock = ($importLines -join "`n")
 
@@ -125,6 +126,7 @@ if ($HasGet) {
   // GET ${route} - list or search by filters (query: id OR name)
   rpc Find${pluralPascal}(Find${pluralPascal}Request) returns (Find${pluralPascal}Response) {
     option (google.api.http) = { get: "${route}" };
+    option (auth.v1.rule) = { roles: ["ADMIN"] }; // or { public: true }
   }
 "@
 
@@ -148,6 +150,7 @@ if ($HasUpsert) {
       post: "${route}"
       body: "*"
     };
+    option (auth.v1.rule) = { roles: ["ADMIN"] }; // or { public: true }
   }
 "@
 
@@ -167,6 +170,7 @@ if ($HasDelete) {
   // DELETE ${route}?id=123 - delete by id (query param)
   rpc Delete${pascal}ById(Delete${pascal}ByIdRequest) returns (Delete${pascal}ByIdResponse) {
     option (google.api.http) = { delete: "${route}" };
+    option (auth.v1.rule) = { roles: ["ADMIN"] }; // or { public: true }
   }
 "@
 
@@ -183,6 +187,7 @@ if ($GenerateMock) {
 // Mock endpoint (no ops selected)
   rpc Mock(MockRequest) returns (MockResponse) {
     option (google.api.http) = { get: "${route}/mock" };
+    option (auth.v1.rule) = { roles: ["ADMIN"] }; // or { public: true }
   }
 "@
 